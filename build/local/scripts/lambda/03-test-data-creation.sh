@@ -29,23 +29,28 @@ aws dynamodb put-item \
               "cash_reserved": {
                 "N": "0"
               },
-              "crypto_available": {
-                "N": "1.000000"
-              },
-              "crypto_amount": {
-                "N": "1.000000"
-              },
-              "crypto_symbol": {
-                "S": "BTC"
-              },
-              "crypto_reserved": {
-                "N": "0"
+              "crypto": {
+                "M": {
+                  "BTC": {
+                    "M": {
+                      "available": {
+                        "N": "1.000000"
+                      },
+                      "amount": {
+                        "N": "1.000000"
+                      },
+                      "reserved": {
+                        "N": "0"
+                      }
+                    }
+                  }
+                }
               },
               "buy_on": {
-                "N": "1"
+                "S": "BUY"
               },
               "sell_on": {
-                "N": "1"
+                "S": "SELL"
               },
               "ops_timeout_seconds": {
                 "N": "60"
@@ -2,51 +2,57 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/brienze1/crypto-robot-validator/internal/validator"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/adapters"
 	"github.com/google/uuid"
+	"time"
 )
 
-type ctx struct {
-	context.Context
-	awsRequestId string
-}
-
-func (ctx ctx) Value(any) any {
-	return &lambdacontext.LambdaContext{
-		AwsRequestID: ctx.awsRequestId,
+func main() {
+	err := run(validator.Main())
+	if err != nil {
+		panic(err)
 	}
 }
 
-func main() {
+// run sends a local operation request to handler, returns error if the handler fails or any record is not processed.
+func run(handler adapters.HandlerAdapter) error {
 	ctx := createContext()
 	event := createSQSEvent()
 
-	err := validator.Main().Handle(ctx, event)
+	response, err := handler.Handle(ctx, event)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	if len(response.BatchItemFailures) > 0 {
+		return fmt.Errorf("records failed: %v", response.BatchItemFailures)
+	}
+	return nil
 }
 
-func createContext() *ctx {
-	return &ctx{
-		awsRequestId: uuid.NewString(),
-	}
+func createContext() context.Context {
+	return lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		AwsRequestID: uuid.NewString(),
+	})
 }
 
 func createSQSEvent() events.SQSEvent {
-	operationMessage := `{
+	operationMessage := fmt.Sprintf(`{
 	  "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
 	  "operation": "BUY",
 	  "symbol": "BTC",
-	  "start_time": "2022-09-17T12:05:07.45066-03:00"
-	}`
+	  "analysis": "STRONG_BUY",
+	  "start_time": "%s"
+	}`, time.Now().Format(time.RFC3339Nano))
 
 	return events.SQSEvent{
 		Records: []events.SQSMessage{
 			{
-				Body: operationMessage,
+				MessageId: uuid.NewString(),
+				Body:      operationMessage,
 			},
 		},
 	}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/handler"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRunSuccess(t *testing.T) {
+	config.LoadTestEnv()
+
+	validationUseCase := mocks.ValidationUseCase()
+	handlerImpl := handler.Handler(validationUseCase.Factory, mocks.SignatureVerifier(), mocks.Logger().Factory)
+
+	var err error
+	assert.NotPanics(t, func() { err = run(handlerImpl) }, "Should not panic")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 1, validationUseCase.ValidateCallCounter, "validate should be called once")
+	assert.Len(t, validationUseCase.InvocationIds, 1)
+	assert.NotEmpty(t, validationUseCase.InvocationIds[0], "Use case should lock on behalf of the invocation")
+}
@@ -1,6 +1,6 @@
 module github.com/brienze1/crypto-robot-validator
 
-go 1.19
+go 1.20
 
 require (
 	github.com/alicebob/miniredis v2.5.0+incompatible
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
 	"github.com/go-redis/redis/v8"
+	"sync"
 	"time"
 )
 
@@ -12,6 +14,8 @@ type redisClient struct {
 	secretsManager adapters.SecretsManagerServiceAdapter
 	client         *redis.Client
 	cacheConfig    *dto.RedisSecrets
+	connections    int
+	mutex          sync.Mutex
 }
 
 func RedisClient(secretsManager adapters.SecretsManagerServiceAdapter) *redisClient {
@@ -20,10 +24,15 @@ func RedisClient(secretsManager adapters.SecretsManagerServiceAdapter) *redisCli
 	}
 }
 
-func (r *redisClient) Open() (*redis.Client, error) {
+// Open returns the redis client shared by every caller, the client is created on the first Open and is only closed
+// when every Open has been followed by a Close. Safe for concurrent use.
+func (r *redisClient) Open(ctx context.Context) (*redis.Client, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	if r.cacheConfig == nil {
 		cacheConfig := &dto.RedisSecrets{}
-		err := r.secretsManager.GetSecret(properties.Properties().Aws.SecretsManager.CacheSecretName, cacheConfig)
+		err := r.secretsManager.GetSecret(ctx, properties.Properties().Aws.SecretsManager.CacheSecretName, cacheConfig)
 		if err != nil {
 			panic(err)
 		}
@@ -31,22 +40,45 @@ func (r *redisClient) Open() (*redis.Client, error) {
 		r.cacheConfig = cacheConfig
 	}
 
-	r.client = redis.NewClient(&redis.Options{
-		Addr:         r.cacheConfig.Address,
-		Username:     r.cacheConfig.User,
-		Password:     r.cacheConfig.Password,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-	})
+	if r.client == nil {
+		r.client = redis.NewClient(&redis.Options{
+			Addr:         r.cacheConfig.Address,
+			Username:     r.cacheConfig.User,
+			Password:     r.cacheConfig.Password,
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+		})
+	}
 
-	_, err := r.client.Ping(r.client.Context()).Result()
+	_, err := r.client.Ping(ctx).Result()
 	if err != nil {
+		if r.connections == 0 {
+			_ = r.client.Close()
+			r.client = nil
+		}
 		return nil, err
 	}
 
+	r.connections++
 	return r.client, nil
 }
 
+// Close releases a connection acquired with Open, the shared client is closed when no connection is left open.
 func (r *redisClient) Close() error {
-	return r.client.Close()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.client == nil {
+		return nil
+	}
+
+	r.connections--
+	if r.connections > 0 {
+		return nil
+	}
+
+	client := r.client
+	r.client = nil
+	r.connections = 0
+	return client.Close()
 }
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	adapters3 "github.com/brienze1/crypto-robot-validator/internal/validator/delivery/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/handler"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/verifier"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/exchange"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/usecase"
 	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/aws"
@@ -15,7 +18,12 @@ import (
 	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
 	"net/http"
 	"sync"
-	"time"
+)
+
+// biscointCircuit and binanceCircuit name the circuit breaker state shared on Redis by every request to the exchange.
+const (
+	biscointCircuit = "biscoint"
+	binanceCircuit  = "binance"
 )
 
 var dependencyInjectorInit sync.Once
@@ -23,6 +31,7 @@ var injector *dependencyInjector
 
 type dependencyInjector struct {
 	Logger                 adapters.LoggerAdapter
+	LoggerFactory          adapters.LoggerFactory
 	EncryptionService      adapters2.EncryptionServiceAdapter
 	HTTPClient             adapters2.HTTPClientAdapter
 	DynamoDBClient         adapters2.DynamoDBAdapter
@@ -30,18 +39,17 @@ type dependencyInjector struct {
 	SecretsManager         adapters2.SecretsManagerAdapter
 	RedisClient            adapters2.RedisAdapter
 	TimeSource             adapters.TimeAdapter
-	EventService           adapters.EventServiceAdapter
 	SecretsManagerService  adapters2.SecretsManagerServiceAdapter
-	ClientPersistence      adapters.ClientPersistenceAdapter
 	CredentialsPersistence adapters2.CredentialsPersistenceAdapter
-	OperationPersistence   adapters.OperationPersistenceAdapter
-	LockPersistence        adapters.LockPersistenceAdapter
 	TokenBuilder           adapters2.TokenBuilderAdapter
 	HeaderBuilder          adapters2.HeaderBuilderAdapter
-	CryptoService          adapters.CryptoServiceAdapter
-	ClientService          adapters.ClientServiceAdapter
-	ValidationUseCase      adapters.ValidationUseCaseAdapter
+	ValidationUseCase      adapters.ValidationUseCaseFactory
+	RelayUseCase           adapters.RelayUseCaseFactory
+	ReaperUseCase          adapters.ReaperUseCaseFactory
+	SignatureVerifier      adapters3.SignatureVerifierAdapter
 	Handler                adapters3.HandlerAdapter
+	RelayHandler           adapters3.RelayHandlerAdapter
+	ReaperHandler          adapters3.ReaperHandlerAdapter
 }
 
 // DependencyInjector constructor method.
@@ -60,12 +68,17 @@ func (d *dependencyInjector) WireDependencies() *dependencyInjector {
 	if d.Logger == nil {
 		d.Logger = log.Logger()
 	}
+	if d.LoggerFactory == nil {
+		d.LoggerFactory = func(correlationId string) adapters.LoggerAdapter {
+			return log.Logger().WithCorrelationID(correlationId)
+		}
+	}
 	if d.EncryptionService == nil {
 		d.EncryptionService = utils.EncryptionService(d.Logger)
 	}
 	if d.HTTPClient == nil {
 		d.HTTPClient = &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: properties.Properties().HTTPClientTimeout,
 		}
 	}
 	if d.DynamoDBClient == nil {
@@ -77,18 +90,9 @@ func (d *dependencyInjector) WireDependencies() *dependencyInjector {
 	if d.TimeSource == nil {
 		d.TimeSource = time_utils.Time()
 	}
-	if d.EventService == nil {
-		d.EventService = eventservice.SNSEventService(d.Logger, d.SNSClient)
-	}
-	if d.ClientPersistence == nil {
-		d.ClientPersistence = persistence.DynamoDBClientPersistence(d.Logger, d.DynamoDBClient)
-	}
 	if d.CredentialsPersistence == nil {
 		d.CredentialsPersistence = persistence.DynamoDBCredentialsPersistence(d.Logger, d.DynamoDBClient)
 	}
-	if d.OperationPersistence == nil {
-		d.OperationPersistence = persistence.DynamoDBOperationPersistence(d.Logger, d.DynamoDBClient)
-	}
 	if d.SecretsManager == nil {
 		d.SecretsManager = SecretsManagerClient()
 	}
@@ -98,35 +102,97 @@ func (d *dependencyInjector) WireDependencies() *dependencyInjector {
 	if d.RedisClient == nil {
 		d.RedisClient = RedisClient(d.SecretsManagerService)
 	}
-	if d.LockPersistence == nil {
-		d.LockPersistence = persistence.RedisPersistence(d.Logger, d.RedisClient)
-	}
 	if d.TokenBuilder == nil {
 		d.TokenBuilder = utils.TokenBuilder(d.Logger, d.EncryptionService)
 	}
 	if d.HeaderBuilder == nil {
 		d.HeaderBuilder = utils.HeaderBuilder(d.Logger, d.CredentialsPersistence, d.SecretsManagerService, d.EncryptionService, d.TokenBuilder)
 	}
-	if d.CryptoService == nil {
-		d.CryptoService = webservice.BiscointWebService(d.Logger, d.HTTPClient, d.HeaderBuilder)
+	if d.ValidationUseCase == nil {
+		d.ValidationUseCase = d.validationUseCase
 	}
-	if d.ClientService == nil {
-		d.ClientService = webservice.BiscointWebService(d.Logger, d.HTTPClient, d.HeaderBuilder)
+	if d.RelayUseCase == nil {
+		d.RelayUseCase = d.relayUseCase
 	}
-	if d.ValidationUseCase == nil {
-		d.ValidationUseCase = usecase.ValidationUseCase(
-			d.LockPersistence,
-			d.ClientPersistence,
-			d.ClientService,
-			d.CryptoService,
-			d.OperationPersistence,
-			d.EventService,
-			d.Logger,
-		)
+	if d.ReaperUseCase == nil {
+		d.ReaperUseCase = d.reaperUseCase
+	}
+	if d.SignatureVerifier == nil {
+		d.SignatureVerifier = verifier.SNSSignatureVerifier(d.Logger, d.HTTPClient)
 	}
 	if d.Handler == nil {
-		d.Handler = handler.Handler(d.ValidationUseCase, d.Logger)
+		d.Handler = handler.Handler(d.ValidationUseCase, d.SignatureVerifier, d.LoggerFactory)
+	}
+	if d.RelayHandler == nil {
+		d.RelayHandler = handler.RelayHandler(d.RelayUseCase, d.LoggerFactory)
+	}
+	if d.ReaperHandler == nil {
+		d.ReaperHandler = handler.ReaperHandler(d.ReaperUseCase, d.LoggerFactory)
 	}
 
 	return d
 }
+
+// validationUseCase creates a usecase.ValidationUseCase for a single record, its persistence and service dependencies
+// log with the record logger and share the clients wired on the injector. Clients are locked with the lambda
+// invocation id as owner. Each exchange has its own circuit breaker.
+// The paper exchange quotes from the recorded price feed if PAPER_EXCHANGE_RECORDED_PRICE_FEED is set, otherwise from
+// the client exchange.
+func (d *dependencyInjector) validationUseCase(invocationId string, logger adapters.LoggerAdapter) adapters.ValidationUseCaseAdapter {
+	retryClient := utils.RetryClient(logger, d.HTTPClient)
+
+	exchanges := webservice.ExchangeRegistry().
+		Register(
+			exchange.Biscoint,
+			webservice.BiscointWebService(logger, retryClient, d.HeaderBuilder),
+			persistence.RedisCircuitBreaker(logger, d.RedisClient, biscointCircuit),
+		).
+		Register(
+			exchange.Binance,
+			webservice.BinanceWebService(logger, retryClient, d.HeaderBuilder),
+			persistence.RedisCircuitBreaker(logger, d.RedisClient, binanceCircuit),
+		)
+
+	var priceFeed adapters2.PriceFeedAdapter
+	if properties.Properties().PaperExchange.RecordedPriceFeed {
+		priceFeed = persistence.DynamoDBPricePersistence(logger, d.DynamoDBClient)
+	}
+
+	return usecase.ValidationUseCase(
+		persistence.RedisPersistence(logger, d.RedisClient),
+		persistence.DynamoDBClientPersistence(logger, d.DynamoDBClient, invocationId),
+		exchanges,
+		webservice.PaperExchange(logger, persistence.DynamoDBLedgerPersistence(logger, d.DynamoDBClient), priceFeed),
+		persistence.DynamoDBOperationPersistence(logger, d.DynamoDBClient),
+		eventservice.SNSEventService(logger, d.SNSClient),
+		persistence.DynamoDBOutboxPersistence(logger, d.DynamoDBClient),
+		logger,
+	)
+}
+
+// relayUseCase creates a usecase.RelayUseCase for a single relay execution, its dependencies log with the execution
+// logger.
+func (d *dependencyInjector) relayUseCase(logger adapters.LoggerAdapter) adapters.RelayUseCaseAdapter {
+	return usecase.RelayUseCase(
+		persistence.DynamoDBOutboxPersistence(logger, d.DynamoDBClient),
+		persistence.DynamoDBOperationPersistence(logger, d.DynamoDBClient),
+		eventservice.SNSEventService(logger, d.SNSClient),
+		d.TimeSource,
+		logger,
+	)
+}
+
+// reaperUseCase creates a usecase.ReaperUseCase for a single reaper execution, its dependencies log with the execution
+// logger. The reaper releases locks regardless of their owner, so its client persistence has no owner. Compensations
+// only release the paper exchange ledger, so its paper exchange has no price feed.
+func (d *dependencyInjector) reaperUseCase(logger adapters.LoggerAdapter) adapters.ReaperUseCaseAdapter {
+	return usecase.ReaperUseCase(
+		persistence.DynamoDBClientPersistence(logger, d.DynamoDBClient, ""),
+		persistence.RedisPersistence(logger, d.RedisClient),
+		persistence.DynamoDBOutboxPersistence(logger, d.DynamoDBClient),
+		persistence.DynamoDBOperationPersistence(logger, d.DynamoDBClient),
+		webservice.PaperExchange(logger, persistence.DynamoDBLedgerPersistence(logger, d.DynamoDBClient), nil),
+		d.TimeSource,
+		logger,
+	)
+}
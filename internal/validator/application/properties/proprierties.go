@@ -3,23 +3,67 @@ package properties
 import (
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 type properties struct {
 	Profile                         string
-	MinimumCryptoSellOperation      float64
-	MinimumCryptoBuyOperation       float64
+	MinimumCryptoSellOperation      map[string]float64
+	MinimumCryptoBuyOperation       map[string]float64
 	BiscointUrl                     string
-	SimulationUrl                   string
 	BiscointGetCryptoPath           string
 	BiscointGetBalancePath          string
+	BinanceUrl                      string
+	BinanceGetCryptoPath            string
+	BinanceGetBalancePath           string
+	BinanceRecvWindow               time.Duration
 	CryptoOperationExecutorTopicArn string
+	CryptoValidatorTopicArn         string
+	DefaultClientTimezone           string
+	HandlerMaxConcurrency           int
+	HTTPClientTimeout               time.Duration
+	SnsVerifySignature              bool
+	ClientLockLease                 time.Duration
+	LockReleaseTimeout              time.Duration
+	ReaperLockLease                 time.Duration
+	RelayGracePeriod                time.Duration
+	QuoteMaxAge                     time.Duration
+	RequestMaxAge                   time.Duration
+	ExchangeRetry                   *retry
+	CircuitBreaker                  *circuitBreaker
+	PaperExchange                   *paperExchange
+	ExchangeFees                    *exchangeFees
 	Aws                             *aws
 	Cache                           *cache
 }
 
+type retry struct {
+	MaxAttempts          int
+	BaseBackoff          time.Duration
+	MaxBackoff           time.Duration
+	Jitter               float64
+	RetryableStatusCodes []int
+}
+
+type circuitBreaker struct {
+	FailureThreshold int
+	FailureWindow    time.Duration
+	OpenDuration     time.Duration
+	ProbeTimeout     time.Duration
+}
+
+type paperExchange struct {
+	StartingBalances  map[string]float64
+	RecordedPriceFeed bool
+}
+
+type exchangeFees struct {
+	Maker map[string]float64
+	Taker map[string]float64
+}
+
 type cache struct {
 	KeyTTL    time.Duration
 	KeyPrefix string
@@ -44,6 +88,9 @@ type dynamoDB struct {
 	ClientTableName      *string
 	OperationTableName   *string
 	CredentialsTableName *string
+	OutboxTableName      *string
+	LedgerTableName      *string
+	PriceTableName       *string
 }
 
 type secretsManager struct {
@@ -76,12 +123,40 @@ func (p *properties) Reload() *properties {
 
 func loadProperties() *properties {
 	profile := os.Getenv("PROFILE")
-	minimumCryptoSellOperation := getDoubleEnvVariable("MINIMUM_CRYPTO_SELL_OPERATION")
-	minimumCryptoBuyOperation := getDoubleEnvVariable("MINIMUM_CRYPTO_BUY_OPERATION")
+	minimumCryptoSellOperation := getFloatMapEnvVariable("MINIMUM_CRYPTO_SELL_OPERATION")
+	minimumCryptoBuyOperation := getFloatMapEnvVariable("MINIMUM_CRYPTO_BUY_OPERATION")
 	biscointUrl := os.Getenv("BISCOINT_CRYPTO_URL")
 	biscointGetCryptoPath := os.Getenv("BISCOINT_CRYPTO_GET_CRYPTO_PATH")
 	biscointGetBalancePath := os.Getenv("BISCOINT_CRYPTO_GET_BALANCE_PATH")
+	binanceUrl := os.Getenv("BINANCE_CRYPTO_URL")
+	binanceGetCryptoPath := os.Getenv("BINANCE_CRYPTO_GET_CRYPTO_PATH")
+	binanceGetBalancePath := os.Getenv("BINANCE_CRYPTO_GET_BALANCE_PATH")
+	binanceRecvWindow := getIntEnvVariable("BINANCE_RECV_WINDOW_MILLISECONDS")
 	cryptoOperationExecutorTopicArn := os.Getenv("AWS_SNS_TOPIC_ARN_CRYPTO_OPERATIONS")
+	cryptoValidatorTopicArn := os.Getenv("AWS_SNS_TOPIC_ARN_CRYPTO_VALIDATOR")
+	defaultClientTimezone := os.Getenv("DEFAULT_CLIENT_TIMEZONE")
+	handlerMaxConcurrency := getIntEnvVariable("HANDLER_MAX_CONCURRENCY")
+	httpClientTimeout := getIntEnvVariable("HTTP_CLIENT_TIMEOUT_SECONDS")
+	snsVerifySignature := getBoolEnvVariable("SNS_VERIFY_SIGNATURE")
+	clientLockLease := getIntEnvVariable("CLIENT_LOCK_LEASE_SECONDS")
+	lockReleaseTimeout := getIntEnvVariable("LOCK_RELEASE_TIMEOUT_SECONDS")
+	reaperLockLease := getIntEnvVariable("REAPER_LOCK_LEASE_SECONDS")
+	relayGracePeriod := getIntEnvVariable("RELAY_GRACE_PERIOD_SECONDS")
+	quoteMaxAge := getIntEnvVariable("QUOTE_MAX_AGE_SECONDS")
+	requestMaxAge := getIntEnvVariable("REQUEST_MAX_AGE_SECONDS")
+	exchangeRetryMaxAttempts := getIntEnvVariable("EXCHANGE_RETRY_MAX_ATTEMPTS")
+	exchangeRetryBaseBackoff := getIntEnvVariable("EXCHANGE_RETRY_BASE_BACKOFF_MILLISECONDS")
+	exchangeRetryMaxBackoff := getIntEnvVariable("EXCHANGE_RETRY_MAX_BACKOFF_MILLISECONDS")
+	exchangeRetryJitter := getDoubleEnvVariable("EXCHANGE_RETRY_JITTER")
+	exchangeRetryStatusCodes := getIntListEnvVariable("EXCHANGE_RETRY_STATUS_CODES")
+	circuitBreakerFailureThreshold := getIntEnvVariable("CIRCUIT_BREAKER_FAILURE_THRESHOLD")
+	circuitBreakerFailureWindow := getIntEnvVariable("CIRCUIT_BREAKER_FAILURE_WINDOW_SECONDS")
+	circuitBreakerOpenDuration := getIntEnvVariable("CIRCUIT_BREAKER_OPEN_SECONDS")
+	circuitBreakerProbeTimeout := getIntEnvVariable("CIRCUIT_BREAKER_PROBE_TIMEOUT_SECONDS")
+	paperExchangeStartingBalances := getFloatMapEnvVariable("PAPER_EXCHANGE_STARTING_BALANCES")
+	paperExchangeRecordedPriceFeed := getBoolEnvVariable("PAPER_EXCHANGE_RECORDED_PRICE_FEED")
+	exchangeMakerFees := getFloatMapEnvVariable("EXCHANGE_MAKER_FEES")
+	exchangeTakerFees := getFloatMapEnvVariable("EXCHANGE_TAKER_FEES")
 	awsRegion := os.Getenv("AWS_REGION")
 	awsURL := os.Getenv("AWS_URL")
 	awsAccessKey := os.Getenv("AWS_ACCESS_KEY")
@@ -91,6 +166,9 @@ func loadProperties() *properties {
 	clientTableName := os.Getenv("AWS_DYNAMODB_CLIENT_TABLE_NAME")
 	operationTableName := os.Getenv("AWS_DYNAMODB_OPERATION_TABLE_NAME")
 	credentialsTableName := os.Getenv("AWS_DYNAMODB_CREDENTIALS_TABLE_NAME")
+	outboxTableName := os.Getenv("AWS_DYNAMODB_OUTBOX_TABLE_NAME")
+	ledgerTableName := os.Getenv("AWS_DYNAMODB_LEDGER_TABLE_NAME")
+	priceTableName := os.Getenv("AWS_DYNAMODB_PRICE_TABLE_NAME")
 	cacheSecretName := os.Getenv("AWS_SECRETS_MANAGER_CACHE_SECRET_NAME")
 	encryptionSecretName := os.Getenv("AWS_SECRETS_MANAGER_ENCRYPTION_SECRET_NAME")
 	cacheKeyTTL := getIntEnvVariable("CACHE_KEY_TTL_SECONDS")
@@ -100,11 +178,46 @@ func loadProperties() *properties {
 		Profile:                         profile,
 		MinimumCryptoSellOperation:      minimumCryptoSellOperation,
 		MinimumCryptoBuyOperation:       minimumCryptoBuyOperation,
-		SimulationUrl:                   biscointUrl,
 		BiscointUrl:                     biscointUrl,
 		BiscointGetCryptoPath:           biscointGetCryptoPath,
 		BiscointGetBalancePath:          biscointGetBalancePath,
+		BinanceUrl:                      binanceUrl,
+		BinanceGetCryptoPath:            binanceGetCryptoPath,
+		BinanceGetBalancePath:           binanceGetBalancePath,
+		BinanceRecvWindow:               time.Duration(binanceRecvWindow) * time.Millisecond,
 		CryptoOperationExecutorTopicArn: cryptoOperationExecutorTopicArn,
+		CryptoValidatorTopicArn:         cryptoValidatorTopicArn,
+		DefaultClientTimezone:           defaultClientTimezone,
+		HandlerMaxConcurrency:           handlerMaxConcurrency,
+		HTTPClientTimeout:               time.Duration(httpClientTimeout) * time.Second,
+		SnsVerifySignature:              snsVerifySignature,
+		ClientLockLease:                 time.Duration(clientLockLease) * time.Second,
+		LockReleaseTimeout:              time.Duration(lockReleaseTimeout) * time.Second,
+		ReaperLockLease:                 time.Duration(reaperLockLease) * time.Second,
+		RelayGracePeriod:                time.Duration(relayGracePeriod) * time.Second,
+		QuoteMaxAge:                     time.Duration(quoteMaxAge) * time.Second,
+		RequestMaxAge:                   time.Duration(requestMaxAge) * time.Second,
+		ExchangeRetry: &retry{
+			MaxAttempts:          exchangeRetryMaxAttempts,
+			BaseBackoff:          time.Duration(exchangeRetryBaseBackoff) * time.Millisecond,
+			MaxBackoff:           time.Duration(exchangeRetryMaxBackoff) * time.Millisecond,
+			Jitter:               exchangeRetryJitter,
+			RetryableStatusCodes: exchangeRetryStatusCodes,
+		},
+		CircuitBreaker: &circuitBreaker{
+			FailureThreshold: circuitBreakerFailureThreshold,
+			FailureWindow:    time.Duration(circuitBreakerFailureWindow) * time.Second,
+			OpenDuration:     time.Duration(circuitBreakerOpenDuration) * time.Second,
+			ProbeTimeout:     time.Duration(circuitBreakerProbeTimeout) * time.Second,
+		},
+		PaperExchange: &paperExchange{
+			StartingBalances:  paperExchangeStartingBalances,
+			RecordedPriceFeed: paperExchangeRecordedPriceFeed,
+		},
+		ExchangeFees: &exchangeFees{
+			Maker: exchangeMakerFees,
+			Taker: exchangeTakerFees,
+		},
 		Aws: &aws{
 			Config: &awsConfig{
 				Region:         awsRegion,
@@ -118,6 +231,9 @@ func loadProperties() *properties {
 				ClientTableName:      &clientTableName,
 				OperationTableName:   &operationTableName,
 				CredentialsTableName: &credentialsTableName,
+				OutboxTableName:      &outboxTableName,
+				LedgerTableName:      &ledgerTableName,
+				PriceTableName:       &priceTableName,
 			},
 			SecretsManager: &secretsManager{
 				CacheSecretName:      cacheSecretName,
@@ -157,3 +273,39 @@ func getIntEnvVariable(key string) int {
 
 	return value
 }
+
+func getIntListEnvVariable(key string) []int {
+	values := make([]int, 0)
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			panic(err.Error() + ". Failed to load property \"" + key + "\" from environment")
+		}
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// getFloatMapEnvVariable parses "KEY:value" items separated by comma, e.g. "BRL:10000,BTC:0.5".
+func getFloatMapEnvVariable(key string) map[string]float64 {
+	values := map[string]float64{}
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		name, amount, _ := strings.Cut(item, ":")
+		value, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+		if err != nil {
+			panic(err.Error() + ". Failed to load property \"" + key + "\" from environment")
+		}
+		values[strings.TrimSpace(name)] = value
+	}
+
+	return values
+}
@@ -7,5 +7,5 @@ import (
 
 // HandlerAdapter is an adapter class. Used for handler.Handler implementation.
 type HandlerAdapter interface {
-	Handle(context context.Context, event events.SQSEvent) error
+	Handle(context context.Context, event events.SQSEvent) (events.SQSEventResponse, error)
 }
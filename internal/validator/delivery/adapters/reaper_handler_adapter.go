@@ -0,0 +1,11 @@
+package adapters
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
+)
+
+// ReaperHandlerAdapter is an adapter class. Used for handler.ReaperHandler implementation.
+type ReaperHandlerAdapter interface {
+	Handle(context context.Context) (*dto.ReaperReport, error)
+}
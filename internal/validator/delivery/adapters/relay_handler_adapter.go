@@ -0,0 +1,11 @@
+package adapters
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
+)
+
+// RelayHandlerAdapter is an adapter class. Used for handler.RelayHandler implementation.
+type RelayHandlerAdapter interface {
+	Handle(context context.Context) (*dto.RelayReport, error)
+}
@@ -0,0 +1,14 @@
+package adapters
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+// SignatureVerifierAdapter is an adapter class. Used for verifier.SNSSignatureVerifier implementation.
+type SignatureVerifierAdapter interface {
+	// Verify checks the notification signature against the certificate SNS used to sign it. Returns error if the
+	// signature is not valid or if the certificate could not be retrieved.
+	Verify(ctx context.Context, notification *dto.SNSNotification) custom_error.BaseErrorAdapter
+}
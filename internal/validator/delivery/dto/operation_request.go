@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/analysis_strength"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/operation_type"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
@@ -8,17 +9,23 @@ import (
 )
 
 type OperationRequest struct {
-	ClientId      string                       `json:"client_id"`
-	OperationTypo operation_type.OperationType `json:"operation"`
-	Symbol        symbol.Symbol                `json:"symbol"`
-	StartTime     time.Time                    `json:"start_time"`
+	ClientId      string                             `json:"client_id"`
+	OperationTypo operation_type.OperationType       `json:"operation"`
+	Symbol        symbol.Symbol                      `json:"symbol"`
+	Analysis      analysis_strength.AnalysisStrength `json:"analysis"`
+	StartTime     time.Time                          `json:"start_time"`
+	Price         float64                            `json:"price,omitempty"`
+	MessageId     string                             `json:"-"`
 }
 
 func (o *OperationRequest) ToModel() *model.OperationRequest {
 	return &model.OperationRequest{
-		ClientId:  o.ClientId,
-		Operation: o.OperationTypo,
-		Symbol:    o.Symbol,
-		StartTime: o.StartTime,
+		ClientId:    o.ClientId,
+		Operation:   o.OperationTypo,
+		Symbol:      o.Symbol,
+		Analysis:    o.Analysis,
+		StartTime:   o.StartTime,
+		SignalPrice: o.Price,
+		MessageId:   o.MessageId,
 	}
 }
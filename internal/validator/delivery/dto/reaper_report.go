@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+)
+
+type ReaperReport struct {
+	Released    []string `json:"released"`
+	Held        []string `json:"held"`
+	Failed      []string `json:"failed"`
+	Compensated []string `json:"compensated"`
+}
+
+func ReaperReportDto(report *model.ReaperReport) *ReaperReport {
+	return &ReaperReport{
+		Released:    report.Released,
+		Held:        report.Held,
+		Failed:      report.Failed,
+		Compensated: report.Compensated,
+	}
+}
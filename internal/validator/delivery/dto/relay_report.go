@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+)
+
+type RelayReport struct {
+	Sent      []string `json:"sent"`
+	Discarded []string `json:"discarded"`
+	Failed    []string `json:"failed"`
+}
+
+func RelayReportDto(report *model.RelayReport) *RelayReport {
+	return &RelayReport{
+		Sent:      report.Sent,
+		Discarded: report.Discarded,
+		Failed:    report.Failed,
+	}
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "strings"
+
+const snsNotificationType = "Notification"
+
+// SNSNotification is the envelope added by SNS to messages delivered to SQS when raw message delivery is disabled.
+type SNSNotification struct {
+	Type              string                         `json:"Type"`
+	MessageId         string                         `json:"MessageId"`
+	TopicArn          string                         `json:"TopicArn"`
+	Subject           string                         `json:"Subject,omitempty"`
+	Message           string                         `json:"Message"`
+	Timestamp         string                         `json:"Timestamp"`
+	SignatureVersion  string                         `json:"SignatureVersion"`
+	Signature         string                         `json:"Signature"`
+	SigningCertURL    string                         `json:"SigningCertURL"`
+	UnsubscribeURL    string                         `json:"UnsubscribeURL"`
+	MessageAttributes map[string]SNSMessageAttribute `json:"MessageAttributes,omitempty"`
+}
+
+type SNSMessageAttribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// IsNotification returns true if the body parsed is an SNS notification envelope.
+func (s *SNSNotification) IsNotification() bool {
+	return s.Type == snsNotificationType && s.Message != ""
+}
+
+// StringToSign builds the canonical string SNS signs for notification messages, Subject is only part of it when
+// present.
+func (s *SNSNotification) StringToSign() string {
+	builder := strings.Builder{}
+
+	write := func(key string, value string) {
+		builder.WriteString(key + "\n" + value + "\n")
+	}
+
+	write("Message", s.Message)
+	write("MessageId", s.MessageId)
+	if s.Subject != "" {
+		write("Subject", s.Subject)
+	}
+	write("Timestamp", s.Timestamp)
+	write("TopicArn", s.TopicArn)
+	write("Type", s.Type)
+
+	return builder.String()
+}
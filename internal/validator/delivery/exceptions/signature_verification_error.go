@@ -0,0 +1,7 @@
+package exceptions
+
+import "github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+
+func SignatureVerificationError(err error, internalError string) custom_error.BaseErrorAdapter {
+	return custom_error.NewBaseError(err, internalError, "Error while verifying the SNS message signature")
+}
@@ -3,47 +3,215 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/delivery/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/exceptions"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	exceptions2 "github.com/brienze1/crypto-robot-validator/internal/validator/domain/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"sync"
 )
 
 type handler struct {
-	validationUseCase adapters.ValidationUseCaseAdapter
-	logger            adapters.LoggerAdapter
+	validationUseCase adapters.ValidationUseCaseFactory
+	signatureVerifier adapters2.SignatureVerifierAdapter
+	logger            adapters.LoggerFactory
+	maxConcurrency    int
+	verifySignature   bool
+}
+
+type record struct {
+	invocationId     string
+	message          events.SQSMessage
+	operationRequest *dto.OperationRequest
+	logger           adapters.LoggerAdapter
 }
 
 // Handler constructor method, used to inject dependencies.
-func Handler(validationUseCase adapters.ValidationUseCaseAdapter, logger adapters.LoggerAdapter) *handler {
+func Handler(
+	validationUseCase adapters.ValidationUseCaseFactory,
+	signatureVerifier adapters2.SignatureVerifierAdapter,
+	logger adapters.LoggerFactory,
+) *handler {
+	maxConcurrency := properties.Properties().HandlerMaxConcurrency
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+
 	return &handler{
 		validationUseCase: validationUseCase,
+		signatureVerifier: signatureVerifier,
 		logger:            logger,
+		maxConcurrency:    maxConcurrency,
+		verifySignature:   properties.Properties().SnsVerifySignature,
 	}
 }
 
-func (h *handler) Handle(context context.Context, event events.SQSEvent) error {
+// Handle validates every record of the SQS batch. Records of the same client are validated in arrival order, records of
+// different clients run concurrently limited by HANDLER_MAX_CONCURRENCY. Failed records are returned on
+// events.SQSEventResponse BatchItemFailures so only those are redelivered, records rejected with a non retryable error
+// code are logged and acknowledged, as are records that cannot be parsed or fail the SNS signature verification. Each
+// record logs with its message id as correlation id. Validations are cancelled
+// LOCK_RELEASE_TIMEOUT_SECONDS before the lambda deadline, leaving time for their locks to be released.
+func (h *handler) Handle(context context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
 	ctx, _ := lambdacontext.FromContext(context)
-	h.logger.SetCorrelationID(ctx.AwsRequestID)
-	h.logger.Info("Event received", event, ctx)
+	logger := h.logger(ctx.AwsRequestID)
+	logger.Info("Event received", event, ctx)
+
+	validationContext, cancel := h.validationContext(context)
+	defer cancel()
+
+	failed := map[string]bool{}
+	clientIds := make([]string, 0)
+	clientRecords := map[string][]*record{}
+
+	for _, message := range event.Records {
+		recordLogger := h.logger(message.MessageId)
+
+		operationRequestDto, err := h.parse(validationContext, message, recordLogger)
+		if err != nil {
+			if error_code.ErrorCode(err.Code()).Retryable() {
+				failed[message.MessageId] = true
+			} else {
+				recordLogger.Info("Record rejected, it will not be redelivered", err.Code())
+			}
+			continue
+		}
+
+		if _, exists := clientRecords[operationRequestDto.ClientId]; !exists {
+			clientIds = append(clientIds, operationRequestDto.ClientId)
+		}
+		clientRecords[operationRequestDto.ClientId] = append(clientRecords[operationRequestDto.ClientId], &record{
+			invocationId:     ctx.AwsRequestID,
+			message:          message,
+			operationRequest: operationRequestDto,
+			logger:           recordLogger,
+		})
+	}
+
+	var mutex sync.Mutex
+	var waitGroup sync.WaitGroup
+	semaphore := make(chan struct{}, h.maxConcurrency)
+
+	for _, clientId := range clientIds {
+		waitGroup.Add(1)
+		semaphore <- struct{}{}
+
+		go func(records []*record) {
+			defer waitGroup.Done()
+			defer func() { <-semaphore }()
+
+			for _, r := range records {
+				if err := h.validate(validationContext, r); err != nil {
+					mutex.Lock()
+					failed[r.message.MessageId] = true
+					mutex.Unlock()
+				}
+			}
+		}(clientRecords[clientId])
+	}
+
+	waitGroup.Wait()
+
+	response := events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}
+	for _, message := range event.Records {
+		if failed[message.MessageId] {
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{
+				ItemIdentifier: message.MessageId,
+			})
+		}
+	}
+
+	logger.Info("Event finished", response, ctx)
+	return response, nil
+}
+
+// validationContext returns a context derived from the lambda context with its deadline moved
+// LOCK_RELEASE_TIMEOUT_SECONDS earlier, the parent context is returned as it is (with a cancel func) when it has no
+// deadline.
+func (h *handler) validationContext(parent context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := parent.Deadline()
+	if !ok {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithDeadline(parent, deadline.Add(-properties.Properties().LockReleaseTimeout))
+}
+
+// parse reads the operation request from the record body. Bodies wrapped in an SNS notification envelope are unwrapped
+// (and have their signature verified when SNS_VERIFY_SIGNATURE is enabled), raw delivery bodies are parsed as they are.
+// Raw delivery bodies cannot be verified, so they are rejected with error_code.InvalidSignature when
+// SNS_VERIFY_SIGNATURE is enabled. Bodies that are not an operation request are rejected with
+// error_code.InvalidMessage. The SNS message id, or the SQS message id for raw delivery, is kept on the request.
+func (h *handler) parse(ctx context.Context, message events.SQSMessage, logger adapters.LoggerAdapter) (*dto.OperationRequest, custom_error.BaseErrorAdapter) {
+	body := message.Body
+	messageId := message.MessageId
+
+	notification := &dto.SNSNotification{}
+	if err := json.Unmarshal([]byte(body), notification); err == nil && notification.IsNotification() {
+		if h.verifySignature {
+			if err := h.signatureVerifier.Verify(ctx, notification); err != nil {
+				return nil, h.abort(logger, err, "Error while trying to verify the SNS message signature", message)
+			}
+		}
+
+		logger.Info("SNS envelope unwrapped", notification.MessageId, notification.TopicArn, notification.MessageAttributes)
+		body = notification.Message
+		messageId = notification.MessageId
+	} else if h.verifySignature {
+		return nil, h.abortWithCode(logger, errors.New("message is not an SNS notification"), "Error while trying to verify the SNS message signature", error_code.InvalidSignature, message)
+	}
 
 	operationRequestDto := &dto.OperationRequest{}
-	if err := json.Unmarshal([]byte(event.Records[0].Body), operationRequestDto); err != nil {
-		return h.abort(err, "Error while trying to parse the SNS message", event)
+	if err := json.Unmarshal([]byte(body), operationRequestDto); err != nil {
+		return nil, h.abortWithCode(logger, err, "Error while trying to parse the SNS message", error_code.InvalidMessage, message)
 	}
+	operationRequestDto.MessageId = messageId
+
+	return operationRequestDto, nil
+}
+
+func (h *handler) validate(ctx context.Context, r *record) (err error) {
+	r.logger.Info("Record received", r.message)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = h.abort(r.logger, fmt.Errorf("%v", recovered), "Panic while trying to run ValidationUseCase", r.operationRequest)
+		}
+	}()
 
-	if err := h.validationUseCase.Validate(operationRequestDto.ToModel()); err != nil {
-		return h.abort(err, "Error while trying to run ValidationUseCase", operationRequestDto)
+	if err := h.validationUseCase(r.invocationId, r.logger).Validate(ctx, r.operationRequest.ToModel()); err != nil {
+		var unlockError *exceptions2.UnlockError
+		if errors.As(err, &unlockError) {
+			r.logger.Error(unlockError, "Record failed with locks still held", unlockError.ClientId, unlockError.Locks)
+		}
+
+		validationError := h.abort(r.logger, err, "Error while trying to run ValidationUseCase", r.operationRequest)
+		if unlockError == nil && !error_code.ErrorCode(validationError.Code()).Retryable() {
+			r.logger.Info("Record rejected, it will not be redelivered", validationError.Code())
+			return nil
+		}
+		return validationError
 	}
 
-	h.logger.Info("Event succeeded", event, ctx)
+	r.logger.Info("Record succeeded", r.message)
 	return nil
 }
 
-func (h *handler) abort(err error, message string, metadata ...interface{}) custom_error.BaseErrorAdapter {
+func (h *handler) abort(logger adapters.LoggerAdapter, err error, message string, metadata ...interface{}) custom_error.BaseErrorAdapter {
 	handlerError := exceptions.HandlerError(err, message)
-	h.logger.Error(handlerError, "Event failed: "+message, metadata)
+	logger.Error(handlerError, "Record failed: "+message, metadata)
+	return handlerError
+}
+
+func (h *handler) abortWithCode(logger adapters.LoggerAdapter, err error, message string, code error_code.ErrorCode, metadata ...interface{}) custom_error.BaseErrorAdapter {
+	handlerError := h.abort(logger, err, message, metadata...)
+	handlerError.SetCode(code.Name())
 	return handlerError
 }
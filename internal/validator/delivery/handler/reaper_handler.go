@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/exceptions"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+)
+
+type reaperHandler struct {
+	reaperUseCase adapters.ReaperUseCaseFactory
+	logger        adapters.LoggerFactory
+}
+
+// ReaperHandler constructor method, used to inject dependencies.
+func ReaperHandler(reaperUseCase adapters.ReaperUseCaseFactory, logger adapters.LoggerFactory) *reaperHandler {
+	return &reaperHandler{
+		reaperUseCase: reaperUseCase,
+		logger:        logger,
+	}
+}
+
+// Handle runs the stale lock reaper, it is triggered on a schedule and logs with the lambda request id as correlation
+// id. Clients that could not be released are listed on the report and do not fail the execution, they are retried on
+// the next one.
+func (h *reaperHandler) Handle(context context.Context) (*dto.ReaperReport, error) {
+	ctx, _ := lambdacontext.FromContext(context)
+	logger := h.logger(ctx.AwsRequestID)
+	logger.Info("Reaper started", ctx)
+
+	report, err := h.reaperUseCase(logger).Reap(context)
+	if err != nil {
+		handlerError := exceptions.HandlerError(err, "Error while trying to run ReaperUseCase")
+		logger.Error(handlerError, "Reaper failed: Error while trying to run ReaperUseCase")
+		return nil, handlerError
+	}
+
+	reportDto := dto.ReaperReportDto(report)
+
+	logger.Info("Reaper finished", reportDto, ctx)
+	return reportDto, nil
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/exceptions"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+)
+
+type relayHandler struct {
+	relayUseCase adapters.RelayUseCaseFactory
+	logger       adapters.LoggerFactory
+}
+
+// RelayHandler constructor method, used to inject dependencies.
+func RelayHandler(relayUseCase adapters.RelayUseCaseFactory, logger adapters.LoggerFactory) *relayHandler {
+	return &relayHandler{
+		relayUseCase: relayUseCase,
+		logger:       logger,
+	}
+}
+
+// Handle runs the outbox relay, it is triggered on a schedule and logs with the lambda request id as correlation id.
+// Entries that could not be relayed are listed on the report and do not fail the execution, they are retried on the
+// next one.
+func (h *relayHandler) Handle(context context.Context) (*dto.RelayReport, error) {
+	ctx, _ := lambdacontext.FromContext(context)
+	logger := h.logger(ctx.AwsRequestID)
+	logger.Info("Relay started", ctx)
+
+	report, err := h.relayUseCase(logger).Relay(context)
+	if err != nil {
+		handlerError := exceptions.HandlerError(err, "Error while trying to run RelayUseCase")
+		logger.Error(handlerError, "Relay failed: Error while trying to run RelayUseCase")
+		return nil, handlerError
+	}
+
+	reportDto := dto.RelayReportDto(report)
+
+	logger.Info("Relay finished", reportDto, ctx)
+	return reportDto, nil
+}
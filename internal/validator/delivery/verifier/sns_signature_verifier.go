@@ -0,0 +1,168 @@
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/exceptions"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+)
+
+// signingCertHost only allows certificates served by SNS regional endpoints.
+var signingCertHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+type snsSignatureVerifier struct {
+	logger       adapters.LoggerAdapter
+	client       adapters2.HTTPClientAdapter
+	topicArn     string
+	mutex        sync.Mutex
+	certificates map[string]*x509.Certificate
+}
+
+// SNSSignatureVerifier constructor method, used to inject dependencies. Only notifications published to the
+// AWS_SNS_TOPIC_ARN_CRYPTO_VALIDATOR topic are accepted.
+func SNSSignatureVerifier(logger adapters.LoggerAdapter, client adapters2.HTTPClientAdapter) *snsSignatureVerifier {
+	return &snsSignatureVerifier{
+		logger:       logger,
+		client:       client,
+		topicArn:     properties.Properties().CryptoValidatorTopicArn,
+		certificates: map[string]*x509.Certificate{},
+	}
+}
+
+// Verify checks the notification signature against the certificate SNS used to sign it. SignatureVersion 1 is signed
+// with SHA1 and SignatureVersion 2 with SHA256. Certificates are only downloaded from SNS hosts over https and are
+// cached by URL. Returns error with error_code.InvalidSignature if the notification was not published to the validator
+// topic or is not signed by SNS, errors while downloading the certificate have no code.
+func (s *snsSignatureVerifier) Verify(ctx context.Context, notification *dto.SNSNotification) custom_error.BaseErrorAdapter {
+	s.logger.Info("Verify signature start", notification.MessageId, notification.TopicArn)
+
+	if notification.TopicArn != s.topicArn {
+		return s.abortWithCode(nil, "Notification topic is not the validator topic: "+notification.TopicArn, error_code.InvalidSignature)
+	}
+
+	var hash crypto.Hash
+	var digest []byte
+	switch notification.SignatureVersion {
+	case "1":
+		sum := sha1.Sum([]byte(notification.StringToSign()))
+		hash, digest = crypto.SHA1, sum[:]
+	case "2":
+		sum := sha256.Sum256([]byte(notification.StringToSign()))
+		hash, digest = crypto.SHA256, sum[:]
+	default:
+		return s.abortWithCode(nil, "Signature version not supported: "+notification.SignatureVersion, error_code.InvalidSignature)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(notification.Signature)
+	if err != nil {
+		return s.abortWithCode(err, "Error while trying to decode signature", error_code.InvalidSignature)
+	}
+
+	if !trustedCertURL(notification.SigningCertURL) {
+		err = errors.New("signing certificate url not trusted: " + notification.SigningCertURL)
+		return s.abortWithCode(err, "Error while trying to get signing certificate", error_code.InvalidSignature)
+	}
+
+	certificate, err := s.certificate(ctx, notification.SigningCertURL)
+	if err != nil {
+		return s.abort(err, "Error while trying to get signing certificate")
+	}
+
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return s.abortWithCode(nil, "Signing certificate public key is not RSA", error_code.InvalidSignature)
+	}
+
+	if err := rsa.VerifyPKCS1v15(publicKey, hash, digest, signature); err != nil {
+		return s.abortWithCode(err, "Signature is not valid", error_code.InvalidSignature)
+	}
+
+	s.logger.Info("Verify signature finish", notification.MessageId)
+	return nil
+}
+
+func (s *snsSignatureVerifier) certificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	s.mutex.Lock()
+	certificate, cached := s.certificates[certURL]
+	s.mutex.Unlock()
+
+	if cached {
+		return certificate, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New("signing certificate status code not Ok: " + response.Status)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, errors.New("signing certificate is not PEM encoded")
+	}
+
+	certificate, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.certificates[certURL] = certificate
+	s.mutex.Unlock()
+
+	return certificate, nil
+}
+
+// trustedCertURL returns true if the certificate url is served by an SNS host over https.
+func trustedCertURL(certURL string) bool {
+	parsedURL, err := url.Parse(certURL)
+	if err != nil {
+		return false
+	}
+	return parsedURL.Scheme == "https" && signingCertHost.MatchString(parsedURL.Hostname())
+}
+
+func (s *snsSignatureVerifier) abort(err error, message string) custom_error.BaseErrorAdapter {
+	signatureVerificationError := exceptions.SignatureVerificationError(err, message)
+	s.logger.Error(signatureVerificationError, "Verify signature failed: "+message)
+	return signatureVerificationError
+}
+
+func (s *snsSignatureVerifier) abortWithCode(err error, message string, code error_code.ErrorCode) custom_error.BaseErrorAdapter {
+	signatureVerificationError := s.abort(err, message)
+	signatureVerificationError.SetCode(code.Name())
+	return signatureVerificationError
+}
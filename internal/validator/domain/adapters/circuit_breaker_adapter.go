@@ -0,0 +1,27 @@
+package adapters
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type CircuitBreakerAdapter interface {
+	// Allow checks if a request to the external service can be made. Returns an error with error_code.ServiceUnavailable
+	// if the circuit is open, or half-open with a trial request already running.
+	Allow(ctx context.Context) custom_error.BaseErrorAdapter
+
+	// Check reads the circuit state without acquiring the half-open trial request. Returns an error with
+	// error_code.ServiceUnavailable if the circuit is open, or half-open with a trial request already running.
+	Check(ctx context.Context) custom_error.BaseErrorAdapter
+
+	// Success records a request that reached the external service, closing the circuit.
+	Success(ctx context.Context)
+
+	// Release records a request that reached the external service but failed for another reason, releasing the
+	// half-open trial request without changing the circuit state.
+	Release(ctx context.Context)
+
+	// Failure records a request that failed because the external service is unavailable, opening the circuit when the
+	// failure threshold is reached.
+	Failure(ctx context.Context)
+}
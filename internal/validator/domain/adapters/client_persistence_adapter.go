@@ -1,17 +1,39 @@
 package adapters
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 )
 
 type ClientPersistenceAdapter interface {
 	// GetClient will find model.Client on client repository using clientId as key
-	GetClient(clientId string) (*model.Client, custom_error.BaseErrorAdapter)
+	GetClient(ctx context.Context, clientId string) (*model.Client, custom_error.BaseErrorAdapter)
 
-	// Lock will update model.Client setting flag locked as true on client repository
-	Lock(client *model.Client) custom_error.BaseErrorAdapter
+	// GetLockedClients will find every model.Client with flag locked set as true on client repository
+	GetLockedClients(ctx context.Context) ([]*model.Client, custom_error.BaseErrorAdapter)
 
-	// Unlock will update model.Client setting flag locked as false on client repository
-	Unlock(client *model.Client) custom_error.BaseErrorAdapter
+	// Lock will update model.Client setting flag locked as true on client repository, the lock is a lease owned by the
+	// caller that expires after a while
+	Lock(ctx context.Context, client *model.Client) custom_error.BaseErrorAdapter
+
+	// LockUntil will update model.Client locked_until value on client repository, keeping the client from operating
+	// until the date is reached (stop loss block for example)
+	LockUntil(ctx context.Context, client *model.Client) custom_error.BaseErrorAdapter
+
+	// SaveReservation will update model.Client balances and reservations on client repository, the model.Operation that
+	// reserved them and its event model.OutboxEntry are created in the same transaction
+	SaveReservation(ctx context.Context, client *model.Client, operation *model.Operation, event *model.OutboxEntry) custom_error.BaseErrorAdapter
+
+	// ReleaseReservation will update model.Client balances and reservations on client repository, the model.Operation
+	// that reserved them is updated with its new status in the same transaction
+	ReleaseReservation(ctx context.Context, client *model.Client, operation *model.Operation) custom_error.BaseErrorAdapter
+
+	// Unlock will update model.Client setting flag locked as false on client repository, only if the lock is owned by
+	// the caller
+	Unlock(ctx context.Context, client *model.Client) custom_error.BaseErrorAdapter
+
+	// ReleaseLock will update model.Client setting flag locked as false on client repository regardless of the lock
+	// owner, used to release expired or abandoned locks
+	ReleaseLock(ctx context.Context, client *model.Client) custom_error.BaseErrorAdapter
 }
@@ -1,10 +1,11 @@
 package adapters
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 )
 
 type EventServiceAdapter interface {
 	// Send event containing object to topic.
-	Send(object interface{}) custom_error.BaseErrorAdapter
+	Send(ctx context.Context, object interface{}) custom_error.BaseErrorAdapter
 }
@@ -0,0 +1,12 @@
+package adapters
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/exchange"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type ExchangeRegistryAdapter interface {
+	// Get returns the crypto service and the circuit breaker of the exchange (exchange.Exchange). Returns an error
+	// with error_code.ExchangeNotSupported if the exchange is not registered.
+	Get(exchange exchange.Exchange) (CryptoServiceAdapter, CircuitBreakerAdapter, custom_error.BaseErrorAdapter)
+}
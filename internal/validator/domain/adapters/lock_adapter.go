@@ -1,11 +1,27 @@
 package adapters
 
-import "github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
 
 type LockPersistenceAdapter interface {
-	// Lock will set the key on cache with TTL active. Returns error if a problem occurs while trying to persist on cache.
-	Lock(key string) custom_error.BaseErrorAdapter
+	// Lock will atomically set the key on cache with an owner token and TTL active. Returns error if key is already
+	// locked or if a problem occurs while trying to persist on cache.
+	Lock(ctx context.Context, key string) custom_error.BaseErrorAdapter
 
-	// Unlock will remove the key from cache. Returns error if a problem occurs while trying to delete from cache.
-	Unlock(key string) custom_error.BaseErrorAdapter
+	// Unlock will remove the key from cache if it is still owned by this process. Returns error if a problem occurs
+	// while trying to delete from cache.
+	Unlock(ctx context.Context, key string) custom_error.BaseErrorAdapter
+
+	// Extend will renew the key TTL if it is still owned by this process. Returns error if key is not owned anymore or
+	// if a problem occurs while trying to update the cache.
+	Extend(ctx context.Context, key string) custom_error.BaseErrorAdapter
+
+	// Exists will check if the key is set on cache, locked by this or by any other process. Returns error if a problem
+	// occurs while trying to read from cache.
+	Exists(ctx context.Context, key string) (bool, custom_error.BaseErrorAdapter)
+
+	// Watchdog will keep extending the key TTL in background until the key is unlocked.
+	Watchdog(ctx context.Context, key string)
 }
@@ -6,3 +6,6 @@ type LoggerAdapter interface {
 	Error(err error, message string, metadata ...interface{})
 	Warning(err error, message string, metadata ...interface{})
 }
+
+// LoggerFactory creates a LoggerAdapter bound to the given correlation id.
+type LoggerFactory func(correlationId string) LoggerAdapter
@@ -1,11 +1,12 @@
 package adapters
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 )
 
 type OperationPersistenceAdapter interface {
-	// Save model.Operation in operation repository.
-	Save(operation *model.Operation) custom_error.BaseErrorAdapter
+	// Get model.Operation from operation repository, returns nil operation if it does not exist.
+	Get(ctx context.Context, operationId string) (*model.Operation, custom_error.BaseErrorAdapter)
 }
@@ -0,0 +1,23 @@
+package adapters
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type OutboxPersistenceAdapter interface {
+	// Save model.OutboxEntry in outbox repository.
+	Save(ctx context.Context, entry *model.OutboxEntry) custom_error.BaseErrorAdapter
+
+	// Get model.OutboxEntry from outbox repository using entryId as key, returns nil if the entry does not exist.
+	Get(ctx context.Context, entryId string) (*model.OutboxEntry, custom_error.BaseErrorAdapter)
+
+	// GetPending model.OutboxEntry list from outbox repository filtered by outbox_type.OutboxType.
+	GetPending(ctx context.Context, outboxType outbox_type.OutboxType) ([]*model.OutboxEntry, custom_error.BaseErrorAdapter)
+
+	// UpdateStatus will update model.OutboxEntry status on outbox repository, only pending entries are updated.
+	UpdateStatus(ctx context.Context, entry *model.OutboxEntry, status outbox_status.OutboxStatus) custom_error.BaseErrorAdapter
+}
@@ -0,0 +1,25 @@
+package adapters
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type PaperExchangeAdapter interface {
+	// Exchange returns the crypto service of simulation clients of the exchange service. Balances come from the client
+	// virtual ledger and quotes from the recorded price feed, or from the exchange service ticker.
+	Exchange(exchangeService CryptoServiceAdapter) CryptoServiceAdapter
+
+	// RecordedPriceFeed returns true if quotes come from the recorded price feed, the paper exchange then makes no
+	// request to the client exchange.
+	RecordedPriceFeed() bool
+
+	// Reserve debits the operation amount from the client ledger. Reserving an operation again does nothing. The
+	// reservation is settled by the executor once the operation completes.
+	Reserve(ctx context.Context, clientId string, operation *model.Operation) custom_error.BaseErrorAdapter
+
+	// Release credits the amount reserved for the operation back to the client ledger. Does nothing if the operation
+	// is not reserved.
+	Release(ctx context.Context, clientId string, operation *model.Operation) custom_error.BaseErrorAdapter
+}
@@ -0,0 +1,15 @@
+package adapters
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+)
+
+// ReaperUseCaseAdapter adapter for usecase.ReaperUseCase.
+type ReaperUseCaseAdapter interface {
+	// Reap releases clients locked for longer than the lock lease whose client_id is no longer locked on cache.
+	Reap(ctx context.Context) (*model.ReaperReport, error)
+}
+
+// ReaperUseCaseFactory creates a ReaperUseCaseAdapter whose dependencies log with the given logger.
+type ReaperUseCaseFactory func(logger LoggerAdapter) ReaperUseCaseAdapter
@@ -0,0 +1,16 @@
+package adapters
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+)
+
+// RelayUseCaseAdapter adapter for usecase.RelayUseCase.
+type RelayUseCaseAdapter interface {
+	// Relay publishes the operation events still pending on the outbox and marks them as sent. Events of operations that
+	// are no longer CREATED are discarded.
+	Relay(ctx context.Context) (*model.RelayReport, error)
+}
+
+// RelayUseCaseFactory creates a RelayUseCaseAdapter whose dependencies log with the given logger.
+type RelayUseCaseFactory func(logger LoggerAdapter) RelayUseCaseAdapter
@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 )
 
@@ -8,6 +9,10 @@ import (
 type ValidationUseCaseAdapter interface {
 	// Validate if operation can be executed. client_id key will be locked in cache and locked flag will be set to true on
 	// client DB during execution of method. After the operation request is validated with client config, an operation is
-	// created and sent to execution via SNS topic.
-	Validate(operationRequest *model.OperationRequest) error
+	// created together with its outbox event and sent to execution via SNS topic.
+	Validate(ctx context.Context, operationRequest *model.OperationRequest) error
 }
+
+// ValidationUseCaseFactory creates a ValidationUseCaseAdapter whose dependencies log with the given logger, clients are
+// locked on behalf of the given invocation id.
+type ValidationUseCaseFactory func(invocationId string, logger LoggerAdapter) ValidationUseCaseAdapter
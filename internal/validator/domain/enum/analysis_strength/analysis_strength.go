@@ -0,0 +1,41 @@
+package analysis_strength
+
+type AnalysisStrength string
+
+const (
+	StrongBuy  AnalysisStrength = "STRONG_BUY"
+	Buy        AnalysisStrength = "BUY"
+	Neutral    AnalysisStrength = "NEUTRAL"
+	Sell       AnalysisStrength = "SELL"
+	StrongSell AnalysisStrength = "STRONG_SELL"
+)
+
+var strengths = map[AnalysisStrength]int{
+	StrongBuy:  2,
+	Buy:        1,
+	Neutral:    0,
+	Sell:       -1,
+	StrongSell: -2,
+}
+
+func (a AnalysisStrength) Name() string {
+	return string(a)
+}
+
+// IsValid returns true if value is one of the known analysis strengths.
+func (a AnalysisStrength) IsValid() bool {
+	_, ok := strengths[a]
+	return ok
+}
+
+// IsBuyAtLeast returns true if analysis is equal or less restricting than threshold for a buy operation. For example
+// STRONG_BUY is at least BUY, but NEUTRAL is not.
+func (a AnalysisStrength) IsBuyAtLeast(threshold AnalysisStrength) bool {
+	return a.IsValid() && threshold.IsValid() && strengths[a] >= strengths[threshold]
+}
+
+// IsSellAtLeast returns true if analysis is equal or less restricting than threshold for a sell operation. For example
+// STRONG_SELL is at least SELL, but NEUTRAL is not.
+func (a AnalysisStrength) IsSellAtLeast(threshold AnalysisStrength) bool {
+	return a.IsValid() && threshold.IsValid() && strengths[a] <= strengths[threshold]
+}
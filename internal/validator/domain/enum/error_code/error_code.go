@@ -0,0 +1,64 @@
+package error_code
+
+type ErrorCode string
+
+const (
+	ClientInactive       ErrorCode = "CLIENT_INACTIVE"
+	ClientLockedUntil    ErrorCode = "CLIENT_LOCKED_UNTIL"
+	ClientLocked         ErrorCode = "CLIENT_LOCKED"
+	ClientModified       ErrorCode = "CLIENT_MODIFIED"
+	SymbolNotSupported   ErrorCode = "SYMBOL_NOT_SUPPORTED"
+	SymbolNotAllowed     ErrorCode = "SYMBOL_NOT_ALLOWED"
+	InvalidAnalysis      ErrorCode = "INVALID_ANALYSIS"
+	BuyOnNotReached      ErrorCode = "BUY_ON_NOT_REACHED"
+	SellOnNotReached     ErrorCode = "SELL_ON_NOT_REACHED"
+	DayStopLoss          ErrorCode = "DAY_STOP_LOSS_REACHED"
+	MonthStopLoss        ErrorCode = "MONTH_STOP_LOSS_REACHED"
+	MinimumCashAmount    ErrorCode = "MINIMUM_CASH_AMOUNT"
+	MinimumCryptoAmount  ErrorCode = "MINIMUM_CRYPTO_AMOUNT"
+	OperationExists      ErrorCode = "OPERATION_EXISTS"
+	OperationModified    ErrorCode = "OPERATION_MODIFIED"
+	OutboxEntryModified  ErrorCode = "OUTBOX_ENTRY_MODIFIED"
+	ServiceUnavailable   ErrorCode = "SERVICE_UNAVAILABLE"
+	InvalidQuote         ErrorCode = "INVALID_QUOTE"
+	StaleQuote           ErrorCode = "STALE_QUOTE"
+	ExchangeNotSupported ErrorCode = "EXCHANGE_NOT_SUPPORTED"
+	LedgerModified       ErrorCode = "LEDGER_MODIFIED"
+	LedgerBalance        ErrorCode = "INSUFFICIENT_LEDGER_BALANCE"
+	StaleRequest         ErrorCode = "STALE_REQUEST"
+	PriceSlippage        ErrorCode = "PRICE_SLIPPAGE"
+	InvalidMessage       ErrorCode = "INVALID_MESSAGE"
+	InvalidSignature     ErrorCode = "INVALID_SIGNATURE"
+)
+
+func (e ErrorCode) Name() string {
+	return string(e)
+}
+
+// rejections are the codes of requests rejected by the client or symbol rules, or by the request itself. Validating
+// the same request again gives the same result.
+var rejections = map[ErrorCode]bool{
+	ClientInactive:       true,
+	ClientLockedUntil:    true,
+	SymbolNotSupported:   true,
+	SymbolNotAllowed:     true,
+	InvalidAnalysis:      true,
+	BuyOnNotReached:      true,
+	SellOnNotReached:     true,
+	DayStopLoss:          true,
+	MonthStopLoss:        true,
+	MinimumCashAmount:    true,
+	MinimumCryptoAmount:  true,
+	ExchangeNotSupported: true,
+	LedgerBalance:        true,
+	StaleRequest:         true,
+	PriceSlippage:        true,
+	InvalidMessage:       true,
+	InvalidSignature:     true,
+}
+
+// Retryable returns false for rejection codes, errors without a code or with any other code are transient and the
+// request may succeed when redelivered.
+func (e ErrorCode) Retryable() bool {
+	return !rejections[e]
+}
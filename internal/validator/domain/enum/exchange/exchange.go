@@ -0,0 +1,12 @@
+package exchange
+
+type Exchange string
+
+const (
+	Biscoint Exchange = "BISCOINT"
+	Binance  Exchange = "BINANCE"
+)
+
+func (e Exchange) Name() string {
+	return string(e)
+}
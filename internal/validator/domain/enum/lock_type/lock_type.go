@@ -0,0 +1,12 @@
+package lock_type
+
+type LockType string
+
+const (
+	ClientId LockType = "CLIENT_ID"
+	Client   LockType = "CLIENT"
+)
+
+func (l LockType) Name() string {
+	return string(l)
+}
@@ -0,0 +1,14 @@
+package outbox_status
+
+type OutboxStatus string
+
+const (
+	Pending   OutboxStatus = "PENDING"
+	Sent      OutboxStatus = "SENT"
+	Discarded OutboxStatus = "DISCARDED"
+	Resolved  OutboxStatus = "RESOLVED"
+)
+
+func (o OutboxStatus) Name() string {
+	return string(o)
+}
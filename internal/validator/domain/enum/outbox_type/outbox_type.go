@@ -0,0 +1,13 @@
+package outbox_type
+
+type OutboxType string
+
+const (
+	Event        OutboxType = "EVENT"
+	Compensation OutboxType = "COMPENSATION"
+	Unlock       OutboxType = "UNLOCK"
+)
+
+func (o OutboxType) Name() string {
+	return string(o)
+}
@@ -3,5 +3,6 @@ package status
 type Status string
 
 const (
-	Created Status = "CREATED"
+	Created   Status = "CREATED"
+	Cancelled Status = "CANCELLED"
 )
@@ -4,10 +4,35 @@ type Symbol string
 
 const (
 	Bitcoin    Symbol = "BTC"
+	Ethereum   Symbol = "ETH"
 	Brl        Symbol = "BRL"
 	BitcoinBRL Symbol = "BTCBRL"
 )
 
+// cryptos is the registry of crypto assets supported by the validator, with the default minimum order size of each
+// asset in its own units.
+var cryptos = map[Symbol]float64{
+	Bitcoin:  0.001,
+	Ethereum: 0.01,
+}
+
 func (s Symbol) Name() string {
 	return string(s)
 }
+
+// IsCrypto returns true if symbol is a supported crypto asset that can be traded.
+func (s Symbol) IsCrypto() bool {
+	_, ok := cryptos[s]
+	return ok
+}
+
+// MinimumOrderSize returns the default smallest amount of the crypto asset an operation can trade, 0 if symbol is not
+// a supported crypto asset.
+func (s Symbol) MinimumOrderSize() float64 {
+	return cryptos[s]
+}
+
+// IsSupported returns true if symbol is a supported crypto asset or the cash asset (Brl).
+func (s Symbol) IsSupported() bool {
+	return s == Brl || s.IsCrypto()
+}
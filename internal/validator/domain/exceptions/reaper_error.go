@@ -0,0 +1,9 @@
+package exceptions
+
+import (
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+func ReaperError(err error, internalError string) custom_error.BaseErrorAdapter {
+	return custom_error.NewBaseError(err, internalError, "Error while releasing stale client locks")
+}
@@ -0,0 +1,40 @@
+package exceptions
+
+import (
+	"fmt"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/lock_type"
+)
+
+// UnlockError is returned when the locks taken during the validation could not be released. Locks lists the locks
+// still held for the client, the unlock errors are kept and can be inspected with errors.Is and errors.As.
+type UnlockError struct {
+	ClientId string
+	Locks    []lock_type.LockType
+	err      error
+}
+
+func NewUnlockError(clientId string, locks []lock_type.LockType, err error) *UnlockError {
+	return &UnlockError{
+		ClientId: clientId,
+		Locks:    locks,
+		err:      err,
+	}
+}
+
+func (u *UnlockError) Error() string {
+	return fmt.Sprintf("locks %v still held for client %s: %v", u.Locks, u.ClientId, u.err)
+}
+
+func (u *UnlockError) Unwrap() error {
+	return u.err
+}
+
+// Holds returns true if the lock is still held for the client.
+func (u *UnlockError) Holds(lock lock_type.LockType) bool {
+	for _, held := range u.Locks {
+		if held == lock {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package exceptions
 
 import (
 	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 )
 
@@ -9,8 +10,9 @@ func ValidationError(err error, internalError string) custom_error.BaseErrorAdap
 	return custom_error.NewBaseError(err, internalError, "Error while validating operation")
 }
 
-func NewValidationError(err string) custom_error.BaseErrorAdapter {
+func NewValidationError(code error_code.ErrorCode, err string) custom_error.BaseErrorAdapter {
 	baseError := custom_error.NewBaseError(errors.New("validation error"), err, "Error while validating operation")
 	baseError.SetLocks(true, true)
+	baseError.SetCode(code.Name())
 	return baseError
 }
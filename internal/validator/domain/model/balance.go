@@ -1,6 +1,12 @@
 package model
 
+import "github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+
 type Balance struct {
-	BrlBalance    float64
-	CryptoBalance float64
+	Assets map[symbol.Symbol]float64
+}
+
+// Amount returns the balance of the asset, zero if asset is not present.
+func (b *Balance) Amount(asset symbol.Symbol) float64 {
+	return b.Assets[asset]
 }
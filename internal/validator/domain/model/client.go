@@ -1,115 +1,298 @@
 package model
 
 import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/analysis_strength"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/exchange"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/operation_type"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/summary_type"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
+	"math"
 	"time"
 )
 
 type Client struct {
 	Id                        string
 	Active                    bool
+	Exchange                  exchange.Exchange
+	Simulation                bool
 	LockedUntil               time.Time
 	Locked                    bool
+	LockedAt                  time.Time
+	LockedBy                  string
+	LockExpiresAt             time.Time
 	CashAvailable             float64
 	CashAmount                float64
 	CashReserved              float64
-	CryptoAvailable           float64
-	CryptoAmount              float64
-	CryptoReserved            float64
+	Crypto                    map[symbol.Symbol]*CryptoBalance
 	OperationStopLoss         float64
 	DayStopLoss               float64
 	MonthStopLoss             float64
 	OperationAmountPercentage float64
-	BuyOn                     int
-	SellOn                    int
+	SlippageTolerance         float64
+	BuyOn                     analysis_strength.AnalysisStrength
+	SellOn                    analysis_strength.AnalysisStrength
 	Symbols                   []string
+	Timezone                  string
 	Summary                   []*Summary
+	Version                   int
 }
 
 // SetBalance will update client current balance, will take account of reserved values.
 func (c *Client) SetBalance(balance *Balance) {
-	c.CashAmount = balance.BrlBalance - c.CashReserved
-	c.CryptoAmount = balance.CryptoBalance - c.CryptoReserved
+	c.CashAmount = balance.Amount(symbol.Brl) - c.CashReserved
+	for _, clientSymbol := range c.Symbols {
+		c.GetCrypto(symbol.Symbol(clientSymbol))
+	}
+	for cryptoSymbol, crypto := range c.Crypto {
+		crypto.Amount = balance.Amount(cryptoSymbol) - crypto.Reserved
+	}
+}
+
+// GetCrypto returns client balance for the crypto symbol, an empty balance is created if client has none.
+func (c *Client) GetCrypto(cryptoSymbol symbol.Symbol) *CryptoBalance {
+	if c.Crypto == nil {
+		c.Crypto = map[symbol.Symbol]*CryptoBalance{}
+	}
+
+	crypto, ok := c.Crypto[cryptoSymbol]
+	if !ok {
+		crypto = &CryptoBalance{}
+		c.Crypto[cryptoSymbol] = crypto
+	}
+
+	return crypto
 }
 
 // CreateOperation validates if client current values can operate, then creates a model.Operation and also updates
-// reserved balance as necessary for the operation. Will return error in case of validation failure.
+// reserved balance as necessary for the operation. Will return error in case of validation failure, the coin quote is
+// validated (Coin Validate) before the operation is sized from it. Requests older than REQUEST_MAX_AGE_SECONDS are
+// rejected, and so are requests whose signal price moved beyond the client slippage_tolerance. The client exchange
+// taker fee is reserved on top of the operation amount, operations are sized down if the balance cannot pay both.
 func (c *Client) CreateOperation(request *OperationRequest, coin *Coin) (*Operation, custom_error.BaseErrorAdapter) {
-	timeUtils := time_utils.Time()
+	timeUtils := time_utils.TimeIn(c.Location())
+
+	if !c.Active {
+		return nil, c.abort(error_code.ClientInactive, "Client is not active")
+	}
+
+	if c.IsLockedUntil() {
+		return nil, c.abort(error_code.ClientLockedUntil, "Client locked_until date not reached")
+	}
+
+	if !request.Symbol.IsCrypto() {
+		return nil, c.abort(error_code.SymbolNotSupported, "Symbol "+request.Symbol.Name()+" is not supported")
+	}
+
+	if !c.hasSymbol(request.Symbol) {
+		return nil, c.abort(error_code.SymbolNotAllowed, "Client does not operate symbol "+request.Symbol.Name())
+	}
+
+	if request.Analysis != "" && !request.Analysis.IsValid() {
+		return nil, c.abort(error_code.InvalidAnalysis, "Operation request analysis is not valid")
+	}
+
+	if request.IsStale() {
+		return nil, c.abort(error_code.StaleRequest, "Operation request is older than max age")
+	}
+
 	for _, summary := range c.Summary {
 		if summary.Type == summary_type.Day && timeUtils.IsToday(summary.Year, summary.Month, summary.Day) && summary.Profit < c.DayStopLoss*-1 {
 			c.LockedUntil = timeUtils.Tomorrow()
-			return nil, c.abort("Client day stop loss reached")
+			return nil, c.abort(error_code.DayStopLoss, "Client day stop loss reached")
 		}
 		if summary.Type == summary_type.Month && timeUtils.IsThisMonth(summary.Year, summary.Month) && summary.Profit < c.MonthStopLoss*-1 {
 			c.LockedUntil = timeUtils.NextMonth()
-			return nil, c.abort("Client month stop loss reached")
+			return nil, c.abort(error_code.MonthStopLoss, "Client month stop loss reached")
 		}
 	}
 
-	operation := NewOperation(c.OperationStopLoss)
+	if err := coin.Validate(); err != nil {
+		return nil, err
+	}
+
+	if c.SlippageTolerance > 0 && request.Slippage(coin) > c.SlippageTolerance {
+		return nil, c.abort(error_code.PriceSlippage, "Price moved beyond client slippage_tolerance since the signal")
+	}
+
+	fee := ExchangeFee(c.Exchange)
+	operation := NewOperation(request.OperationId(), c.OperationStopLoss)
+	operation.SignalPrice = request.SignalPrice
+	operation.Simulation = c.Simulation
 
 	switch request.Operation {
 	case operation_type.Buy:
-		if coin.GetMinOperationValue(operation_type.Buy) > c.CashAmount || coin.GetMinOperationValue(operation_type.Buy) > c.CashAvailable {
-			return nil, c.abort("Client does not have minimum cash amount")
+		if !c.isBuyOnReached(request.Analysis) {
+			return nil, c.abort(error_code.BuyOnNotReached, "Analysis received does not reach client buy_on")
+		}
+
+		if coin.GetMinOperationValue(operation_type.Buy, request.Symbol, fee) > c.CashAmount || coin.GetMinOperationValue(operation_type.Buy, request.Symbol, fee) > c.CashAvailable {
+			return nil, c.abort(error_code.MinimumCashAmount, "Client does not have minimum cash amount")
 		}
 
 		expectedOperationAmount := c.CashAvailable * c.OperationAmountPercentage / 100
 
-		if expectedOperationAmount > c.CashAmount {
-			operation.Amount = c.CashAmount
+		if fee.Gross(expectedOperationAmount) > c.CashAmount {
+			operation.Amount = fee.Net(c.CashAmount)
+			operation.Fee = c.CashAmount - operation.Amount
 			c.CashReserved += c.CashAmount
 			c.CashAmount = 0
 		} else {
 			operation.Amount = expectedOperationAmount
-			c.CashReserved += expectedOperationAmount
-			c.CashAmount -= expectedOperationAmount
+			operation.Fee = fee.Charge(expectedOperationAmount)
+			c.CashReserved += operation.Amount + operation.Fee
+			c.CashAmount -= operation.Amount + operation.Fee
 		}
 
 		operation.Type = operation_type.Buy
-		operation.Quote = symbol.Bitcoin
+		operation.Quote = request.Symbol
 		operation.Base = symbol.Brl
 	case operation_type.Sell:
-		if coin.GetMinOperationValue(operation_type.Sell) > c.CryptoAmount || coin.GetMinOperationValue(operation_type.Sell) > c.CryptoAvailable {
-			return nil, exceptions.NewValidationError("Client does not have minimum crypto amount")
+		if !c.isSellOnReached(request.Analysis) {
+			return nil, c.abort(error_code.SellOnNotReached, "Analysis received does not reach client sell_on")
 		}
 
-		expectedOperationAmount := c.CryptoAvailable * c.OperationAmountPercentage / 100
+		crypto := c.GetCrypto(request.Symbol)
 
-		if expectedOperationAmount > c.CryptoAmount {
-			operation.Amount = c.CryptoAmount
-			c.CryptoReserved += c.CryptoAmount
-			c.CryptoAmount = 0
-		} else {
-			operation.Amount = expectedOperationAmount
-			c.CryptoReserved += expectedOperationAmount
-			c.CryptoAmount -= expectedOperationAmount
+		if coin.GetMinOperationValue(operation_type.Sell, request.Symbol, fee) > crypto.Amount || coin.GetMinOperationValue(operation_type.Sell, request.Symbol, fee) > crypto.Available {
+			return nil, c.abort(error_code.MinimumCryptoAmount, "Client does not have minimum crypto amount")
 		}
 
+		operation.Amount = math.Min(crypto.Available*c.OperationAmountPercentage/100, crypto.Amount)
+		operation.Fee = fee.Charge(operation.Amount * coin.SellValue)
+		crypto.Reserved += operation.Amount
+		crypto.Amount -= operation.Amount
+
 		operation.Type = operation_type.Sell
 		operation.Quote = symbol.Brl
-		operation.Base = symbol.Bitcoin
+		operation.Base = request.Symbol
 	}
 
 	return operation, nil
 }
 
+// ReleaseReservation reverses the balance reserved by CreateOperation for the operation, fee included, cash is
+// released for BUY operations and crypto for SELL operations.
+func (c *Client) ReleaseReservation(operation *Operation) {
+	switch operation.Type {
+	case operation_type.Buy:
+		c.CashReserved -= operation.Reserved()
+		c.CashAmount += operation.Reserved()
+	case operation_type.Sell:
+		crypto := c.GetCrypto(operation.Base)
+		crypto.Reserved -= operation.Reserved()
+		crypto.Amount += operation.Reserved()
+	}
+}
+
+// RollSummary keeps only the summaries of the current day and month, creating empty ones if the current period has
+// none. Periods are computed in the client location.
+func (c *Client) RollSummary() {
+	timeUtils := time_utils.TimeIn(c.Location())
+
+	var summaries []*Summary
+	hasDay, hasMonth := false, false
+	for _, summary := range c.Summary {
+		if summary.Type == summary_type.Day && timeUtils.IsToday(summary.Year, summary.Month, summary.Day) {
+			summaries = append(summaries, summary)
+			hasDay = true
+		}
+		if summary.Type == summary_type.Month && timeUtils.IsThisMonth(summary.Year, summary.Month) {
+			summaries = append(summaries, summary)
+			hasMonth = true
+		}
+	}
+
+	if !hasDay {
+		summaries = append(summaries, &Summary{
+			Type:  summary_type.Day,
+			Day:   timeUtils.Day(),
+			Month: timeUtils.Month(),
+			Year:  timeUtils.Year(),
+		})
+	}
+	if !hasMonth {
+		summaries = append(summaries, &Summary{
+			Type:  summary_type.Month,
+			Day:   1,
+			Month: timeUtils.Month(),
+			Year:  timeUtils.Year(),
+		})
+	}
+
+	c.Summary = summaries
+}
+
+// Location returns the client timezone location, falls back to the default client timezone if client has none.
+func (c *Client) Location() *time.Location {
+	if c.Timezone == "" {
+		return time_utils.Location(properties.Properties().DefaultClientTimezone)
+	}
+
+	return time_utils.Location(c.Timezone)
+}
+
+// IsLockedUntil returns true while client locked_until date is not reached.
+func (c *Client) IsLockedUntil() bool {
+	return time_utils.Time().Value().Before(c.LockedUntil)
+}
+
+// IsLocked returns true while client lock lease is not expired, locks without lock_expires_at never expire.
+func (c *Client) IsLocked() bool {
+	return c.Locked && (c.LockExpiresAt.IsZero() || time_utils.Time().Value().Before(c.LockExpiresAt))
+}
+
 // Lock client
 func (c *Client) Lock() {
 	c.Locked = true
 }
 
-// Unlock client
+// Unlock client, the lock lease is cleared.
 func (c *Client) Unlock() {
 	c.Locked = false
+	c.LockedBy = ""
+	c.LockExpiresAt = time.Time{}
+}
+
+// IsLockStale returns true if client lock lease is expired. Locks without lock_expires_at are stale when locked for
+// longer than the given lease, or when locked before locked_at was recorded.
+func (c *Client) IsLockStale(lease time.Duration, now time.Time) bool {
+	if !c.Locked {
+		return false
+	}
+
+	if !c.LockExpiresAt.IsZero() {
+		return !now.Before(c.LockExpiresAt)
+	}
+
+	return c.LockedAt.IsZero() || now.Sub(c.LockedAt) >= lease
+}
+
+// isBuyOnReached returns true if analysis reaches client buy_on. Requests without analysis and clients without buy_on
+// are not gated, as they predate the analysis check.
+func (c *Client) isBuyOnReached(analysis analysis_strength.AnalysisStrength) bool {
+	return analysis == "" || c.BuyOn == "" || analysis.IsBuyAtLeast(c.BuyOn)
+}
+
+// isSellOnReached returns true if analysis reaches client sell_on. Requests without analysis and clients without
+// sell_on are not gated, as they predate the analysis check.
+func (c *Client) isSellOnReached(analysis analysis_strength.AnalysisStrength) bool {
+	return analysis == "" || c.SellOn == "" || analysis.IsSellAtLeast(c.SellOn)
+}
+
+func (c *Client) hasSymbol(requestSymbol symbol.Symbol) bool {
+	for _, clientSymbol := range c.Symbols {
+		if clientSymbol == requestSymbol.Name() {
+			return true
+		}
+	}
+	return false
 }
 
-func (c *Client) abort(message string) custom_error.BaseErrorAdapter {
-	return exceptions.NewValidationError(message)
+func (c *Client) abort(code error_code.ErrorCode, message string) custom_error.BaseErrorAdapter {
+	return exceptions.NewValidationError(code, message)
 }
@@ -2,9 +2,14 @@ package model
 
 import (
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/operation_type"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
 	"math"
+	"time"
 )
 
 type Coin struct {
@@ -12,14 +17,70 @@ type Coin struct {
 	Quote     symbol.Symbol
 	BuyValue  float64
 	SellValue float64
+	Timestamp time.Time
 }
 
-func (c Coin) GetMinOperationValue(operationType operation_type.OperationType) float64 {
+// GetMinOperationValue returns the balance needed to operate the minimum order size of the crypto asset. BUY values are
+// in cash with the fee included, SELL values are in crypto and the fee is paid from the cash proceeds.
+func (c Coin) GetMinOperationValue(operationType operation_type.OperationType, crypto symbol.Symbol, fee *Fee) float64 {
 	switch operationType {
 	case operation_type.Buy:
-		return c.BuyValue * properties.Properties().MinimumCryptoBuyOperation
+		return fee.Gross(c.BuyValue * MinimumOrderSize(operationType, crypto))
 	case operation_type.Sell:
-		return properties.Properties().MinimumCryptoSellOperation
+		return MinimumOrderSize(operationType, crypto)
 	}
 	return math.MaxFloat64
 }
+
+// MinimumOrderSize returns the smallest amount of the crypto asset an operation can trade, configured per crypto on
+// MINIMUM_CRYPTO_BUY_OPERATION and MINIMUM_CRYPTO_SELL_OPERATION. Cryptos not configured use the symbol registry
+// default.
+func MinimumOrderSize(operationType operation_type.OperationType, crypto symbol.Symbol) float64 {
+	minimums := properties.Properties().MinimumCryptoBuyOperation
+	if operationType == operation_type.Sell {
+		minimums = properties.Properties().MinimumCryptoSellOperation
+	}
+
+	if minimum, ok := minimums[crypto.Name()]; ok {
+		return minimum
+	}
+	return crypto.MinimumOrderSize()
+}
+
+// Price returns the value the operation type is executed at, buy value (ask) for BUY and sell value (bid) for SELL.
+func (c Coin) Price(operationType operation_type.OperationType) float64 {
+	switch operationType {
+	case operation_type.Buy:
+		return c.BuyValue
+	case operation_type.Sell:
+		return c.SellValue
+	}
+	return 0
+}
+
+// Validate checks if the quote can be used to size an operation. Returns error_code.InvalidQuote if buy or sell values
+// are not positive or buy value (ask) is lower than sell value (bid), and error_code.StaleQuote if the quote timestamp
+// is missing or older than QUOTE_MAX_AGE_SECONDS.
+func (c Coin) Validate() custom_error.BaseErrorAdapter {
+	if c.BuyValue <= 0 || c.SellValue <= 0 {
+		return c.abort(error_code.InvalidQuote, "Quote buy and sell values must be positive")
+	}
+
+	if c.BuyValue < c.SellValue {
+		return c.abort(error_code.InvalidQuote, "Quote buy value is lower than sell value")
+	}
+
+	if c.Timestamp.IsZero() {
+		return c.abort(error_code.StaleQuote, "Quote timestamp is missing")
+	}
+
+	if time_utils.Time().Value().Sub(c.Timestamp) > properties.Properties().QuoteMaxAge {
+		return c.abort(error_code.StaleQuote, "Quote is older than max age")
+	}
+
+	return nil
+}
+
+func (c Coin) abort(code error_code.ErrorCode, message string) custom_error.BaseErrorAdapter {
+	return exceptions.NewValidationError(code, message)
+}
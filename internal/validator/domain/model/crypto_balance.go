@@ -0,0 +1,7 @@
+package model
+
+type CryptoBalance struct {
+	Available float64
+	Amount    float64
+	Reserved  float64
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/exchange"
+)
+
+// Fee is the fee schedule of an exchange, maker and taker fees are percentages of the operation amount.
+type Fee struct {
+	Maker float64
+	Taker float64
+}
+
+// ExchangeFee returns the fee schedule of the exchange configured on EXCHANGE_MAKER_FEES and EXCHANGE_TAKER_FEES,
+// exchanges not configured have no fees.
+func ExchangeFee(exchange exchange.Exchange) *Fee {
+	return &Fee{
+		Maker: properties.Properties().ExchangeFees.Maker[exchange.Name()],
+		Taker: properties.Properties().ExchangeFees.Taker[exchange.Name()],
+	}
+}
+
+// Charge returns the fee charged over the operation amount. Operations are executed as market orders, so the taker
+// fee applies.
+func (f *Fee) Charge(amount float64) float64 {
+	return amount * f.Taker / 100
+}
+
+// Gross returns the amount plus the fee charged over it.
+func (f *Fee) Gross(amount float64) float64 {
+	return amount + f.Charge(amount)
+}
+
+// Net returns the largest operation amount that can be paid, fee included, with the balance.
+func (f *Fee) Net(balance float64) float64 {
+	return balance / (1 + f.Taker/100)
+}
@@ -4,28 +4,44 @@ import (
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/operation_type"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/status"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
-	"github.com/google/uuid"
 	"time"
 )
 
 type Operation struct {
-	Id        string
-	Status    status.Status
-	CreatedAt time.Time
-	Locked    bool
-	Type      operation_type.OperationType
-	Amount    float64
-	Base      symbol.Symbol
-	Quote     symbol.Symbol
-	StopLoss  float64
+	Id          string
+	Status      status.Status
+	CreatedAt   time.Time
+	Locked      bool
+	Type        operation_type.OperationType
+	Amount      float64
+	Fee         float64
+	Base        symbol.Symbol
+	Quote       symbol.Symbol
+	StopLoss    float64
+	SignalPrice float64
+	Simulation  bool
 }
 
-func NewOperation(stopLoss float64) *Operation {
+func NewOperation(id string, stopLoss float64) *Operation {
 	return &Operation{
-		Id:        uuid.NewString(),
+		Id:        id,
 		Status:    status.Created,
 		CreatedAt: time.Now(),
 		Locked:    false,
 		StopLoss:  stopLoss,
 	}
 }
+
+// Reserved returns the balance reserved for the operation. Fees are charged in BRL, so BUY operations reserve the amount
+// plus expected fee and SELL operations reserve only the crypto amount, their fee is paid from the proceeds.
+func (o *Operation) Reserved() float64 {
+	if o.Type == operation_type.Sell {
+		return o.Amount
+	}
+	return o.Amount + o.Fee
+}
+
+// Cancel operation, used when the operation event could not be published and the reservation is released
+func (o *Operation) Cancel() {
+	o.Status = status.Cancelled
+}
@@ -1,14 +1,62 @@
 package model
 
 import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/analysis_strength"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/operation_type"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
+	"github.com/google/uuid"
+	"math"
+	"strings"
 	"time"
 )
 
+// operationNamespace is the uuid namespace used to derive operation ids from operation requests.
+var operationNamespace = uuid.MustParse("5b0f3e52-8c4a-4d8e-9d8f-6a1c2b3e4f50")
+
 type OperationRequest struct {
-	ClientId  string
-	Operation operation_type.OperationType
-	Symbol    symbol.Symbol
-	StartTime time.Time
+	ClientId    string
+	Operation   operation_type.OperationType
+	Symbol      symbol.Symbol
+	Analysis    analysis_strength.AnalysisStrength
+	StartTime   time.Time
+	SignalPrice float64
+	MessageId   string
+}
+
+// IsStale returns true if the request start_time is older than REQUEST_MAX_AGE_SECONDS. Requests without start_time
+// cannot be aged and are never stale.
+func (o *OperationRequest) IsStale() bool {
+	return !o.StartTime.IsZero() && time_utils.Time().Value().Sub(o.StartTime) > properties.Properties().RequestMaxAge
+}
+
+// Slippage returns how much the coin price moved from the signal price, as a percentage of the signal price. BUY
+// requests are compared to the quote buy value (ask) and SELL requests to the sell value (bid). Returns 0 if the
+// request carries no signal price.
+func (o *OperationRequest) Slippage(coin *Coin) float64 {
+	if o.SignalPrice <= 0 {
+		return 0
+	}
+
+	return math.Abs(coin.Price(o.Operation)-o.SignalPrice) / o.SignalPrice * 100
+}
+
+// OperationId derives the id of the operation created from this request using client_id, operation, symbol and
+// start_time as idempotency key. A redelivered or republished request always maps to the same operation id. Requests
+// without start_time use the message id instead, so they are not taken as replays of each other.
+func (o *OperationRequest) OperationId() string {
+	requestKey := o.StartTime.UTC().Format(time.RFC3339Nano)
+	if o.StartTime.IsZero() {
+		requestKey = o.MessageId
+	}
+
+	key := strings.Join([]string{
+		o.ClientId,
+		string(o.Operation),
+		o.Symbol.Name(),
+		requestKey,
+	}, "|")
+
+	return uuid.NewSHA1(operationNamespace, []byte(key)).String()
 }
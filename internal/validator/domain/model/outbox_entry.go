@@ -0,0 +1,47 @@
+package model
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/lock_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"time"
+)
+
+type OutboxEntry struct {
+	Id        string
+	Type      outbox_type.OutboxType
+	Status    outbox_status.OutboxStatus
+	ClientId  string
+	Operation *Operation
+	Locks     []lock_type.LockType
+	Reason    string
+	CreatedAt time.Time
+}
+
+// NewOutboxEntry creates a pending outbox entry for the operation. The id is derived from the entry type and the
+// operation id, so recording the same operation twice keeps a single entry.
+func NewOutboxEntry(outboxType outbox_type.OutboxType, clientId string, operation *Operation, reason string) *OutboxEntry {
+	return &OutboxEntry{
+		Id:        string(outboxType) + "#" + operation.Id,
+		Type:      outboxType,
+		Status:    outbox_status.Pending,
+		ClientId:  clientId,
+		Operation: operation,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewUnlockOutboxEntry creates a pending outbox entry for the client locks that could not be released. The id is
+// derived from the client id, so a client keeps a single entry with the locks of its last failure.
+func NewUnlockOutboxEntry(clientId string, locks []lock_type.LockType, reason string) *OutboxEntry {
+	return &OutboxEntry{
+		Id:        string(outbox_type.Unlock) + "#" + clientId,
+		Type:      outbox_type.Unlock,
+		Status:    outbox_status.Pending,
+		ClientId:  clientId,
+		Locks:     locks,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,9 @@
+package model
+
+// ReaperReport lists the clients handled by a reaper execution and the operations whose compensation was repaired.
+type ReaperReport struct {
+	Released    []string
+	Held        []string
+	Failed      []string
+	Compensated []string
+}
@@ -0,0 +1,8 @@
+package model
+
+// RelayReport lists the outbox entries handled by a relay execution.
+type RelayReport struct {
+	Sent      []string
+	Discarded []string
+	Failed    []string
+}
@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/exceptions"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"time"
+)
+
+type reaperUseCase struct {
+	clientDB      adapters.ClientPersistenceAdapter
+	lockDB        adapters.LockPersistenceAdapter
+	outboxDB      adapters.OutboxPersistenceAdapter
+	operationDB   adapters.OperationPersistenceAdapter
+	paperExchange adapters.PaperExchangeAdapter
+	timeSource    adapters.TimeAdapter
+	lease         time.Duration
+	logger        adapters.LoggerAdapter
+}
+
+// ReaperUseCase constructor for class.
+func ReaperUseCase(
+	clientDB adapters.ClientPersistenceAdapter,
+	lockDB adapters.LockPersistenceAdapter,
+	outboxDB adapters.OutboxPersistenceAdapter,
+	operationDB adapters.OperationPersistenceAdapter,
+	paperExchange adapters.PaperExchangeAdapter,
+	timeSource adapters.TimeAdapter,
+	logger adapters.LoggerAdapter,
+) *reaperUseCase {
+	return &reaperUseCase{
+		clientDB:      clientDB,
+		lockDB:        lockDB,
+		outboxDB:      outboxDB,
+		operationDB:   operationDB,
+		paperExchange: paperExchange,
+		timeSource:    timeSource,
+		lease:         properties.Properties().ReaperLockLease,
+		logger:        logger,
+	}
+}
+
+// Reap releases clients whose lock lease expired, or that are locked for longer than REAPER_LOCK_LEASE_SECONDS when
+// locked without a lease, and whose client_id key is no longer set on cache, a key still set means a validation may be
+// running and the client is kept locked. Clients recorded on the outbox with locks still held are released without
+// waiting for the lease. The release is conditioned on the client version, so a client updated after it was read is
+// not released. Compensations recorded on the outbox are repaired after the locks are released, the operation is
+// cancelled and the client reservation released while the client is locked.
+func (r *reaperUseCase) Reap(ctx context.Context) (*model.ReaperReport, error) {
+	r.logger.Info("Reap start")
+
+	clients, err := r.clientDB.GetLockedClients(ctx)
+	if err != nil {
+		return nil, r.abort(err, "Error while trying to get locked clients")
+	}
+
+	entries, err := r.outboxDB.GetPending(ctx, outbox_type.Unlock)
+	if err != nil {
+		return nil, r.abort(err, "Error while trying to get pending outbox unlocks")
+	}
+
+	compensations, err := r.outboxDB.GetPending(ctx, outbox_type.Compensation)
+	if err != nil {
+		return nil, r.abort(err, "Error while trying to get pending outbox compensations")
+	}
+
+	recorded := map[string]*model.OutboxEntry{}
+	for _, entry := range entries {
+		recorded[entry.ClientId] = entry
+	}
+
+	report := &model.ReaperReport{
+		Released:    []string{},
+		Held:        []string{},
+		Failed:      []string{},
+		Compensated: []string{},
+	}
+	now := r.timeSource.Now()
+	for _, client := range clients {
+		entry := recorded[client.Id]
+		delete(recorded, client.Id)
+
+		if entry == nil && !client.IsLockStale(r.lease, now) {
+			continue
+		}
+
+		released, err := r.release(ctx, client)
+		if err != nil {
+			r.logger.Error(err, "Could not release client", client.Id)
+			report.Failed = append(report.Failed, client.Id)
+			continue
+		}
+
+		if !released {
+			report.Held = append(report.Held, client.Id)
+			continue
+		}
+
+		report.Released = append(report.Released, client.Id)
+		if entry != nil {
+			r.resolve(ctx, entry)
+		}
+	}
+
+	for _, entry := range recorded {
+		r.logger.Info("Client is not locked anymore, resolving outbox entry", entry)
+		r.resolve(ctx, entry)
+	}
+
+	for _, compensation := range compensations {
+		repaired, err := r.compensate(ctx, compensation)
+		if err != nil {
+			r.logger.Error(err, "Could not repair operation compensation", compensation)
+			report.Failed = append(report.Failed, compensation.ClientId)
+			continue
+		}
+
+		if !repaired {
+			report.Held = append(report.Held, compensation.ClientId)
+			continue
+		}
+
+		report.Compensated = append(report.Compensated, compensation.Operation.Id)
+	}
+
+	r.logger.Info("Reap finish", report)
+	return report, nil
+}
+
+func (r *reaperUseCase) release(ctx context.Context, client *model.Client) (bool, custom_error.BaseErrorAdapter) {
+	held, err := r.lockDB.Exists(ctx, client.Id)
+	if err != nil {
+		return false, err
+	}
+
+	if held {
+		r.logger.Info("Client_id is still locked on cache, keeping client locked", client.Id)
+		return false, nil
+	}
+
+	err = r.clientDB.ReleaseLock(ctx, client)
+	if err != nil {
+		return false, err
+	}
+
+	r.logger.Info("Stale client lock released", client.Id)
+	return true, nil
+}
+
+// compensate applies the compensation recorded on the outbox. The client_id is locked on cache and the client on DB
+// while the reservation is released, clients whose client_id is still locked are kept for the next execution.
+// Operations that are no longer CREATED were already compensated or executed, their entry is only resolved.
+func (r *reaperUseCase) compensate(ctx context.Context, entry *model.OutboxEntry) (bool, custom_error.BaseErrorAdapter) {
+	held, err := r.lockDB.Exists(ctx, entry.ClientId)
+	if err != nil {
+		return false, err
+	}
+
+	if held {
+		r.logger.Info("Client_id is still locked on cache, keeping compensation pending", entry)
+		return false, nil
+	}
+
+	err = r.lockDB.Lock(ctx, entry.ClientId)
+	if err != nil {
+		return false, err
+	}
+	defer r.unlock(ctx, entry.ClientId)
+
+	operation, err := r.operationDB.Get(ctx, entry.Operation.Id)
+	if err != nil {
+		return false, err
+	}
+
+	client, err := r.clientDB.GetClient(ctx, entry.ClientId)
+	if err != nil {
+		return false, err
+	}
+
+	if client == nil || operation == nil || operation.Status != status.Created {
+		r.logger.Info("Operation is not waiting for its compensation, resolving outbox entry", entry, operation)
+		r.resolve(ctx, entry)
+		return true, nil
+	}
+
+	err = r.clientDB.Lock(ctx, client)
+	if err != nil {
+		return false, err
+	}
+
+	client.ReleaseReservation(operation)
+	operation.Cancel()
+
+	err = r.clientDB.ReleaseReservation(ctx, client, operation)
+	if releaseErr := r.clientDB.ReleaseLock(ctx, client); releaseErr != nil {
+		r.logger.Warning(releaseErr, "Could not release client lock, it will be reaped when its lease expires", client.Id)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if operation.Simulation {
+		err = r.paperExchange.Release(ctx, client.Id, operation)
+		if err != nil {
+			r.logger.Error(err, "Could not release operation on paper exchange", client.Id, operation)
+		}
+	}
+
+	r.logger.Info("Operation compensation repaired", client.Id, operation)
+	r.resolve(ctx, entry)
+	return true, nil
+}
+
+func (r *reaperUseCase) unlock(ctx context.Context, clientId string) {
+	err := r.lockDB.Unlock(ctx, clientId)
+	if err != nil {
+		r.logger.Warning(err, "Could not unlock client_id", clientId)
+	}
+}
+
+func (r *reaperUseCase) resolve(ctx context.Context, entry *model.OutboxEntry) {
+	err := r.outboxDB.UpdateStatus(ctx, entry, outbox_status.Resolved)
+	if err != nil {
+		r.logger.Warning(err, "Could not mark outbox entry as resolved", entry)
+	}
+}
+
+func (r *reaperUseCase) abort(err custom_error.BaseErrorAdapter, message string) error {
+	reaperError := exceptions.ReaperError(err, message)
+	r.logger.Error(reaperError, "Reap failed: "+message)
+	return reaperError
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/exceptions"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"time"
+)
+
+type relayUseCase struct {
+	outboxDB     adapters.OutboxPersistenceAdapter
+	operationDB  adapters.OperationPersistenceAdapter
+	eventService adapters.EventServiceAdapter
+	timeSource   adapters.TimeAdapter
+	gracePeriod  time.Duration
+	logger       adapters.LoggerAdapter
+}
+
+// RelayUseCase constructor for class.
+func RelayUseCase(
+	outboxDB adapters.OutboxPersistenceAdapter,
+	operationDB adapters.OperationPersistenceAdapter,
+	eventService adapters.EventServiceAdapter,
+	timeSource adapters.TimeAdapter,
+	logger adapters.LoggerAdapter,
+) *relayUseCase {
+	return &relayUseCase{
+		outboxDB:     outboxDB,
+		operationDB:  operationDB,
+		eventService: eventService,
+		timeSource:   timeSource,
+		gracePeriod:  properties.Properties().RelayGracePeriod,
+		logger:       logger,
+	}
+}
+
+// Relay publishes the operation events still pending on the outbox and marks them as sent. The operation is read again
+// before publishing, events of operations that no longer exist, are not CREATED or are waiting for a compensation to be
+// repaired are discarded. Entries that fail are kept pending and retried on the next execution. Entries created less
+// than RELAY_GRACE_PERIOD_SECONDS ago are skipped, the validation that created them may still be running and publish or
+// compensate the operation itself.
+func (r *relayUseCase) Relay(ctx context.Context) (*model.RelayReport, error) {
+	r.logger.Info("Relay start")
+
+	entries, err := r.outboxDB.GetPending(ctx, outbox_type.Event)
+	if err != nil {
+		return nil, r.abort(err, "Error while trying to get pending outbox entries")
+	}
+
+	compensations, err := r.outboxDB.GetPending(ctx, outbox_type.Compensation)
+	if err != nil {
+		return nil, r.abort(err, "Error while trying to get pending outbox compensations")
+	}
+
+	compensated := map[string]bool{}
+	for _, compensation := range compensations {
+		compensated[compensation.Operation.Id] = true
+	}
+
+	report := &model.RelayReport{
+		Sent:      []string{},
+		Discarded: []string{},
+		Failed:    []string{},
+	}
+	now := r.timeSource.Now()
+	for _, entry := range entries {
+		if now.Sub(entry.CreatedAt) < r.gracePeriod {
+			r.logger.Info("Outbox entry is inside grace period, skipping", entry)
+			continue
+		}
+
+		entryStatus, err := r.relay(ctx, entry, compensated[entry.Operation.Id])
+		if err != nil {
+			r.logger.Error(err, "Could not relay outbox entry", entry)
+			report.Failed = append(report.Failed, entry.Id)
+			continue
+		}
+
+		if entryStatus == outbox_status.Sent {
+			report.Sent = append(report.Sent, entry.Id)
+		} else {
+			report.Discarded = append(report.Discarded, entry.Id)
+		}
+	}
+
+	r.logger.Info("Relay finish", report)
+	return report, nil
+}
+
+func (r *relayUseCase) relay(ctx context.Context, entry *model.OutboxEntry, compensated bool) (outbox_status.OutboxStatus, custom_error.BaseErrorAdapter) {
+	operation, err := r.operationDB.Get(ctx, entry.Operation.Id)
+	if err != nil {
+		return "", err
+	}
+
+	if compensated || operation == nil || operation.Status != status.Created {
+		r.logger.Info("Operation is not waiting for its event, discarding outbox entry", entry, operation)
+		return outbox_status.Discarded, r.outboxDB.UpdateStatus(ctx, entry, outbox_status.Discarded)
+	}
+
+	err = r.eventService.Send(ctx, operation)
+	if err != nil {
+		return "", err
+	}
+
+	return outbox_status.Sent, r.outboxDB.UpdateStatus(ctx, entry, outbox_status.Sent)
+}
+
+func (r *relayUseCase) abort(err custom_error.BaseErrorAdapter, message string) error {
+	relayError := exceptions.RelayError(err, message)
+	r.logger.Error(relayError, "Relay failed: "+message)
+	return relayError
+}
@@ -1,7 +1,14 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/lock_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/status"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/exceptions"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
@@ -11,10 +18,11 @@ import (
 type validationUseCase struct {
 	lockDB        adapters.LockPersistenceAdapter
 	clientDB      adapters.ClientPersistenceAdapter
-	clientService adapters.ClientServiceAdapter
-	cryptoService adapters.CryptoServiceAdapter
+	exchanges     adapters.ExchangeRegistryAdapter
+	paperExchange adapters.PaperExchangeAdapter
 	operationDB   adapters.OperationPersistenceAdapter
 	eventService  adapters.EventServiceAdapter
+	outboxDB      adapters.OutboxPersistenceAdapter
 	logger        adapters.LoggerAdapter
 }
 
@@ -22,102 +30,325 @@ type validationUseCase struct {
 func ValidationUseCase(
 	lockDB adapters.LockPersistenceAdapter,
 	clientDB adapters.ClientPersistenceAdapter,
-	clientService adapters.ClientServiceAdapter,
-	cryptoService adapters.CryptoServiceAdapter,
+	exchanges adapters.ExchangeRegistryAdapter,
+	paperExchange adapters.PaperExchangeAdapter,
 	operationDB adapters.OperationPersistenceAdapter,
 	eventService adapters.EventServiceAdapter,
+	outboxDB adapters.OutboxPersistenceAdapter,
 	logger adapters.LoggerAdapter,
 ) *validationUseCase {
 	return &validationUseCase{
 		lockDB:        lockDB,
 		clientDB:      clientDB,
-		clientService: clientService,
-		cryptoService: cryptoService,
+		exchanges:     exchanges,
+		paperExchange: paperExchange,
 		operationDB:   operationDB,
 		eventService:  eventService,
+		outboxDB:      outboxDB,
 		logger:        logger,
 	}
 }
 
 // Validate if operation can be executed. client_id key will be locked in cache and locked flag will be set to true on
 // client DB during execution of method. After the operation request is validated with client config, an operation is
-// created and sent to execution via SNS topic.
-func (v *validationUseCase) Validate(operationRequest *model.OperationRequest) error {
+// created together with its outbox event and sent to execution via SNS topic. Events left pending are published by
+// the relay. The validation is aborted when ctx is cancelled or reaches its deadline, locks are still released. Balance
+// and quote are requested to the client exchange, if its circuit breaker is open the validation fails before the
+// client_id is locked. The circuit state is only checked, the half-open trial is taken by the exchange request.
+// Simulation clients operate on the paper exchange, their operations are reserved on the paper exchange ledger
+// before the event is sent and compensated if the ledger reservation fails. Simulation clients quoting from the
+// recorded price feed make no exchange request, so their exchange circuit is not checked.
+func (v *validationUseCase) Validate(ctx context.Context, operationRequest *model.OperationRequest) error {
 	v.logger.Info("Validate start", operationRequest)
 
-	err := v.lockDB.Lock(operationRequest.ClientId)
+	if checkErr := v.checkExchange(ctx, operationRequest.ClientId); checkErr != nil {
+		return checkErr
+	}
+
+	err := v.lockDB.Lock(ctx, operationRequest.ClientId)
 	if err != nil {
 		return v.abort(err, "Error while trying to lock client_id", operationRequest.ClientId, nil)
 	}
 
-	client, err := v.clientDB.GetClient(operationRequest.ClientId)
+	v.lockDB.Watchdog(ctx, operationRequest.ClientId)
+
+	client, err := v.clientDB.GetClient(ctx, operationRequest.ClientId)
 	if err != nil {
 		return v.abort(err, "Error while trying get client from DB", operationRequest.ClientId, nil)
 	}
 
-	err = v.clientDB.Lock(client)
+	exchangeService, _, err := v.exchanges.Get(client.Exchange)
 	if err != nil {
-		return v.abort(err, "Error while trying to lock client DB", client.Id, client)
+		return v.abort(err, "Error while trying to get client exchange", client.Id, nil)
+	}
+
+	if client.Simulation {
+		exchangeService = v.paperExchange.Exchange(exchangeService)
 	}
 
-	balance, err := v.clientService.GetBalance(client.Id, false)
+	client.RollSummary()
+
+	err = v.clientDB.Lock(ctx, client)
 	if err != nil {
 		return v.abort(err, "Error while trying to lock client DB", client.Id, client)
 	}
 
+	operation, err := v.operationDB.Get(ctx, operationRequest.OperationId())
+	if err != nil {
+		return v.abort(err, "Error while trying to get operation from DB", client.Id, client)
+	}
+
+	if operation != nil && operation.Status == status.Created {
+		err = v.repairCompensation(ctx, client, operation)
+		if err != nil {
+			return v.abort(err, "Error while trying to repair operation compensation", client.Id, client)
+		}
+	}
+
+	if operation != nil && operation.Status != status.Cancelled {
+		v.logger.Info("Operation already created for request, skipping creation", operationRequest, operation)
+	} else {
+		if operation != nil {
+			v.logger.Info("Operation for request was cancelled, creating it again", operationRequest, operation)
+		}
+
+		var createErr error
+		operation, createErr = v.createOperation(ctx, operationRequest, client, exchangeService)
+		if createErr != nil {
+			return createErr
+		}
+	}
+
+	if operation.Status == status.Created {
+		if operation.Simulation {
+			err = v.paperExchange.Reserve(ctx, client.Id, operation)
+			if err != nil {
+				v.compensate(client, operation, err)
+				return v.abort(err, "Error while trying to reserve operation on paper exchange", client.Id, client)
+			}
+		}
+
+		err = v.eventService.Send(ctx, operation)
+		if err != nil {
+			v.compensate(client, operation, err)
+			return v.abort(err, "Error while trying to send operation event", client.Id, client)
+		}
+
+		v.markSent(ctx, client, operation)
+	}
+
+	err = v.clientDB.Unlock(ctx, client)
+	if err != nil {
+		return v.abort(err, "Error while trying to unlock client DB", client.Id, client)
+	}
+
+	err = v.lockDB.Unlock(ctx, client.Id)
+	if err != nil {
+		return v.abort(err, "Error while trying to unlock client_id", client.Id, client)
+	}
+
+	v.logger.Info("Validate finish", operationRequest, client, operation)
+	return nil
+}
+
+// checkExchange reads the client without locking it and checks the circuit breaker of its exchange, so validations of
+// an exchange with an open circuit fail before the client_id is locked. Simulation clients quoting from the recorded
+// price feed skip the check. No lock is held, errors are returned without releasing any.
+func (v *validationUseCase) checkExchange(ctx context.Context, clientId string) error {
+	client, err := v.clientDB.GetClient(ctx, clientId)
+	if err != nil {
+		err.SetLocks(false, false)
+		return v.abort(err, "Error while trying get client from DB", clientId, nil)
+	}
+
+	_, breaker, err := v.exchanges.Get(client.Exchange)
+	if err != nil {
+		err.SetLocks(false, false)
+		return v.abort(err, "Error while trying to get client exchange", clientId, nil)
+	}
+
+	if client.Simulation && v.paperExchange.RecordedPriceFeed() {
+		return nil
+	}
+
+	err = breaker.Check(ctx)
+	if err != nil {
+		err.SetLocks(false, false)
+		return v.abort(err, "Exchange circuit breaker is open", clientId, nil)
+	}
+
+	return nil
+}
+
+// createOperation reserves the client balance for a new operation, the reservation, the operation and its outbox event
+// are saved in the same transaction. The operation id is derived from the request so replays of the same request find it instead of
+// reserving the balance again. Operations cancelled by a compensation are created again, replacing the cancelled
+// operation and its outbox event, so the request is not lost when its event could not be sent. Balance and quote are
+// requested to the client exchange service.
+func (v *validationUseCase) createOperation(ctx context.Context, operationRequest *model.OperationRequest, client *model.Client, exchangeService adapters.CryptoServiceAdapter) (*model.Operation, error) {
+	balance, err := exchangeService.GetBalance(ctx, client.Id)
+	if err != nil {
+		return nil, v.abort(err, "Error while trying to get client balance from crypto service", client.Id, client)
+	}
+
 	client.SetBalance(balance)
 
-	coin, err := v.cryptoService.GetCrypto(operationRequest.Symbol, symbol.Brl)
+	coin, err := exchangeService.GetCrypto(ctx, operationRequest.Symbol, symbol.Brl)
 	if err != nil {
-		return v.abort(err, "Error while trying to get coin from crypto service", client.Id, client)
+		return nil, v.abort(err, "Error while trying to get coin from crypto service", client.Id, client)
 	}
 
 	operation, err := client.CreateOperation(operationRequest, coin)
 	if err != nil {
-		return v.abort(err, "Error while trying to create operation", client.Id, client)
+		return nil, v.abort(err, "Error while trying to create operation", client.Id, client)
+	}
+
+	event := model.NewOutboxEntry(outbox_type.Event, client.Id, operation, "")
+
+	err = v.clientDB.SaveReservation(ctx, client, operation, event)
+	if err != nil {
+		return nil, v.abort(err, "Error while trying to save client reservation and operation", client.Id, client)
 	}
 
-	err = v.operationDB.Save(operation)
+	return operation, nil
+}
+
+// compensate cancels the operation and releases the client reservation when the operation event could not be sent. If
+// the compensation cannot be persisted the operation is recorded on the outbox for later repair, by a replay of the
+// request or by the reaper. The compensation runs on a release context, so it is persisted even if the event failed
+// because the validation was cancelled.
+func (v *validationUseCase) compensate(client *model.Client, operation *model.Operation, cause custom_error.BaseErrorAdapter) {
+	v.logger.Info("Compensation start", client.Id, operation)
+
+	ctx, cancel := releaseContext()
+	defer cancel()
+
+	err := v.releaseReservation(ctx, client, operation)
 	if err != nil {
-		return v.abort(err, "Error while trying to save operation", client.Id, client)
+		v.logger.Error(err, "Compensation failed, recording operation on outbox", client.Id, operation)
+
+		err = v.outboxDB.Save(ctx, model.NewOutboxEntry(outbox_type.Compensation, client.Id, operation, cause.Error()))
+		if err != nil {
+			v.logger.Error(err, "Could not record operation on outbox", client.Id, operation)
+		}
+		return
 	}
 
-	err = v.eventService.Send(operation)
+	v.logger.Info("Compensation finish", client.Id, operation)
+}
+
+// repairCompensation applies the compensation recorded on the outbox for the operation if it is still pending, so a
+// replayed request does not send the event of an operation whose compensation could not be persisted. The operation
+// is cancelled and the replay creates it again.
+func (v *validationUseCase) repairCompensation(ctx context.Context, client *model.Client, operation *model.Operation) custom_error.BaseErrorAdapter {
+	compensation, err := v.outboxDB.Get(ctx, model.NewOutboxEntry(outbox_type.Compensation, client.Id, operation, "").Id)
 	if err != nil {
-		return v.abort(err, "Error while trying to send operation event", client.Id, client)
+		return err
+	}
+
+	if compensation == nil || compensation.Status != outbox_status.Pending {
+		return nil
 	}
 
-	err = v.clientDB.Unlock(client)
+	v.logger.Info("Operation has a pending compensation, repairing it", client.Id, operation)
+
+	err = v.releaseReservation(ctx, client, operation)
 	if err != nil {
-		return v.abort(err, "Error while trying to unlock client DB", client.Id, client)
+		return err
 	}
 
-	err = v.lockDB.Unlock(client.Id)
+	err = v.outboxDB.UpdateStatus(ctx, compensation, outbox_status.Resolved)
 	if err != nil {
-		return v.abort(err, "Error while trying to unlock client_id", client.Id, client)
+		v.logger.Warning(err, "Could not mark compensation as resolved", client.Id, operation)
+	}
+
+	return nil
+}
+
+// releaseReservation cancels the operation and releases the client reservation in a single transaction. Simulated
+// operations are also released on the paper exchange ledger.
+func (v *validationUseCase) releaseReservation(ctx context.Context, client *model.Client, operation *model.Operation) custom_error.BaseErrorAdapter {
+	client.ReleaseReservation(operation)
+	operation.Cancel()
+
+	err := v.clientDB.ReleaseReservation(ctx, client, operation)
+	if err != nil {
+		return err
+	}
+
+	if operation.Simulation {
+		err = v.paperExchange.Release(ctx, client.Id, operation)
+		if err != nil {
+			v.logger.Error(err, "Could not release operation on paper exchange", client.Id, operation)
+		}
 	}
 
-	v.logger.Info("Validate finish", operationRequest, client, operation)
 	return nil
 }
 
+// markSent marks the operation outbox event as sent. A failure is only logged, the relay will publish the event again
+// and consumers must handle duplicated operations.
+func (v *validationUseCase) markSent(ctx context.Context, client *model.Client, operation *model.Operation) {
+	err := v.outboxDB.UpdateStatus(ctx, model.NewOutboxEntry(outbox_type.Event, client.Id, operation, ""), outbox_status.Sent)
+	if err != nil {
+		v.logger.Warning(err, "Could not mark operation event as sent", client.Id, operation)
+	}
+}
+
+// abort releases the locks held by the error and returns the validation error. Every lock is released even if a
+// previous unlock failed, locks that could not be released are recorded on the outbox and returned as an
+// exceptions.UnlockError joined with the validation error. Locks are released on a release context, so they are
+// released even if the validation was cancelled.
 func (v *validationUseCase) abort(err custom_error.BaseErrorAdapter, message, clientId string, client *model.Client) error {
 	validationError := exceptions.ValidationError(err, message)
 	v.logger.Error(validationError, "Validate failed: "+message)
 
+	ctx, cancel := releaseContext()
+	defer cancel()
+
+	var locks []lock_type.LockType
+	var unlockErrors []error
+
 	if err.LockedClient() && client != nil {
-		ex := v.clientDB.Unlock(client)
+		if client.IsLockedUntil() {
+			ex := v.clientDB.LockUntil(ctx, client)
+			if ex != nil {
+				v.logger.Warning(ex, "Could not persist client locked_until")
+			}
+		}
+
+		ex := v.clientDB.Unlock(ctx, client)
 		if ex != nil {
-			panic(ex)
+			locks = append(locks, lock_type.Client)
+			unlockErrors = append(unlockErrors, ex)
 		}
 	}
 
 	if err.LockedClientId() {
-		ex := v.lockDB.Unlock(clientId)
+		ex := v.lockDB.Unlock(ctx, clientId)
 		if ex != nil {
-			panic(ex)
+			locks = append(locks, lock_type.ClientId)
+			unlockErrors = append(unlockErrors, ex)
 		}
 	}
 
-	return validationError
+	if len(locks) == 0 {
+		return validationError
+	}
+
+	unlockError := exceptions.NewUnlockError(clientId, locks, errors.Join(unlockErrors...))
+	v.logger.Error(unlockError, "Could not release locks, recording client on outbox", clientId, locks)
+
+	ex := v.outboxDB.Save(ctx, model.NewUnlockOutboxEntry(clientId, locks, unlockError.Error()))
+	if ex != nil {
+		v.logger.Error(ex, "Could not record client locks on outbox", clientId, locks)
+	}
+
+	return errors.Join(validationError, unlockError)
+}
+
+// releaseContext returns a context detached from the validation context, limited by LOCK_RELEASE_TIMEOUT_SECONDS. Used
+// to release locks and compensate operations after the validation context was cancelled or reached its deadline.
+func releaseContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), properties.Properties().LockReleaseTimeout)
 }
@@ -15,4 +15,10 @@ type DynamoDBAdapter interface {
 
 	// PutItem is the same as dynamodb.Client PutItem method
 	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+
+	// UpdateItem is the same as dynamodb.Client UpdateItem method
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+
+	// TransactWriteItems is the same as dynamodb.Client TransactWriteItems method
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
 }
@@ -6,4 +6,5 @@ type EncryptionServiceAdapter interface {
 	AESDecrypt(hexEncryptedString string, secret string) (string, custom_error.BaseErrorAdapter)
 	AESEncrypt(decryptedString string, secret string) (string, custom_error.BaseErrorAdapter)
 	SHA384Encrypt(string string, secret string) string
+	SHA256Encrypt(string string, secret string) string
 }
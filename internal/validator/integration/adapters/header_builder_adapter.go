@@ -1,10 +1,12 @@
 package adapters
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 	"net/http"
 )
 
 type HeaderBuilderAdapter interface {
-	BiscointHeader(clientId string, endpoint string, payload any) (http.Header, custom_error.BaseErrorAdapter)
+	BiscointHeader(ctx context.Context, clientId string, endpoint string, payload any) (http.Header, custom_error.BaseErrorAdapter)
+	BinanceHeader(ctx context.Context, clientId string, query string) (http.Header, string, custom_error.BaseErrorAdapter)
 }
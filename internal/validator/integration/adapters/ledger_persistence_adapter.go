@@ -0,0 +1,15 @@
+package adapters
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type LedgerPersistenceAdapter interface {
+	// Get returns the paper exchange ledger of the client, nil if the client has none.
+	Get(ctx context.Context, clientId string) (*dto.Ledger, custom_error.BaseErrorAdapter)
+
+	// Save creates the ledger or updates it if it was not modified since it was read.
+	Save(ctx context.Context, ledger *dto.Ledger) custom_error.BaseErrorAdapter
+}
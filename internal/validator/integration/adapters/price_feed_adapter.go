@@ -0,0 +1,14 @@
+package adapters
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type PriceFeedAdapter interface {
+	// GetCrypto returns a model.Coin with the values to buy and sell the crypto coin based on symbol and quote
+	// (symbol.Symbol).
+	GetCrypto(ctx context.Context, symbol symbol.Symbol, quote symbol.Symbol) (*model.Coin, custom_error.BaseErrorAdapter)
+}
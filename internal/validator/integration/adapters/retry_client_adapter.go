@@ -0,0 +1,7 @@
+package adapters
+
+import "net/http"
+
+type RetryClientAdapter interface {
+	Do(request *http.Request, sign func(request *http.Request) error) (*http.Response, error)
+}
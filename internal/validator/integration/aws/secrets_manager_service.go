@@ -26,10 +26,10 @@ func SecretsManagerService(logger adapters.LoggerAdapter, secretsManager adapter
 }
 
 // GetSecret is used to retrieve secrets from secrets manager, returns *dto.Secrets.
-func (s *secretsManagerService) GetSecret(secretName string, secretObject any) custom_error.BaseErrorAdapter {
+func (s *secretsManagerService) GetSecret(ctx context.Context, secretName string, secretObject any) custom_error.BaseErrorAdapter {
 	s.logger.Info("Get secret starting", secretName)
 
-	result, err := s.secretsManager.GetSecretValue(context.TODO(), &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretName)})
+	result, err := s.secretsManager.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretName)})
 	if err != nil {
 		return s.abort(err, "error while getting secret")
 	}
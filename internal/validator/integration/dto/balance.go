@@ -1,6 +1,4 @@
 package dto
 
-type Balance struct {
-	BRL string `json:"BRL"`
-	BTC string `json:"BTC"`
-}
+// Balance is the Biscoint balance data, amounts are keyed by asset symbol.
+type Balance map[string]string
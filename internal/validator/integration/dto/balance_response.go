@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	"strconv"
 )
@@ -11,18 +12,22 @@ type BalanceResponse struct {
 	Balance Balance `json:"data"`
 }
 
+// ToModel creates a model.Balance from dto.BalanceResponse, assets not supported by the validator are ignored.
 func (b *BalanceResponse) ToModel() (*model.Balance, error) {
-	brlBalance, err := strconv.ParseFloat(b.Balance.BRL, 64)
-	if err != nil {
-		return nil, err
-	}
-	cryptoBalance, err := strconv.ParseFloat(b.Balance.BTC, 64)
-	if err != nil {
-		return nil, err
+	balance := &model.Balance{Assets: map[symbol.Symbol]float64{}}
+
+	for asset, amount := range b.Balance {
+		if !symbol.Symbol(asset).IsSupported() {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		balance.Assets[symbol.Symbol(asset)] = value
 	}
 
-	return &model.Balance{
-		BrlBalance:    brlBalance,
-		CryptoBalance: cryptoBalance,
-	}, err
+	return balance, nil
 }
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"strconv"
+)
+
+// BinanceBalanceResponse is the model for Binance account response.
+type BinanceBalanceResponse struct {
+	Balances []BinanceBalance `json:"balances"`
+}
+
+// BinanceBalance is the Binance balance of a single asset, amounts are sent as strings. Free is the amount available,
+// Locked is held by open orders.
+type BinanceBalance struct {
+	Asset  string `json:"asset"`
+	Free   string `json:"free"`
+	Locked string `json:"locked"`
+}
+
+// ToModel creates a model.Balance from dto.BinanceBalanceResponse using the free amount of each asset, assets not
+// supported by the validator are ignored.
+func (b *BinanceBalanceResponse) ToModel() (*model.Balance, error) {
+	balance := &model.Balance{Assets: map[symbol.Symbol]float64{}}
+
+	for _, asset := range b.Balances {
+		if !symbol.Symbol(asset.Asset).IsSupported() {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(asset.Free, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		balance.Assets[symbol.Symbol(asset.Asset)] = value
+	}
+
+	return balance, nil
+}
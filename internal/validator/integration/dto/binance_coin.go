@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"strconv"
+	"time"
+)
+
+// BinanceCoin is the model for Binance book ticker response, prices are sent as strings.
+type BinanceCoin struct {
+	Symbol   string `json:"symbol"`
+	BidPrice string `json:"bidPrice"`
+	AskPrice string `json:"askPrice"`
+}
+
+// ToModel returns model.Coin from dto.BinanceCoin. The book ticker carries no timestamp, so the quote time is informed
+// by the caller.
+func (c *BinanceCoin) ToModel(base symbol.Symbol, quote symbol.Symbol, timestamp time.Time) (*model.Coin, error) {
+	buyValue, err := strconv.ParseFloat(c.AskPrice, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	sellValue, err := strconv.ParseFloat(c.BidPrice, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Coin{
+		Symbol:    base,
+		Quote:     quote,
+		BuyValue:  buyValue,
+		SellValue: sellValue,
+		Timestamp: timestamp,
+	}, nil
+}
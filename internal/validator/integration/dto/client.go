@@ -1,83 +1,169 @@
 package dto
 
 import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/analysis_strength"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/exchange"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
+	"time"
 )
 
 // Client DynamoDB entity for crypto-robot.client repository
 type Client struct {
-	Id                        string     `dynamodbav:"client_id"`
-	Active                    bool       `dynamodbav:"active"`
-	LockedUntil               string     `dynamodbav:"locked_until"`
-	Locked                    bool       `dynamodbav:"locked"`
-	CashAvailable             float64    `dynamodbav:"cash_available"`
-	CashAmount                float64    `dynamodbav:"cash_amount"`
-	CashReserved              float64    `dynamodbav:"cash_reserved"`
-	CryptoAvailable           float64    `dynamodbav:"crypto_available"`
-	CryptoAmount              float64    `dynamodbav:"crypto_amount"`
-	CryptoReserved            float64    `dynamodbav:"crypto_reserved"`
-	OperationStopLoss         float64    `dynamodbav:"operation_stop_loss"`
-	DayStopLoss               float64    `dynamodbav:"day_stop_loss"`
-	MonthStopLoss             float64    `dynamodbav:"month_stop_loss"`
-	OperationAmountPercentage float64    `dynamodbav:"operation_amount_percentage"`
-	BuyOn                     int        `dynamodbav:"buy_on"`
-	SellOn                    int        `dynamodbav:"sell_on"`
-	Symbols                   []string   `dynamodbav:"symbols"`
-	Summary                   []*Summary `dynamodbav:"summary"`
+	Id                        string                             `dynamodbav:"client_id"`
+	Active                    bool                               `dynamodbav:"active"`
+	Exchange                  string                             `dynamodbav:"exchange,omitempty"`
+	Simulation                bool                               `dynamodbav:"simulation,omitempty"`
+	LockedUntil               string                             `dynamodbav:"locked_until"`
+	Locked                    bool                               `dynamodbav:"locked"`
+	LockedAt                  string                             `dynamodbav:"locked_at,omitempty"`
+	LockedBy                  string                             `dynamodbav:"locked_by,omitempty"`
+	LockExpiresAt             string                             `dynamodbav:"lock_expires_at,omitempty"`
+	CashAvailable             float64                            `dynamodbav:"cash_available"`
+	CashAmount                float64                            `dynamodbav:"cash_amount"`
+	CashReserved              float64                            `dynamodbav:"cash_reserved"`
+	Crypto                    map[string]*CryptoBalance          `dynamodbav:"crypto"`
+	CryptoAvailable           float64                            `dynamodbav:"crypto_available,omitempty"`
+	CryptoAmount              float64                            `dynamodbav:"crypto_amount,omitempty"`
+	CryptoReserved            float64                            `dynamodbav:"crypto_reserved,omitempty"`
+	OperationStopLoss         float64                            `dynamodbav:"operation_stop_loss"`
+	DayStopLoss               float64                            `dynamodbav:"day_stop_loss"`
+	MonthStopLoss             float64                            `dynamodbav:"month_stop_loss"`
+	OperationAmountPercentage float64                            `dynamodbav:"operation_amount_percentage"`
+	SlippageTolerance         float64                            `dynamodbav:"slippage_tolerance,omitempty"`
+	BuyOn                     analysis_strength.AnalysisStrength `dynamodbav:"buy_on"`
+	SellOn                    analysis_strength.AnalysisStrength `dynamodbav:"sell_on"`
+	Symbols                   []string                           `dynamodbav:"symbols"`
+	Timezone                  string                             `dynamodbav:"timezone,omitempty"`
+	Summary                   []*Summary                         `dynamodbav:"summary"`
+	Version                   int                                `dynamodbav:"version,omitempty"`
 }
 
 // ClientDto creates a dto.Client from model.Client
 func ClientDto(client *model.Client) *Client {
+	lockedAt := ""
+	if !client.LockedAt.IsZero() {
+		lockedAt = client.LockedAt.Format(time.RFC3339Nano)
+	}
+
+	lockExpiresAt := ""
+	if !client.LockExpiresAt.IsZero() {
+		lockExpiresAt = client.LockExpiresAt.Format(time.RFC3339Nano)
+	}
+
 	return &Client{
 		Id:                        client.Id,
 		Active:                    client.Active,
-		LockedUntil:               client.LockedUntil.String(),
+		Exchange:                  client.Exchange.Name(),
+		Simulation:                client.Simulation,
+		LockedUntil:               client.LockedUntil.Format(time.RFC3339Nano),
 		Locked:                    client.Locked,
+		LockedAt:                  lockedAt,
+		LockedBy:                  client.LockedBy,
+		LockExpiresAt:             lockExpiresAt,
 		CashAvailable:             client.CashAvailable,
 		CashAmount:                client.CashAmount,
 		CashReserved:              client.CashReserved,
-		CryptoAvailable:           client.CryptoAvailable,
-		CryptoAmount:              client.CryptoAmount,
-		CryptoReserved:            client.CryptoReserved,
+		Crypto:                    CryptoBalanceDto(client.Crypto),
 		OperationStopLoss:         client.OperationStopLoss,
 		DayStopLoss:               client.DayStopLoss,
 		MonthStopLoss:             client.MonthStopLoss,
 		OperationAmountPercentage: client.OperationAmountPercentage,
+		SlippageTolerance:         client.SlippageTolerance,
 		BuyOn:                     client.BuyOn,
 		SellOn:                    client.SellOn,
 		Symbols:                   client.Symbols,
+		Timezone:                  client.Timezone,
 		Summary:                   SummaryDto(client.Summary),
+		Version:                   client.Version,
 	}
 }
 
 // ToModel creates a model.Client from dto.Client
 func (client Client) ToModel() *model.Client {
-	lockedUntil := time_utils.From(client.LockedUntil)
+	lockedUntil := time.Time{}
+	if client.LockedUntil != "" {
+		lockedUntil = time_utils.From(client.LockedUntil).Value()
+	}
+
+	lockedAt := time.Time{}
+	if client.LockedAt != "" {
+		lockedAt = time_utils.From(client.LockedAt).Value()
+	}
+
+	lockExpiresAt := time.Time{}
+	if client.LockExpiresAt != "" {
+		lockExpiresAt = time_utils.From(client.LockExpiresAt).Value()
+	}
 
 	var summaries []*model.Summary
 	for _, summaryDto := range client.Summary {
 		summaries = append(summaries, summaryDto.ToModel())
 	}
 
+	crypto := map[symbol.Symbol]*model.CryptoBalance{}
+	for cryptoSymbol, balance := range client.Crypto {
+		crypto[symbol.Symbol(cryptoSymbol)] = balance.ToModel()
+	}
+
+	// clients created before multi-symbol support only have bitcoin balances on crypto_* attributes
+	if len(client.Crypto) == 0 && (client.CryptoAvailable != 0 || client.CryptoAmount != 0 || client.CryptoReserved != 0) {
+		crypto[symbol.Bitcoin] = &model.CryptoBalance{
+			Available: client.CryptoAvailable,
+			Amount:    client.CryptoAmount,
+			Reserved:  client.CryptoReserved,
+		}
+	}
+
+	// clients created before analysis strength support have numeric buy_on and sell_on levels
+	buyOn := legacyAnalysisStrength(client.BuyOn, analysis_strength.Buy, analysis_strength.StrongBuy)
+	sellOn := legacyAnalysisStrength(client.SellOn, analysis_strength.Sell, analysis_strength.StrongSell)
+
+	// clients created before exchange support hold their funds on Biscoint
+	clientExchange := exchange.Exchange(client.Exchange)
+	if clientExchange == "" {
+		clientExchange = exchange.Biscoint
+	}
+
 	return &model.Client{
 		Id:                        client.Id,
 		Active:                    client.Active,
-		LockedUntil:               lockedUntil.Value(),
+		Exchange:                  clientExchange,
+		Simulation:                client.Simulation,
+		LockedUntil:               lockedUntil,
 		Locked:                    client.Locked,
+		LockedAt:                  lockedAt,
+		LockedBy:                  client.LockedBy,
+		LockExpiresAt:             lockExpiresAt,
 		CashAvailable:             client.CashAvailable,
 		CashAmount:                client.CashAmount,
 		CashReserved:              client.CashReserved,
-		CryptoAvailable:           client.CryptoAvailable,
-		CryptoAmount:              client.CryptoAmount,
-		CryptoReserved:            client.CryptoReserved,
+		Crypto:                    crypto,
 		OperationStopLoss:         client.OperationStopLoss,
 		DayStopLoss:               client.DayStopLoss,
 		MonthStopLoss:             client.MonthStopLoss,
 		OperationAmountPercentage: client.OperationAmountPercentage,
-		BuyOn:                     client.BuyOn,
-		SellOn:                    client.SellOn,
+		SlippageTolerance:         client.SlippageTolerance,
+		BuyOn:                     buyOn,
+		SellOn:                    sellOn,
 		Symbols:                   client.Symbols,
+		Timezone:                  client.Timezone,
 		Summary:                   summaries,
+		Version:                   client.Version,
+	}
+}
+
+// legacyAnalysisStrength maps numeric levels, 0 to NEUTRAL, 1 to level and 2 to strongLevel. Other values are returned
+// as they are.
+func legacyAnalysisStrength(value, level, strongLevel analysis_strength.AnalysisStrength) analysis_strength.AnalysisStrength {
+	switch value {
+	case "0":
+		return analysis_strength.Neutral
+	case "1":
+		return level
+	case "2":
+		return strongLevel
 	}
+	return value
 }
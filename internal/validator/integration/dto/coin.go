@@ -3,6 +3,7 @@ package dto
 import (
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"time"
 )
 
 // Coin is the model for Biscoint GetCoin response.
@@ -11,6 +12,7 @@ type Coin struct {
 	Quote     symbol.Symbol `json:"quote"`
 	BuyValue  float64       `json:"ask"`
 	SellValue float64       `json:"bid"`
+	Timestamp time.Time     `json:"timestamp"`
 }
 
 // ToModel returns model.Coin from dto.Coin.
@@ -20,5 +22,6 @@ func (c *Coin) ToModel() *model.Coin {
 		Quote:     c.Quote,
 		BuyValue:  c.BuyValue,
 		SellValue: c.SellValue,
+		Timestamp: c.Timestamp,
 	}
 }
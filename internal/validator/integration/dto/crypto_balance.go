@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+)
+
+// CryptoBalance DynamoDB entity for crypto-robot.client repository
+type CryptoBalance struct {
+	Available float64 `dynamodbav:"available"`
+	Amount    float64 `dynamodbav:"amount"`
+	Reserved  float64 `dynamodbav:"reserved"`
+}
+
+// CryptoBalanceDto creates a dto.CryptoBalance map from model.CryptoBalance map
+func CryptoBalanceDto(balances map[symbol.Symbol]*model.CryptoBalance) map[string]*CryptoBalance {
+	balancesDto := map[string]*CryptoBalance{}
+	for cryptoSymbol, balance := range balances {
+		balancesDto[cryptoSymbol.Name()] = &CryptoBalance{
+			Available: balance.Available,
+			Amount:    balance.Amount,
+			Reserved:  balance.Reserved,
+		}
+	}
+
+	return balancesDto
+}
+
+// ToModel creates a model.CryptoBalance from dto.CryptoBalance
+func (c *CryptoBalance) ToModel() *model.CryptoBalance {
+	return &model.CryptoBalance{
+		Available: c.Available,
+		Amount:    c.Amount,
+		Reserved:  c.Reserved,
+	}
+}
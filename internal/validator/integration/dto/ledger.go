@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+)
+
+// Ledger is the paper exchange virtual account of a simulation client on crypto-robot.ledger repository. Balances are
+// keyed by asset symbol and reservations by operation id.
+type Ledger struct {
+	ClientId     string                        `dynamodbav:"client_id"`
+	Balances     map[string]float64            `dynamodbav:"balances"`
+	Reservations map[string]*LedgerReservation `dynamodbav:"reservations"`
+	Version      int                           `dynamodbav:"version"`
+}
+
+// LedgerReservation is the amount of the asset debited from the ledger for an operation. The validator only credits it
+// back on compensation, reservations of sent operations are settled by the executor completing the operation.
+type LedgerReservation struct {
+	Asset  string  `dynamodbav:"asset"`
+	Amount float64 `dynamodbav:"amount"`
+}
+
+// NewLedger creates the ledger of the client holding the starting balances.
+func NewLedger(clientId string, startingBalances map[string]float64) *Ledger {
+	balances := map[string]float64{}
+	for asset, amount := range startingBalances {
+		balances[asset] = amount
+	}
+
+	return &Ledger{
+		ClientId:     clientId,
+		Balances:     balances,
+		Reservations: map[string]*LedgerReservation{},
+	}
+}
+
+// ToModel returns model.Balance from dto.Ledger. Reserved amounts are added to the balances, like an exchange account
+// still holds the funds reserved by the validator.
+func (l *Ledger) ToModel() *model.Balance {
+	assets := map[symbol.Symbol]float64{}
+	for asset, amount := range l.Balances {
+		assets[symbol.Symbol(asset)] += amount
+	}
+	for _, reservation := range l.Reservations {
+		assets[symbol.Symbol(reservation.Asset)] += reservation.Amount
+	}
+
+	return &model.Balance{
+		Assets: assets,
+	}
+}
@@ -9,27 +9,50 @@ import (
 )
 
 type Operation struct {
-	Id        string                       `dynamodbav:"operation_id"`
-	Status    status.Status                `dynamodbav:"status"`
-	CreatedAt time.Time                    `dynamodbav:"created_at"`
-	Locked    bool                         `dynamodbav:"locked"`
-	Type      operation_type.OperationType `dynamodbav:"type"`
-	Amount    float64                      `dynamodbav:"amount"`
-	Base      symbol.Symbol                `dynamodbav:"base"`
-	Quote     symbol.Symbol                `dynamodbav:"quote"`
-	StopLoss  float64                      `dynamodbav:"stop_loss"`
+	Id          string                       `dynamodbav:"operation_id"`
+	Status      status.Status                `dynamodbav:"status"`
+	CreatedAt   time.Time                    `dynamodbav:"created_at"`
+	Locked      bool                         `dynamodbav:"locked"`
+	Type        operation_type.OperationType `dynamodbav:"type"`
+	Amount      float64                      `dynamodbav:"amount"`
+	Fee         float64                      `dynamodbav:"fee"`
+	Base        symbol.Symbol                `dynamodbav:"base"`
+	Quote       symbol.Symbol                `dynamodbav:"quote"`
+	StopLoss    float64                      `dynamodbav:"stop_loss"`
+	SignalPrice float64                      `dynamodbav:"signal_price,omitempty"`
+	Simulation  bool                         `dynamodbav:"simulation"`
 }
 
 func OperationDto(operation *model.Operation) *Operation {
 	return &Operation{
-		Id:        operation.Id,
-		Status:    operation.Status,
-		CreatedAt: operation.CreatedAt,
-		Locked:    operation.Locked,
-		Type:      operation.Type,
-		Amount:    operation.Amount,
-		Base:      operation.Base,
-		Quote:     operation.Quote,
-		StopLoss:  operation.StopLoss,
+		Id:          operation.Id,
+		Status:      operation.Status,
+		CreatedAt:   operation.CreatedAt,
+		Locked:      operation.Locked,
+		Type:        operation.Type,
+		Amount:      operation.Amount,
+		Fee:         operation.Fee,
+		Base:        operation.Base,
+		Quote:       operation.Quote,
+		StopLoss:    operation.StopLoss,
+		SignalPrice: operation.SignalPrice,
+		Simulation:  operation.Simulation,
+	}
+}
+
+func (o *Operation) ToModel() *model.Operation {
+	return &model.Operation{
+		Id:          o.Id,
+		Status:      o.Status,
+		CreatedAt:   o.CreatedAt,
+		Locked:      o.Locked,
+		Type:        o.Type,
+		Amount:      o.Amount,
+		Fee:         o.Fee,
+		Base:        o.Base,
+		Quote:       o.Quote,
+		StopLoss:    o.StopLoss,
+		SignalPrice: o.SignalPrice,
+		Simulation:  o.Simulation,
 	}
 }
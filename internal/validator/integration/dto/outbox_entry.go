@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/lock_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"time"
+)
+
+// OutboxEntry DynamoDB entity for crypto-robot.outbox repository
+type OutboxEntry struct {
+	Id        string                     `dynamodbav:"outbox_id"`
+	Type      outbox_type.OutboxType     `dynamodbav:"type"`
+	Status    outbox_status.OutboxStatus `dynamodbav:"status"`
+	ClientId  string                     `dynamodbav:"client_id"`
+	Operation *Operation                 `dynamodbav:"operation,omitempty"`
+	Locks     []lock_type.LockType       `dynamodbav:"locks,omitempty"`
+	Reason    string                     `dynamodbav:"reason,omitempty"`
+	CreatedAt time.Time                  `dynamodbav:"created_at"`
+}
+
+// OutboxEntryDto creates a dto.OutboxEntry from model.OutboxEntry
+func OutboxEntryDto(entry *model.OutboxEntry) *OutboxEntry {
+	entryDto := &OutboxEntry{
+		Id:        entry.Id,
+		Type:      entry.Type,
+		Status:    entry.Status,
+		ClientId:  entry.ClientId,
+		Locks:     entry.Locks,
+		Reason:    entry.Reason,
+		CreatedAt: entry.CreatedAt,
+	}
+	if entry.Operation != nil {
+		entryDto.Operation = OperationDto(entry.Operation)
+	}
+	return entryDto
+}
+
+// ToModel creates a model.OutboxEntry from dto.OutboxEntry
+func (o *OutboxEntry) ToModel() *model.OutboxEntry {
+	entry := &model.OutboxEntry{
+		Id:        o.Id,
+		Type:      o.Type,
+		Status:    o.Status,
+		ClientId:  o.ClientId,
+		Locks:     o.Locks,
+		Reason:    o.Reason,
+		CreatedAt: o.CreatedAt,
+	}
+	if o.Operation != nil {
+		entry.Operation = o.Operation.ToModel()
+	}
+	return entry
+}
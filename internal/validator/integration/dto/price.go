@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"time"
+)
+
+// Price is the latest quote recorded for a pair (e.g. BTCBRL) on crypto-robot.prices repository.
+type Price struct {
+	Pair      string        `dynamodbav:"pair"`
+	Symbol    symbol.Symbol `dynamodbav:"symbol"`
+	Quote     symbol.Symbol `dynamodbav:"quote"`
+	BuyValue  float64       `dynamodbav:"buy_value"`
+	SellValue float64       `dynamodbav:"sell_value"`
+	Timestamp time.Time     `dynamodbav:"timestamp"`
+}
+
+// ToModel returns model.Coin from dto.Price.
+func (p *Price) ToModel() *model.Coin {
+	return &model.Coin{
+		Symbol:    p.Symbol,
+		Quote:     p.Quote,
+		BuyValue:  p.BuyValue,
+		SellValue: p.SellValue,
+		Timestamp: p.Timestamp,
+	}
+}
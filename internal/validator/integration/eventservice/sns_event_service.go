@@ -25,7 +25,7 @@ func SNSEventService(logger adapters2.LoggerAdapter, sns adapters.SNSAdapter) *s
 }
 
 // Send will create a publish request for AWS SNS topic.
-func (s *snsEventService) Send(messageObject interface{}) custom_error.BaseErrorAdapter {
+func (s *snsEventService) Send(ctx context.Context, messageObject interface{}) custom_error.BaseErrorAdapter {
 	s.logger.Info("Send started", messageObject)
 
 	stringMessage, err := json.Marshal(messageObject)
@@ -39,7 +39,7 @@ func (s *snsEventService) Send(messageObject interface{}) custom_error.BaseError
 		TopicArn: &properties.Properties().CryptoOperationExecutorTopicArn,
 	}
 
-	result, err := s.sns.Publish(context.TODO(), publishInput)
+	result, err := s.sns.Publish(ctx, publishInput)
 	if err != nil {
 		return s.abort(err, "Error while trying to publish", publishInput)
 	}
@@ -0,0 +1,15 @@
+package exceptions
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+// CircuitBreakerError is the base error class for persistence.RedisCircuitBreaker, requests are not sent while the
+// circuit is open so the error is flagged with error_code.ServiceUnavailable to be retried later.
+func CircuitBreakerError(err error, internalError string) custom_error.BaseErrorAdapter {
+	baseError := custom_error.NewBaseError(err, internalError, "Circuit breaker is open")
+	baseError.SetLocks(true, true)
+	baseError.SetCode(error_code.ServiceUnavailable.Name())
+	return baseError
+}
@@ -0,0 +1,10 @@
+package exceptions
+
+import "github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+
+// DynamoDBPricePersistenceError is the base error class for persistence.DynamoDBPricePersistence.
+func DynamoDBPricePersistenceError(err error, internalError string) custom_error.BaseErrorAdapter {
+	baseError := custom_error.NewBaseError(err, internalError, "Error while using DynamoDB Price table")
+	baseError.SetLocks(true, true)
+	return baseError
+}
@@ -0,0 +1,14 @@
+package exceptions
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+// ExchangeRegistryError is the base error class for webservice.ExchangeRegistry.
+func ExchangeRegistryError(err error, internalError string) custom_error.BaseErrorAdapter {
+	baseError := custom_error.NewBaseError(err, internalError, "Exchange is not supported")
+	baseError.SetLocks(true, true)
+	baseError.SetCode(error_code.ExchangeNotSupported.Name())
+	return baseError
+}
@@ -0,0 +1,10 @@
+package exceptions
+
+import "github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+
+// PaperExchangeError is the base error class for webservice.PaperExchange.
+func PaperExchangeError(err error, internalError string) custom_error.BaseErrorAdapter {
+	baseError := custom_error.NewBaseError(err, internalError, "Error while using the paper exchange")
+	baseError.SetLocks(true, true)
+	return baseError
+}
@@ -2,36 +2,50 @@ package persistence
 
 import (
 	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/status"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
+	"strconv"
+	"time"
 )
 
 type dynamoDBClientPersistence struct {
 	logger   adapters.LoggerAdapter
 	dynamoDB adapters2.DynamoDBAdapter
+	owner    string
+	lease    time.Duration
 }
 
-// DynamoDBClientPersistence class constructor
-func DynamoDBClientPersistence(logger adapters.LoggerAdapter, dynamoDB adapters2.DynamoDBAdapter) *dynamoDBClientPersistence {
+// DynamoDBClientPersistence class constructor, owner is the invocation id written as locked_by on the clients it
+// locks, so a lock can be traced back to the invocation holding it.
+func DynamoDBClientPersistence(logger adapters.LoggerAdapter, dynamoDB adapters2.DynamoDBAdapter, owner string) *dynamoDBClientPersistence {
 	return &dynamoDBClientPersistence{
 		logger:   logger,
 		dynamoDB: dynamoDB,
+		owner:    owner,
+		lease:    properties.Properties().ClientLockLease,
 	}
 }
 
-// GetClient will find model.Client on client DynamoDB repository using clientId as key.
-func (d *dynamoDBClientPersistence) GetClient(clientId string) (*model.Client, custom_error.BaseErrorAdapter) {
+// GetClient will find model.Client on client DynamoDB repository using clientId as key. Returns error with
+// error_code.ClientLocked if the client lock lease is live, clients whose lock lease is expired are returned as free.
+func (d *dynamoDBClientPersistence) GetClient(ctx context.Context, clientId string) (*model.Client, custom_error.BaseErrorAdapter) {
 	d.logger.Info("GetClient started", clientId)
 
-	response, err := d.dynamoDB.GetItem(context.TODO(), &dynamodb.GetItemInput{
+	response, err := d.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
 		Key: map[string]types.AttributeValue{
 			"client_id": &types.AttributeValueMemberS{Value: clientId},
 		},
@@ -51,70 +65,419 @@ func (d *dynamoDBClientPersistence) GetClient(clientId string) (*model.Client, c
 		return nil, d.abort(err, "Error while trying to unmarshal get client response.")
 	}
 
-	if clientDto.Locked {
-		return nil, d.abort(err, "Client is locked.")
+	client := clientDto.ToModel()
+
+	if client.IsLocked() {
+		return nil, d.abortWithCode(err, "Client is locked.", error_code.ClientLocked)
 	}
 
-	client := clientDto.ToModel()
+	if client.IsLockedUntil() {
+		return nil, d.abortWithCode(err, "Client is locked until locked_until date.", error_code.ClientLockedUntil)
+	}
 
 	d.logger.Info("GetClient finished", clientId, client)
 	return client, nil
 }
 
-// Lock will update model.Client setting flag locked as true on client DynamoDB repository. Returns error if client is
-// already locked.
-func (d *dynamoDBClientPersistence) Lock(client *model.Client) custom_error.BaseErrorAdapter {
-	d.logger.Info("Lock started", client)
+// GetLockedClients will find every model.Client with flag locked set as true on client DynamoDB repository, the table
+// is scanned page by page until there are no more items left.
+func (d *dynamoDBClientPersistence) GetLockedClients(ctx context.Context) ([]*model.Client, custom_error.BaseErrorAdapter) {
+	d.logger.Info("GetLockedClients started")
 
-	client.Lock()
+	var clients []*model.Client
+	var lastEvaluatedKey map[string]types.AttributeValue
+	for {
+		response, err := d.dynamoDB.Scan(ctx, &dynamodb.ScanInput{
+			TableName:        properties.Properties().Aws.DynamoDB.ClientTableName,
+			FilterExpression: aws.String("#locked = :locked"),
+			ExpressionAttributeNames: map[string]string{
+				"#locked": "locked",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":locked": &types.AttributeValueMemberBOOL{Value: true},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		})
+		if err != nil {
+			return nil, d.abort(err, "Error while trying to scan locked clients.")
+		}
 
-	clientDto := dto.ClientDto(client)
+		var clientsDto []*dto.Client
+		err = attributevalue.UnmarshalListOfMaps(response.Items, &clientsDto)
+		if err != nil {
+			return nil, d.abort(err, "Error while trying to unmarshal locked clients.")
+		}
+
+		for _, clientDto := range clientsDto {
+			clients = append(clients, clientDto.ToModel())
+		}
+
+		if len(response.LastEvaluatedKey) == 0 {
+			break
+		}
+		lastEvaluatedKey = response.LastEvaluatedKey
+	}
+
+	d.logger.Info("GetLockedClients finished", len(clients))
+	return clients, nil
+}
+
+// Lock will update model.Client setting flag locked as true on client DynamoDB repository, together with a lease
+// owned by this instance (locked_by) that expires after CLIENT_LOCK_LEASE_SECONDS (lock_expires_at). A client whose
+// lease is expired is locked as if it was free. The update is conditioned on the lock read and on client version, so
+// concurrent validators cannot lock the same client. Returns error if client is already locked or was modified since
+// it was read.
+func (d *dynamoDBClientPersistence) Lock(ctx context.Context, client *model.Client) custom_error.BaseErrorAdapter {
+	d.logger.Info("Lock started", client)
+
+	if client.IsLocked() {
+		return d.abortWithCode(nil, "Client is locked.", error_code.ClientLocked)
+	}
 
-	err := d.update(clientDto)
+	err := d.update(ctx, client, true, "")
 	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return d.abortWithCode(err, "Client is locked or was modified by another process.", error_code.ClientLocked)
+		}
 		return d.abort(err, "Error while trying to lock client.")
 	}
 
+	client.Lock()
+
 	d.logger.Info("Lock finished", client)
 	return nil
 }
 
-// Unlock will update model.Client setting flag locked as false on client DynamoDB repository.
-func (d *dynamoDBClientPersistence) Unlock(client *model.Client) custom_error.BaseErrorAdapter {
+// LockUntil will update model.Client locked_until value on client DynamoDB repository. Only locked_until attribute is
+// written, so a client is kept from operating until the date is reached.
+func (d *dynamoDBClientPersistence) LockUntil(ctx context.Context, client *model.Client) custom_error.BaseErrorAdapter {
+	d.logger.Info("LockUntil started", client)
+
+	_, err := d.dynamoDB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		Key: map[string]types.AttributeValue{
+			"client_id": &types.AttributeValueMemberS{Value: client.Id},
+		},
+		TableName:        properties.Properties().Aws.DynamoDB.ClientTableName,
+		UpdateExpression: aws.String("SET locked_until = :locked_until"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":locked_until": &types.AttributeValueMemberS{Value: client.LockedUntil.Format(time.RFC3339Nano)},
+		},
+	})
+	if err != nil {
+		return d.abort(err, "Error while trying to update client locked_until.")
+	}
+
+	d.logger.Info("LockUntil finished", client)
+	return nil
+}
+
+// Unlock will update model.Client setting flag locked as false on client DynamoDB repository and removing its lease,
+// the summary changed during validation is written together. Balances are only written by SaveReservation, so a failed
+// validation never persists a reservation. The update is conditioned on the lease being owned by this instance and on
+// client version, so a lock taken over after the lease expired and changes made by other processes are not
+// overwritten. Errors flag both client locks as held, the client is still locked on DynamoDB.
+func (d *dynamoDBClientPersistence) Unlock(ctx context.Context, client *model.Client) custom_error.BaseErrorAdapter {
 	d.logger.Info("Unlock started", client)
 
+	err := d.update(ctx, client, false, d.owner)
+	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return keepLocks(d.abortWithCode(err, "Client lock is not owned by this process or client was modified by another process.", error_code.ClientModified))
+		}
+		return keepLocks(d.abort(err, "Error while trying to unlock client."))
+	}
+
+	client.Unlock()
+
+	d.logger.Info("Unlock finished", client)
+	return nil
+}
+
+// ReleaseLock will update model.Client setting flag locked as false on client DynamoDB repository regardless of the
+// lock owner, used to release locks whose lease expired or whose owner could not unlock them. The update is
+// conditioned on client version, so a client locked again or modified after it was read is not released.
+func (d *dynamoDBClientPersistence) ReleaseLock(ctx context.Context, client *model.Client) custom_error.BaseErrorAdapter {
+	d.logger.Info("ReleaseLock started", client)
+
+	err := d.update(ctx, client, false, "")
+	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return d.abortWithCode(err, "Client was modified by another process.", error_code.ClientModified)
+		}
+		return d.abort(err, "Error while trying to release client lock.")
+	}
+
 	client.Unlock()
 
+	d.logger.Info("ReleaseLock finished", client)
+	return nil
+}
+
+// SaveReservation will update model.Client balances and reservations on client DynamoDB repository, create the
+// model.Operation on operation DynamoDB repository and its event model.OutboxEntry on outbox DynamoDB repository in a
+// single transaction. The client update is conditioned on client being locked and on its version, the operation and
+// the event are only created if they do not exist or the operation was cancelled (its event was never sent). Returns
+// error and writes nothing if any of the conditions fail.
+func (d *dynamoDBClientPersistence) SaveReservation(ctx context.Context, client *model.Client, operation *model.Operation, event *model.OutboxEntry) custom_error.BaseErrorAdapter {
+	d.logger.Info("SaveReservation started", client, operation, event)
+
+	operationInput, err := attributevalue.MarshalMap(dto.OperationDto(operation))
+	if err != nil {
+		return keepLocks(d.abort(err, "Error while trying to marshal operation."))
+	}
+
+	eventInput, err := attributevalue.MarshalMap(dto.OutboxEntryDto(event))
+	if err != nil {
+		return keepLocks(d.abort(err, "Error while trying to marshal outbox entry."))
+	}
+
+	operationValues, err := attributevalue.MarshalMap(map[string]interface{}{
+		":cancelled": status.Cancelled,
+	})
+	if err != nil {
+		return keepLocks(d.abort(err, "Error while trying to marshal operation status."))
+	}
+
+	eventValues, err := attributevalue.MarshalMap(map[string]interface{}{
+		":sent": outbox_status.Sent,
+	})
+	if err != nil {
+		return keepLocks(d.abort(err, "Error while trying to marshal outbox status."))
+	}
+
+	err = d.transactBalances(ctx, client, types.TransactWriteItem{
+		Put: &types.Put{
+			TableName:           properties.Properties().Aws.DynamoDB.OperationTableName,
+			Item:                operationInput,
+			ConditionExpression: aws.String("attribute_not_exists(#operation_id) OR #status = :cancelled"),
+			ExpressionAttributeNames: map[string]string{
+				"#operation_id": "operation_id",
+				"#status":       "status",
+			},
+			ExpressionAttributeValues: operationValues,
+		},
+	}, types.TransactWriteItem{
+		Put: &types.Put{
+			TableName:           properties.Properties().Aws.DynamoDB.OutboxTableName,
+			Item:                eventInput,
+			ConditionExpression: aws.String("attribute_not_exists(#outbox_id) OR #status <> :sent"),
+			ExpressionAttributeNames: map[string]string{
+				"#outbox_id": "outbox_id",
+				"#status":    "status",
+			},
+			ExpressionAttributeValues: eventValues,
+		},
+	})
+	if err != nil {
+		return d.abortTransaction(err, "Operation already exists.", error_code.OperationExists)
+	}
+
+	d.logger.Info("SaveReservation finished", client, operation, event)
+	return nil
+}
+
+// ReleaseReservation will update model.Client balances and reservations on client DynamoDB repository and update the
+// model.Operation status on operation DynamoDB repository in a single transaction, used to compensate a reservation
+// whose operation was cancelled. The operation is only updated if it is still CREATED.
+func (d *dynamoDBClientPersistence) ReleaseReservation(ctx context.Context, client *model.Client, operation *model.Operation) custom_error.BaseErrorAdapter {
+	d.logger.Info("ReleaseReservation started", client, operation)
+
+	values, err := attributevalue.MarshalMap(map[string]interface{}{
+		":status":          operation.Status,
+		":expected_status": status.Created,
+	})
+	if err != nil {
+		return keepLocks(d.abort(err, "Error while trying to marshal operation status."))
+	}
+
+	err = d.transactBalances(ctx, client, types.TransactWriteItem{
+		Update: &types.Update{
+			Key: map[string]types.AttributeValue{
+				"operation_id": &types.AttributeValueMemberS{Value: operation.Id},
+			},
+			TableName:           properties.Properties().Aws.DynamoDB.OperationTableName,
+			UpdateExpression:    aws.String("SET #status = :status"),
+			ConditionExpression: aws.String("#status = :expected_status"),
+			ExpressionAttributeNames: map[string]string{
+				"#status": "status",
+			},
+			ExpressionAttributeValues: values,
+		},
+	})
+	if err != nil {
+		return d.abortTransaction(err, "Operation was modified by another process.", error_code.OperationModified)
+	}
+
+	d.logger.Info("ReleaseReservation finished", client, operation)
+	return nil
+}
+
+// transactBalances writes the client balances and the operation items in a single transaction, client version is
+// incremented and checked for optimistic concurrency.
+func (d *dynamoDBClientPersistence) transactBalances(ctx context.Context, client *model.Client, operationItems ...types.TransactWriteItem) error {
 	clientDto := dto.ClientDto(client)
 
-	err := d.update(clientDto)
+	names := map[string]string{
+		"#locked":        "locked",
+		"#version":       "version",
+		"#cash_amount":   "cash_amount",
+		"#cash_reserved": "cash_reserved",
+		"#crypto":        "crypto",
+	}
+
+	values, err := attributevalue.MarshalMap(map[string]interface{}{
+		":expected_locked": true,
+		":version":         clientDto.Version + 1,
+		":cash_amount":     clientDto.CashAmount,
+		":cash_reserved":   clientDto.CashReserved,
+		":crypto":          clientDto.Crypto,
+	})
 	if err != nil {
-		return d.abort(err, "Error while trying to unlock client.")
+		return err
 	}
 
-	d.logger.Info("Unlock finished", client)
+	clientItem := types.TransactWriteItem{
+		Update: &types.Update{
+			Key: map[string]types.AttributeValue{
+				"client_id": &types.AttributeValueMemberS{Value: client.Id},
+			},
+			TableName:                 properties.Properties().Aws.DynamoDB.ClientTableName,
+			UpdateExpression:          aws.String("SET #version = :version, #cash_amount = :cash_amount, #cash_reserved = :cash_reserved, #crypto = :crypto"),
+			ConditionExpression:       aws.String(versionCondition(clientDto, values)),
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: values,
+		},
+	}
+
+	_, err = d.dynamoDB.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: append([]types.TransactWriteItem{clientItem}, operationItems...),
+	})
+	if err != nil {
+		return err
+	}
+
+	client.Version = clientDto.Version + 1
 	return nil
 }
 
-func (d *dynamoDBClientPersistence) update(client *dto.Client) custom_error.BaseErrorAdapter {
-	clientInput, err := attributevalue.MarshalMap(client)
+// abortTransaction maps the transactBalances error, a canceled transaction is caused either by the client condition
+// (first item) or by the operation conditions (following items).
+func (d *dynamoDBClientPersistence) abortTransaction(err error, operationConflict string, operationCode error_code.ErrorCode) custom_error.BaseErrorAdapter {
+	var transactionCanceled *types.TransactionCanceledException
+	if !errors.As(err, &transactionCanceled) {
+		return keepLocks(d.abort(err, "Error while trying to save client balances."))
+	}
+
+	for i, reason := range transactionCanceled.CancellationReasons {
+		if i > 0 && aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+			return keepLocks(d.abortWithCode(err, operationConflict, operationCode))
+		}
+	}
+
+	return keepLocks(d.abortWithCode(err, "Client was modified by another process.", error_code.ClientModified))
+}
+
+// keepLocks flags both client locks as held, used when the client is still locked on DynamoDB after the error.
+func keepLocks(clientError custom_error.BaseErrorAdapter) custom_error.BaseErrorAdapter {
+	clientError.SetLocks(true, true)
+	return clientError
+}
+
+// update writes only the lock attributes and the summary, attributes unknown to dto.Client are kept. Locking writes
+// the lease (locked_by, locked_at and lock_expires_at), unlocking removes locked_by and lock_expires_at and, when owner
+// is set, is conditioned on locked_by. Client version is incremented and checked for optimistic concurrency, a client
+// without version was never updated by this method.
+func (d *dynamoDBClientPersistence) update(ctx context.Context, client *model.Client, locked bool, owner string) error {
+	clientDto := dto.ClientDto(client)
+	lockedAt := time_utils.Time().Now()
+	lockExpiresAt := lockedAt.Add(d.lease)
+
+	// locking expects the lock read, so a client whose lease expired can be locked again
+	expectedLocked := true
+	if locked {
+		expectedLocked = client.Locked
+	}
+
+	names := map[string]string{
+		"#locked":          "locked",
+		"#version":         "version",
+		"#summary":         "summary",
+		"#locked_by":       "locked_by",
+		"#lock_expires_at": "lock_expires_at",
+	}
+
+	values, err := attributevalue.MarshalMap(map[string]interface{}{
+		":locked":          locked,
+		":expected_locked": expectedLocked,
+		":version":         clientDto.Version + 1,
+		":summary":         clientDto.Summary,
+	})
 	if err != nil {
-		return d.abort(err, "Error while trying to marshal client.")
+		return err
 	}
 
-	_, err = d.dynamoDB.PutItem(context.TODO(), &dynamodb.PutItemInput{
-		TableName: properties.Properties().Aws.DynamoDB.ClientTableName,
-		Item:      clientInput,
+	updateExpression := "SET #locked = :locked, #version = :version, #summary = :summary"
+	conditionExpression := versionCondition(clientDto, values)
+	if locked {
+		names["#locked_at"] = "locked_at"
+		values[":locked_at"] = &types.AttributeValueMemberS{Value: lockedAt.Format(time.RFC3339Nano)}
+		values[":locked_by"] = &types.AttributeValueMemberS{Value: d.owner}
+		values[":lock_expires_at"] = &types.AttributeValueMemberS{Value: lockExpiresAt.Format(time.RFC3339Nano)}
+		updateExpression += ", #locked_at = :locked_at, #locked_by = :locked_by, #lock_expires_at = :lock_expires_at"
+	} else {
+		updateExpression += " REMOVE #locked_by, #lock_expires_at"
+	}
+
+	if owner != "" {
+		values[":owner"] = &types.AttributeValueMemberS{Value: owner}
+		conditionExpression += " AND #locked_by = :owner"
+	}
+
+	_, err = d.dynamoDB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		Key: map[string]types.AttributeValue{
+			"client_id": &types.AttributeValueMemberS{Value: client.Id},
+		},
+		TableName:                 properties.Properties().Aws.DynamoDB.ClientTableName,
+		UpdateExpression:          aws.String(updateExpression),
+		ConditionExpression:       aws.String(conditionExpression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
 	})
 	if err != nil {
-		return d.abort(err, "Error while trying to update client.")
+		return err
 	}
 
+	if locked {
+		client.LockedAt = lockedAt
+		client.LockedBy = d.owner
+		client.LockExpiresAt = lockExpiresAt
+	}
+	client.Version = clientDto.Version + 1
 	return nil
 }
 
+// versionCondition conditions the write on the expected locked flag and on the client version read, adding the
+// current version to the expression values when needed.
+func versionCondition(clientDto *dto.Client, values map[string]types.AttributeValue) string {
+	if clientDto.Version == 0 {
+		return "#locked = :expected_locked AND attribute_not_exists(#version)"
+	}
+
+	values[":current_version"] = &types.AttributeValueMemberN{Value: strconv.Itoa(clientDto.Version)}
+	return "#locked = :expected_locked AND #version = :current_version"
+}
+
 func (d *dynamoDBClientPersistence) abort(err error, message string) custom_error.BaseErrorAdapter {
 	dynamoDBClientPersistenceError := exceptions.DynamoDBClientPersistenceError(err, message)
 	d.logger.Error(dynamoDBClientPersistenceError, "Get clients failed: "+message)
 	return dynamoDBClientPersistenceError
 }
+
+func (d *dynamoDBClientPersistence) abortWithCode(err error, message string, code error_code.ErrorCode) custom_error.BaseErrorAdapter {
+	dynamoDBClientPersistenceError := d.abort(err, message)
+	dynamoDBClientPersistenceError.SetCode(code.Name())
+	return dynamoDBClientPersistenceError
+}
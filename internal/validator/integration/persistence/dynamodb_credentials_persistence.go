@@ -27,10 +27,10 @@ func DynamoDBCredentialsPersistence(logger adapters.LoggerAdapter, dynamoDB adap
 }
 
 // GetCredentials will find dto.Credentials on credentials DynamoDB repository using clientId as key.
-func (d *dynamoDBCredentialsPersistence) GetCredentials(clientId string) (*dto.Credentials, custom_error.BaseErrorAdapter) {
+func (d *dynamoDBCredentialsPersistence) GetCredentials(ctx context.Context, clientId string) (*dto.Credentials, custom_error.BaseErrorAdapter) {
 	d.logger.Info("GetCredentials started", clientId)
 
-	response, err := d.dynamoDB.GetItem(context.TODO(), &dynamodb.GetItemInput{
+	response, err := d.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
 		Key: map[string]types.AttributeValue{
 			"client_id": &types.AttributeValueMemberS{Value: clientId},
 		},
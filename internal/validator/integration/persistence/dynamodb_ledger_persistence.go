@@ -0,0 +1,120 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"strconv"
+)
+
+type dynamoDBLedgerPersistence struct {
+	logger   adapters.LoggerAdapter
+	dynamoDB adapters2.DynamoDBAdapter
+}
+
+// DynamoDBLedgerPersistence class constructor
+func DynamoDBLedgerPersistence(logger adapters.LoggerAdapter, dynamoDB adapters2.DynamoDBAdapter) *dynamoDBLedgerPersistence {
+	return &dynamoDBLedgerPersistence{
+		logger:   logger,
+		dynamoDB: dynamoDB,
+	}
+}
+
+// Get will find dto.Ledger on ledger DynamoDB repository using clientId as key. Returns nil if the client has no
+// ledger.
+func (d *dynamoDBLedgerPersistence) Get(ctx context.Context, clientId string) (*dto.Ledger, custom_error.BaseErrorAdapter) {
+	d.logger.Info("Get ledger started", clientId)
+
+	response, err := d.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
+		Key: map[string]types.AttributeValue{
+			"client_id": &types.AttributeValueMemberS{Value: clientId},
+		},
+		TableName: properties.Properties().Aws.DynamoDB.LedgerTableName,
+	})
+	if err != nil {
+		return nil, d.abort(err, "Error while trying to get ledger.")
+	}
+
+	if response.Item == nil {
+		d.logger.Info("Get ledger finished, ledger not found", clientId)
+		return nil, nil
+	}
+
+	var ledger *dto.Ledger
+	err = attributevalue.UnmarshalMap(response.Item, &ledger)
+	if err != nil {
+		return nil, d.abort(err, "Error while trying to unmarshal get ledger response.")
+	}
+
+	d.logger.Info("Get ledger finished", clientId, ledger)
+	return ledger, nil
+}
+
+// Save will persist dto.Ledger on ledger DynamoDB repository. Ledger version is incremented and checked for optimistic
+// concurrency, a ledger without version is only created if the client has none. Returns error with
+// error_code.LedgerModified if the condition fails.
+func (d *dynamoDBLedgerPersistence) Save(ctx context.Context, ledger *dto.Ledger) custom_error.BaseErrorAdapter {
+	d.logger.Info("Save ledger started", ledger)
+
+	ledgerDto := *ledger
+	ledgerDto.Version = ledger.Version + 1
+
+	ledgerInput, err := attributevalue.MarshalMap(ledgerDto)
+	if err != nil {
+		return d.abort(err, "Error while trying to marshal ledger.")
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           properties.Properties().Aws.DynamoDB.LedgerTableName,
+		Item:                ledgerInput,
+		ConditionExpression: aws.String("attribute_not_exists(#client_id)"),
+		ExpressionAttributeNames: map[string]string{
+			"#client_id": "client_id",
+		},
+	}
+	if ledger.Version > 0 {
+		input.ConditionExpression = aws.String("#version = :version")
+		input.ExpressionAttributeNames = map[string]string{
+			"#version": "version",
+		}
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":version": &types.AttributeValueMemberN{Value: strconv.Itoa(ledger.Version)},
+		}
+	}
+
+	_, err = d.dynamoDB.PutItem(ctx, input)
+	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return d.abortWithCode(err, "Ledger was modified by another process.", error_code.LedgerModified)
+		}
+		return d.abort(err, "Error while trying to save ledger.")
+	}
+
+	ledger.Version = ledgerDto.Version
+
+	d.logger.Info("Save ledger finished", ledger)
+	return nil
+}
+
+func (d *dynamoDBLedgerPersistence) abort(err error, message string) custom_error.BaseErrorAdapter {
+	dynamoDBLedgerPersistenceError := exceptions.DynamoDBLedgerPersistenceError(err, message)
+	d.logger.Error(dynamoDBLedgerPersistenceError, "Ledger persistence failed: "+message)
+	return dynamoDBLedgerPersistenceError
+}
+
+func (d *dynamoDBLedgerPersistence) abortWithCode(err error, message string, code error_code.ErrorCode) custom_error.BaseErrorAdapter {
+	dynamoDBLedgerPersistenceError := d.abort(err, message)
+	dynamoDBLedgerPersistenceError.SetCode(code.Name())
+	return dynamoDBLedgerPersistenceError
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
@@ -26,29 +27,40 @@ func DynamoDBOperationPersistence(logger adapters.LoggerAdapter, dynamoDB adapte
 	}
 }
 
-func (d *dynamoDBOperationPersistence) Save(operation *model.Operation) custom_error.BaseErrorAdapter {
-	d.logger.Info("Save operation started", operation)
+// Get will find model.Operation on operation DynamoDB repository using operationId as key. Returns nil operation if
+// it does not exist.
+func (d *dynamoDBOperationPersistence) Get(ctx context.Context, operationId string) (*model.Operation, custom_error.BaseErrorAdapter) {
+	d.logger.Info("Get operation started", operationId)
 
-	operationDto := dto.OperationDto(operation)
-	operationInput, err := attributevalue.MarshalMap(operationDto)
+	response, err := d.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
+		Key: map[string]types.AttributeValue{
+			"operation_id": &types.AttributeValueMemberS{Value: operationId},
+		},
+		TableName: properties.Properties().Aws.DynamoDB.OperationTableName,
+	})
 	if err != nil {
-		return d.abort(err, "Error while trying to marshal operation.")
+		return nil, d.abort(err, "Error while trying to get operation.")
 	}
 
-	_, err = d.dynamoDB.PutItem(context.TODO(), &dynamodb.PutItemInput{
-		TableName: properties.Properties().Aws.DynamoDB.OperationTableName,
-		Item:      operationInput,
-	})
+	if response.Item == nil {
+		d.logger.Info("Get operation finished, operation not found", operationId)
+		return nil, nil
+	}
+
+	var operationDto *dto.Operation
+	err = attributevalue.UnmarshalMap(response.Item, &operationDto)
 	if err != nil {
-		return d.abort(err, "Error while trying to update operation.")
+		return nil, d.abort(err, "Error while trying to unmarshal get operation response.")
 	}
 
-	d.logger.Info("Save operation finished", operation, operationDto)
-	return nil
+	operation := operationDto.ToModel()
+
+	d.logger.Info("Get operation finished", operationId, operation)
+	return operation, nil
 }
 
 func (d *dynamoDBOperationPersistence) abort(err error, message string) custom_error.BaseErrorAdapter {
 	dynamoDBOperationPersistenceError := exceptions.DynamoDBOperationPersistenceError(err, message)
-	d.logger.Error(dynamoDBOperationPersistenceError, "Save operation failed: "+message)
+	d.logger.Error(dynamoDBOperationPersistenceError, "Operation persistence failed: "+message)
 	return dynamoDBOperationPersistenceError
 }
@@ -0,0 +1,178 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type dynamoDBOutboxPersistence struct {
+	logger   adapters.LoggerAdapter
+	dynamoDB adapters2.DynamoDBAdapter
+}
+
+// DynamoDBOutboxPersistence class constructor
+func DynamoDBOutboxPersistence(logger adapters.LoggerAdapter, dynamoDB adapters2.DynamoDBAdapter) *dynamoDBOutboxPersistence {
+	return &dynamoDBOutboxPersistence{
+		logger:   logger,
+		dynamoDB: dynamoDB,
+	}
+}
+
+// Save will persist model.OutboxEntry on outbox DynamoDB repository, entries are kept until they are repaired.
+func (d *dynamoDBOutboxPersistence) Save(ctx context.Context, entry *model.OutboxEntry) custom_error.BaseErrorAdapter {
+	d.logger.Info("Save outbox entry started", entry)
+
+	entryDto := dto.OutboxEntryDto(entry)
+	entryInput, err := attributevalue.MarshalMap(entryDto)
+	if err != nil {
+		return d.abort(err, "Error while trying to marshal outbox entry.")
+	}
+
+	_, err = d.dynamoDB.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: properties.Properties().Aws.DynamoDB.OutboxTableName,
+		Item:      entryInput,
+	})
+	if err != nil {
+		return d.abort(err, "Error while trying to save outbox entry.")
+	}
+
+	d.logger.Info("Save outbox entry finished", entry, entryDto)
+	return nil
+}
+
+// Get will find model.OutboxEntry on outbox DynamoDB repository using entryId as key. Returns nil entry if it does not
+// exist.
+func (d *dynamoDBOutboxPersistence) Get(ctx context.Context, entryId string) (*model.OutboxEntry, custom_error.BaseErrorAdapter) {
+	d.logger.Info("Get outbox entry started", entryId)
+
+	response, err := d.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
+		Key: map[string]types.AttributeValue{
+			"outbox_id": &types.AttributeValueMemberS{Value: entryId},
+		},
+		TableName: properties.Properties().Aws.DynamoDB.OutboxTableName,
+	})
+	if err != nil {
+		return nil, d.abort(err, "Error while trying to get outbox entry.")
+	}
+
+	if response.Item == nil {
+		d.logger.Info("Get outbox entry finished, entry not found", entryId)
+		return nil, nil
+	}
+
+	var entryDto *dto.OutboxEntry
+	err = attributevalue.UnmarshalMap(response.Item, &entryDto)
+	if err != nil {
+		return nil, d.abort(err, "Error while trying to unmarshal outbox entry.")
+	}
+
+	entry := entryDto.ToModel()
+
+	d.logger.Info("Get outbox entry finished", entryId, entry)
+	return entry, nil
+}
+
+// GetPending will find every pending model.OutboxEntry of the outbox_type.OutboxType on outbox DynamoDB repository,
+// the table is scanned page by page until there are no more items left.
+func (d *dynamoDBOutboxPersistence) GetPending(ctx context.Context, outboxType outbox_type.OutboxType) ([]*model.OutboxEntry, custom_error.BaseErrorAdapter) {
+	d.logger.Info("GetPending outbox entries started", outboxType)
+
+	var entries []*model.OutboxEntry
+	var lastEvaluatedKey map[string]types.AttributeValue
+	for {
+		response, err := d.dynamoDB.Scan(ctx, &dynamodb.ScanInput{
+			TableName:        properties.Properties().Aws.DynamoDB.OutboxTableName,
+			FilterExpression: aws.String("#status = :status AND #type = :type"),
+			ExpressionAttributeNames: map[string]string{
+				"#status": "status",
+				"#type":   "type",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":status": &types.AttributeValueMemberS{Value: outbox_status.Pending.Name()},
+				":type":   &types.AttributeValueMemberS{Value: outboxType.Name()},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		})
+		if err != nil {
+			return nil, d.abort(err, "Error while trying to scan outbox entries.")
+		}
+
+		var entriesDto []*dto.OutboxEntry
+		err = attributevalue.UnmarshalListOfMaps(response.Items, &entriesDto)
+		if err != nil {
+			return nil, d.abort(err, "Error while trying to unmarshal outbox entries.")
+		}
+
+		for _, entryDto := range entriesDto {
+			entries = append(entries, entryDto.ToModel())
+		}
+
+		if len(response.LastEvaluatedKey) == 0 {
+			break
+		}
+		lastEvaluatedKey = response.LastEvaluatedKey
+	}
+
+	d.logger.Info("GetPending outbox entries finished", outboxType, len(entries))
+	return entries, nil
+}
+
+// UpdateStatus will update model.OutboxEntry status on outbox DynamoDB repository. The update is conditioned on the
+// entry still being pending, so an entry already sent or discarded is never changed again.
+func (d *dynamoDBOutboxPersistence) UpdateStatus(ctx context.Context, entry *model.OutboxEntry, status outbox_status.OutboxStatus) custom_error.BaseErrorAdapter {
+	d.logger.Info("UpdateStatus outbox entry started", entry, status)
+
+	_, err := d.dynamoDB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		Key: map[string]types.AttributeValue{
+			"outbox_id": &types.AttributeValueMemberS{Value: entry.Id},
+		},
+		TableName:           properties.Properties().Aws.DynamoDB.OutboxTableName,
+		UpdateExpression:    aws.String("SET #status = :status"),
+		ConditionExpression: aws.String("#status = :pending"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status":  &types.AttributeValueMemberS{Value: status.Name()},
+			":pending": &types.AttributeValueMemberS{Value: outbox_status.Pending.Name()},
+		},
+	})
+	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return d.abortWithCode(err, "Outbox entry is not pending.", error_code.OutboxEntryModified)
+		}
+		return d.abort(err, "Error while trying to update outbox entry status.")
+	}
+
+	entry.Status = status
+
+	d.logger.Info("UpdateStatus outbox entry finished", entry, status)
+	return nil
+}
+
+func (d *dynamoDBOutboxPersistence) abort(err error, message string) custom_error.BaseErrorAdapter {
+	dynamoDBOutboxPersistenceError := exceptions.DynamoDBOutboxPersistenceError(err, message)
+	d.logger.Error(dynamoDBOutboxPersistenceError, "Outbox persistence failed: "+message)
+	return dynamoDBOutboxPersistenceError
+}
+
+func (d *dynamoDBOutboxPersistence) abortWithCode(err error, message string, code error_code.ErrorCode) custom_error.BaseErrorAdapter {
+	dynamoDBOutboxPersistenceError := d.abort(err, message)
+	dynamoDBOutboxPersistenceError.SetCode(code.Name())
+	return dynamoDBOutboxPersistenceError
+}
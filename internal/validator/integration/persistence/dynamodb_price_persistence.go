@@ -0,0 +1,64 @@
+package persistence
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type dynamoDBPricePersistence struct {
+	logger   adapters.LoggerAdapter
+	dynamoDB adapters2.DynamoDBAdapter
+}
+
+// DynamoDBPricePersistence class constructor, used as the recorded price feed of the paper exchange.
+func DynamoDBPricePersistence(logger adapters.LoggerAdapter, dynamoDB adapters2.DynamoDBAdapter) *dynamoDBPricePersistence {
+	return &dynamoDBPricePersistence{
+		logger:   logger,
+		dynamoDB: dynamoDB,
+	}
+}
+
+// GetCrypto will find the latest dto.Price recorded for the pair (symbol followed by quote, e.g. BTCBRL) on price
+// DynamoDB repository and return it as model.Coin.
+func (d *dynamoDBPricePersistence) GetCrypto(ctx context.Context, symbol symbol.Symbol, quote symbol.Symbol) (*model.Coin, custom_error.BaseErrorAdapter) {
+	d.logger.Info("Get price started", symbol, quote)
+
+	response, err := d.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
+		Key: map[string]types.AttributeValue{
+			"pair": &types.AttributeValueMemberS{Value: symbol.Name() + quote.Name()},
+		},
+		TableName: properties.Properties().Aws.DynamoDB.PriceTableName,
+	})
+	if err != nil {
+		return nil, d.abort(err, "Error while trying to get price.")
+	}
+
+	if response.Item == nil {
+		return nil, d.abort(err, "Price not recorded for "+symbol.Name()+quote.Name()+".")
+	}
+
+	var price *dto.Price
+	err = attributevalue.UnmarshalMap(response.Item, &price)
+	if err != nil {
+		return nil, d.abort(err, "Error while trying to unmarshal get price response.")
+	}
+
+	d.logger.Info("Get price finished", symbol, quote, price)
+	return price.ToModel(), nil
+}
+
+func (d *dynamoDBPricePersistence) abort(err error, message string) custom_error.BaseErrorAdapter {
+	dynamoDBPricePersistenceError := exceptions.DynamoDBPricePersistenceError(err, message)
+	d.logger.Error(dynamoDBPricePersistenceError, "Price persistence failed: "+message)
+	return dynamoDBPricePersistenceError
+}
@@ -0,0 +1,222 @@
+package persistence
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+// allowScript returns 1 if the circuit is closed, or half-open and the trial request was acquired (probe key set with
+// NX). Returns 0 while the circuit is open or another trial request is running.
+//
+// KEYS: open, halfOpen, probe. ARGV: probe TTL in milliseconds.
+var allowScript = redis.NewScript(`
+if redis.call("exists", KEYS[1]) == 1 then
+	return 0
+end
+if redis.call("exists", KEYS[2]) == 1 then
+	if redis.call("set", KEYS[3], "1", "px", ARGV[1], "nx") then
+		return 1
+	end
+	return 0
+end
+return 1
+`)
+
+// checkScript returns 1 if a request would be allowed, without acquiring the trial request. Returns 0 while the circuit
+// is open or half-open with a trial request already running.
+//
+// KEYS: open, halfOpen, probe.
+var checkScript = redis.NewScript(`
+if redis.call("exists", KEYS[1]) == 1 then
+	return 0
+end
+if redis.call("exists", KEYS[2]) == 1 and redis.call("exists", KEYS[3]) == 1 then
+	return 0
+end
+return 1
+`)
+
+// failureScript counts the failure on the window counter and opens the circuit when the threshold is reached, a failed
+// trial request opens the circuit again right away. The half-open state expires one open duration after the circuit
+// stops being open, so trial requests that never report back do not keep the circuit half-open. Returns 1 if the
+// circuit was opened.
+//
+// KEYS: open, halfOpen, probe, failures. ARGV: failure window, open duration, half-open duration (milliseconds) and
+// failure threshold.
+var failureScript = redis.NewScript(`
+if redis.call("exists", KEYS[2]) == 0 then
+	local failures = redis.call("incr", KEYS[4])
+	if failures == 1 then
+		redis.call("pexpire", KEYS[4], ARGV[1])
+	end
+	if failures < tonumber(ARGV[4]) then
+		return 0
+	end
+end
+redis.call("set", KEYS[1], "1", "px", ARGV[2])
+redis.call("set", KEYS[2], "1", "px", ARGV[3])
+redis.call("del", KEYS[3], KEYS[4])
+return 1
+`)
+
+type redisCircuitBreaker struct {
+	logger      adapters2.LoggerAdapter
+	redisClient adapters.RedisAdapter
+	name        string
+	keys        []string
+	threshold   int
+	window      int64
+	open        int64
+	probe       int64
+}
+
+// RedisCircuitBreaker constructor for class. The circuit state is kept on Redis under the breaker name, so it is shared
+// by every concurrent invocation. The half-open trial request lease lasts CIRCUIT_BREAKER_PROBE_TIMEOUT_SECONDS, never
+// less than the retry budget of the trial request, so it does not expire while the trial request is still running.
+func RedisCircuitBreaker(logger adapters2.LoggerAdapter, redisClient adapters.RedisAdapter, name string) *redisCircuitBreaker {
+	prefix := properties.Properties().Cache.KeyPrefix + "breaker." + name + "."
+
+	probe := properties.Properties().CircuitBreaker.ProbeTimeout
+	if budget := retryBudget(); probe < budget {
+		probe = budget
+	}
+
+	return &redisCircuitBreaker{
+		logger:      logger,
+		redisClient: redisClient,
+		name:        name,
+		keys:        []string{prefix + "open", prefix + "half_open", prefix + "probe", prefix + "failures"},
+		threshold:   properties.Properties().CircuitBreaker.FailureThreshold,
+		window:      properties.Properties().CircuitBreaker.FailureWindow.Milliseconds(),
+		open:        properties.Properties().CircuitBreaker.OpenDuration.Milliseconds(),
+		probe:       probe.Milliseconds(),
+	}
+}
+
+// retryBudget returns the longest a request can take through the retry client: EXCHANGE_RETRY_MAX_ATTEMPTS attempts
+// of HTTP_CLIENT_TIMEOUT_SECONDS each, with the longest backoff (EXCHANGE_RETRY_MAX_BACKOFF_MILLISECONDS plus its
+// jitter) between them.
+func retryBudget() time.Duration {
+	retry := properties.Properties().ExchangeRetry
+
+	attempts := retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := retry.MaxBackoff + time.Duration(retry.Jitter*float64(retry.MaxBackoff))
+
+	return time.Duration(attempts)*properties.Properties().HTTPClientTimeout + time.Duration(attempts-1)*backoff
+}
+
+// Allow checks the circuit state on Redis. The circuit opens for CIRCUIT_BREAKER_OPEN_SECONDS, after that it is
+// half-open and a single trial request is allowed until it succeeds, fails or its probe expires after
+// CIRCUIT_BREAKER_PROBE_TIMEOUT_SECONDS. Requests are allowed if Redis cannot be reached, the breaker only protects the
+// external service.
+func (r *redisCircuitBreaker) Allow(ctx context.Context) custom_error.BaseErrorAdapter {
+	redisClient, err := r.redisClient.Open(ctx)
+	if err != nil {
+		r.logger.Warning(err, "Could not open Redis connection, circuit breaker ignored", r.name)
+		return nil
+	}
+	defer r.close()
+
+	allowed, err := allowScript.Run(ctx, redisClient, r.keys[:3], r.probe).Int()
+	if err != nil {
+		r.logger.Warning(err, "Could not read circuit state, circuit breaker ignored", r.name)
+		return nil
+	}
+
+	if allowed == 0 {
+		r.logger.Info("Circuit is open, request not allowed", r.name)
+		return exceptions.CircuitBreakerError(nil, "Circuit "+r.name+" is open")
+	}
+
+	return nil
+}
+
+// Check reads the circuit state on Redis without acquiring the half-open trial request, so the request that follows can
+// still take it with Allow. Requests are allowed if Redis cannot be reached.
+func (r *redisCircuitBreaker) Check(ctx context.Context) custom_error.BaseErrorAdapter {
+	redisClient, err := r.redisClient.Open(ctx)
+	if err != nil {
+		r.logger.Warning(err, "Could not open Redis connection, circuit breaker ignored", r.name)
+		return nil
+	}
+	defer r.close()
+
+	allowed, err := checkScript.Run(ctx, redisClient, r.keys[:3]).Int()
+	if err != nil {
+		r.logger.Warning(err, "Could not read circuit state, circuit breaker ignored", r.name)
+		return nil
+	}
+
+	if allowed == 0 {
+		r.logger.Info("Circuit is open", r.name)
+		return exceptions.CircuitBreakerError(nil, "Circuit "+r.name+" is open")
+	}
+
+	return nil
+}
+
+// Success closes the circuit, clearing the half-open state. The failure counter is kept, failures expire with the
+// CIRCUIT_BREAKER_FAILURE_WINDOW_SECONDS window, so successes between intermittent failures do not reset the count.
+func (r *redisCircuitBreaker) Success(ctx context.Context) {
+	redisClient, err := r.redisClient.Open(ctx)
+	if err != nil {
+		r.logger.Warning(err, "Could not open Redis connection, success not recorded", r.name)
+		return
+	}
+	defer r.close()
+
+	if err := redisClient.Del(ctx, r.keys[1], r.keys[2]).Err(); err != nil {
+		r.logger.Warning(err, "Could not record circuit success", r.name)
+	}
+}
+
+// Release releases the half-open trial request without changing the circuit state, so the next request can be the
+// trial.
+func (r *redisCircuitBreaker) Release(ctx context.Context) {
+	redisClient, err := r.redisClient.Open(ctx)
+	if err != nil {
+		r.logger.Warning(err, "Could not open Redis connection, trial not released", r.name)
+		return
+	}
+	defer r.close()
+
+	if err := redisClient.Del(ctx, r.keys[2]).Err(); err != nil {
+		r.logger.Warning(err, "Could not release circuit trial", r.name)
+	}
+}
+
+// Failure counts the failure within CIRCUIT_BREAKER_FAILURE_WINDOW_SECONDS and opens the circuit when
+// CIRCUIT_BREAKER_FAILURE_THRESHOLD is reached. A failed trial request opens the circuit again.
+func (r *redisCircuitBreaker) Failure(ctx context.Context) {
+	redisClient, err := r.redisClient.Open(ctx)
+	if err != nil {
+		r.logger.Warning(err, "Could not open Redis connection, failure not recorded", r.name)
+		return
+	}
+	defer r.close()
+
+	opened, err := failureScript.Run(ctx, redisClient, r.keys, r.window, r.open, 2*r.open, r.threshold).Int()
+	if err != nil {
+		r.logger.Warning(err, "Could not record circuit failure", r.name)
+		return
+	}
+
+	if opened == 1 {
+		r.logger.Warning(nil, "Circuit opened", r.name)
+	}
+}
+
+func (r *redisCircuitBreaker) close() {
+	if err := r.redisClient.Close(); err != nil {
+		r.logger.Warning(err, "Could not close Redis connection")
+	}
+}
@@ -8,15 +8,40 @@ import (
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"sync"
 	"time"
 )
 
+// releaseScript deletes the key only if it still holds the owner token.
+var releaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// extendScript renews the key TTL only if it still holds the owner token.
+var extendScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
 type redisPersistence struct {
 	logger      adapters2.LoggerAdapter
 	redisClient adapters.RedisAdapter
-	ctx         context.Context
 	prefix      string
 	keyTTL      time.Duration
+	mutex       sync.Mutex
+	tokens      map[string]string
+	watchdogs   map[string]*watchdog
+}
+
+type watchdog struct {
+	stop chan struct{}
+	done chan struct{}
 }
 
 // RedisPersistence constructor for class.
@@ -24,33 +49,36 @@ func RedisPersistence(logger adapters2.LoggerAdapter, redisClient adapters.Redis
 	return &redisPersistence{
 		logger:      logger,
 		redisClient: redisClient,
-		ctx:         context.Background(),
 		prefix:      properties.Properties().Cache.KeyPrefix,
 		keyTTL:      properties.Properties().Cache.KeyTTL,
+		tokens:      map[string]string{},
+		watchdogs:   map[string]*watchdog{},
 	}
 }
 
-// Lock will set the key on cache with TTL active. Returns error if a problem occurs while trying to persist on cache.
-func (r *redisPersistence) Lock(key string) custom_error.BaseErrorAdapter {
+// Lock will atomically set the key on cache (SET NX PX) with an owner token and TTL active. Returns error if key is
+// already locked or if a problem occurs while trying to persist on cache.
+func (r *redisPersistence) Lock(ctx context.Context, key string) custom_error.BaseErrorAdapter {
 	r.logger.Info("Lock started", key)
 
-	redisClient, err := r.redisClient.Open()
+	redisClient, err := r.redisClient.Open(ctx)
 	if err != nil {
-		return r.abort(err, "Error while trying to open redis connection", false, false)
+		return r.abort("Lock", err, "Error while trying to open redis connection", false, false)
 	}
 
-	_, err = redisClient.Get(r.ctx, r.prefix+key).Result()
-	if err == nil {
-		return r.abort(err, "Key is already locked", false, true)
-	} else if err != nil && err != redis.Nil {
-		return r.abort(err, "Error while trying to get redis key", false, true)
-	}
+	token := uuid.NewString()
 
-	_, err = redisClient.Set(r.ctx, r.prefix+key, key, r.keyTTL).Result()
-	if err != nil {
-		return r.abort(err, "Error while trying to set redis key", false, true)
+	_, err = redisClient.Do(ctx, "set", r.prefix+key, token, "px", r.keyTTL.Milliseconds(), "nx").Result()
+	if err == redis.Nil {
+		return r.abort("Lock", nil, "Key is already locked", false, true)
+	} else if err != nil {
+		return r.abort("Lock", err, "Error while trying to set redis key", false, true)
 	}
 
+	r.mutex.Lock()
+	r.tokens[key] = token
+	r.mutex.Unlock()
+
 	err = r.redisClient.Close()
 	if err != nil {
 		r.logger.Warning(err, "Could not close Redis connection")
@@ -60,20 +88,41 @@ func (r *redisPersistence) Lock(key string) custom_error.BaseErrorAdapter {
 	return nil
 }
 
-// Unlock will remove the key from cache. Returns error if a problem occurs while trying to delete from cache.
-func (r *redisPersistence) Unlock(key string) custom_error.BaseErrorAdapter {
+// Unlock will stop the key watchdog and remove the key from cache, the key is only removed if it still holds the owner
+// token set on Lock. Returns error if a problem occurs while trying to delete from cache, the owner token is kept so
+// the release can be retried.
+func (r *redisPersistence) Unlock(ctx context.Context, key string) custom_error.BaseErrorAdapter {
 	r.logger.Info("Unlock started", key)
 
-	redisClient, err := r.redisClient.Open()
+	r.stopWatchdog(key)
+
+	r.mutex.Lock()
+	token, owned := r.tokens[key]
+	r.mutex.Unlock()
+
+	if !owned {
+		r.logger.Info("Unlock finished, key not locked by this process", key)
+		return nil
+	}
+
+	redisClient, err := r.redisClient.Open(ctx)
 	if err != nil {
-		return r.abort(err, "Error while trying to open redis connection", true, false)
+		return r.abort("Unlock", err, "Error while trying to open redis connection", true, false)
 	}
 
-	_, err = redisClient.Del(r.ctx, r.prefix+key).Result()
+	released, err := releaseScript.Run(ctx, redisClient, []string{r.prefix + key}, token).Int()
 	if err != nil {
-		return r.abort(err, "Error while trying to delete redis key", true, true)
+		return r.abort("Unlock", err, "Error while trying to delete redis key", true, true)
+	}
+
+	if released == 0 {
+		r.logger.Warning(nil, "Key lock expired before release", key)
 	}
 
+	r.mutex.Lock()
+	delete(r.tokens, key)
+	r.mutex.Unlock()
+
 	err = r.redisClient.Close()
 	if err != nil {
 		r.logger.Warning(err, "Could not close Redis connection")
@@ -83,15 +132,128 @@ func (r *redisPersistence) Unlock(key string) custom_error.BaseErrorAdapter {
 	return nil
 }
 
-func (r *redisPersistence) abort(err error, message string, locked bool, closeConn bool) custom_error.BaseErrorAdapter {
+// Extend will renew the key TTL on cache if it still holds the owner token set on Lock. Returns error if key is not
+// owned anymore or if a problem occurs while trying to update the cache.
+func (r *redisPersistence) Extend(ctx context.Context, key string) custom_error.BaseErrorAdapter {
+	r.logger.Info("Extend started", key)
+
+	r.mutex.Lock()
+	token, owned := r.tokens[key]
+	r.mutex.Unlock()
+
+	if !owned {
+		return r.abort("Extend", nil, "Key is not locked by this process", false, false)
+	}
+
+	redisClient, err := r.redisClient.Open(ctx)
+	if err != nil {
+		return r.abort("Extend", err, "Error while trying to open redis connection", true, false)
+	}
+
+	extended, err := extendScript.Run(ctx, redisClient, []string{r.prefix + key}, token, r.keyTTL.Milliseconds()).Int()
+	if err != nil {
+		return r.abort("Extend", err, "Error while trying to extend redis key", true, true)
+	}
+
+	if extended == 0 {
+		return r.abort("Extend", nil, "Key lock expired before extension", false, true)
+	}
+
+	err = r.redisClient.Close()
+	if err != nil {
+		r.logger.Warning(err, "Could not close Redis connection")
+	}
+
+	r.logger.Info("Extend finished", key)
+	return nil
+}
+
+// Exists will check if the key is set on cache, locked by this or by any other process. Returns error if a problem
+// occurs while trying to read from cache.
+func (r *redisPersistence) Exists(ctx context.Context, key string) (bool, custom_error.BaseErrorAdapter) {
+	r.logger.Info("Exists started", key)
+
+	redisClient, err := r.redisClient.Open(ctx)
+	if err != nil {
+		return false, r.abort("Exists", err, "Error while trying to open redis connection", false, false)
+	}
+
+	count, err := redisClient.Exists(ctx, r.prefix+key).Result()
+	if err != nil {
+		return false, r.abort("Exists", err, "Error while trying to read redis key", false, true)
+	}
+
+	err = r.redisClient.Close()
+	if err != nil {
+		r.logger.Warning(err, "Could not close Redis connection")
+	}
+
+	r.logger.Info("Exists finished", key, count > 0)
+	return count > 0, nil
+}
+
+// Watchdog will extend the key TTL on a third of the TTL interval until key is unlocked or ctx is done, used for
+// validations that can last longer than the key TTL.
+func (r *redisPersistence) Watchdog(ctx context.Context, key string) {
+	if r.keyTTL <= 0 {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, running := r.watchdogs[key]; running {
+		return
+	}
+
+	w := &watchdog{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	r.watchdogs[key] = w
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(r.keyTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.Extend(ctx, key); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (r *redisPersistence) stopWatchdog(key string) {
+	r.mutex.Lock()
+	w, running := r.watchdogs[key]
+	delete(r.watchdogs, key)
+	r.mutex.Unlock()
+
+	if running {
+		close(w.stop)
+		<-w.done
+	}
+}
+
+func (r *redisPersistence) abort(operation string, err error, message string, locked bool, closeConn bool) custom_error.BaseErrorAdapter {
 	if closeConn {
 		closeErr := r.redisClient.Close()
 		if closeErr != nil {
-			r.logger.Warning(err, "Could not close Redis connection")
+			r.logger.Warning(closeErr, "Could not close Redis connection")
 		}
 	}
 
 	redisPersistenceLockError := exceptions.RedisPersistenceLockError(err, message, locked)
-	r.logger.Error(redisPersistenceLockError, "Unlock failed: "+message)
+	r.logger.Error(redisPersistenceLockError, operation+" failed: "+message)
 	return redisPersistenceLockError
 }
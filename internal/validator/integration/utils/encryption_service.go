@@ -94,6 +94,19 @@ func (e *encryptionService) SHA384Encrypt(string string, secret string) string {
 	return hex.EncodeToString(digester.Sum(nil))
 }
 
+// SHA256Encrypt returns the hex encoded HMAC-SHA256 of string signed with secret, the string is signed as is (used by
+// Binance signed endpoints).
+func (e *encryptionService) SHA256Encrypt(string string, secret string) string {
+	e.logger.Info("SHA256Encrypt started")
+
+	digester := hmac.New(crypto.SHA256.New, []byte(secret))
+
+	digester.Write([]byte(string))
+
+	e.logger.Info("SHA256Encrypt finished")
+	return hex.EncodeToString(digester.Sum(nil))
+}
+
 func (e *encryptionService) abort(err error, message string, metadata ...interface{}) custom_error.BaseErrorAdapter {
 	encryptionServiceError := exceptions.EncryptionServiceError(err, message)
 	e.logger.Error(encryptionServiceError, "Encryption service failed: "+message, metadata)
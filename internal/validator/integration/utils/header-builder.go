@@ -1,14 +1,17 @@
 package utils
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
-	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
 	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
 )
 
 type headerBuilder struct {
@@ -17,6 +20,7 @@ type headerBuilder struct {
 	secretsManagerService  adapters.SecretsManagerServiceAdapter
 	encryptionService      adapters.EncryptionServiceAdapter
 	tokenBuilder           adapters.TokenBuilderAdapter
+	lastNonce              int64
 }
 
 func HeaderBuilder(
@@ -35,27 +39,18 @@ func HeaderBuilder(
 	}
 }
 
-func (h *headerBuilder) BiscointHeader(clientId string, endpoint string, payload any) (http.Header, custom_error.BaseErrorAdapter) {
+// BiscointHeader signs the request with the client api secret. The nonce is the unix time in milliseconds and strictly
+// increases on every call, so requests signed again on retry never repeat a nonce.
+func (h *headerBuilder) BiscointHeader(ctx context.Context, clientId string, endpoint string, payload any) (http.Header, custom_error.BaseErrorAdapter) {
 	h.logger.Info("BiscointHeader started", clientId, endpoint, payload)
 
-	credentials, err := h.credentialsPersistence.GetCredentials(clientId)
+	credentials, apiSecret, err := h.credentials(ctx, clientId)
 	if err != nil {
-		return nil, h.abort(err, "Error while getting client credentials")
+		return nil, err
 	}
 
-	encryptionSecrets := &dto.EncryptionSecrets{}
-	err = h.secretsManagerService.GetSecret(properties.Properties().Aws.SecretsManager.EncryptionSecretName, encryptionSecrets)
-	if err != nil {
-		return nil, h.abort(err, "Error while getting encryption key")
-	}
-
-	decryptedSecret, err := h.encryptionService.AESDecrypt(credentials.ApiSecret, encryptionSecrets.EncryptionKey)
-	if err != nil {
-		return nil, h.abort(err, "Error while trying to decrypt secret")
-	}
-
-	nonce := time_utils.Epoch()
-	token, err := h.tokenBuilder.Build(decryptedSecret, endpoint, payload, nonce)
+	nonce := h.nonce()
+	token, err := h.tokenBuilder.Build(apiSecret, endpoint, payload, nonce)
 	if err != nil {
 		return nil, h.abort(err, "Error while trying to generate token")
 	}
@@ -70,6 +65,60 @@ func (h *headerBuilder) BiscointHeader(clientId string, endpoint string, payload
 	return headers, nil
 }
 
+// BinanceHeader signs the query string with the client api secret (HMAC-SHA256). Returns the api key header and the
+// signed query string, the signature is appended as the last parameter.
+func (h *headerBuilder) BinanceHeader(ctx context.Context, clientId string, query string) (http.Header, string, custom_error.BaseErrorAdapter) {
+	h.logger.Info("BinanceHeader started", clientId, query)
+
+	credentials, apiSecret, err := h.credentials(ctx, clientId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	signature := h.encryptionService.SHA256Encrypt(query, apiSecret)
+
+	headers := http.Header{}
+	headers.Set("X-MBX-APIKEY", credentials.ApiKey)
+
+	h.logger.Info("BinanceHeader finished", clientId, query)
+	return headers, query + "&signature=" + signature, nil
+}
+
+// nonce returns the current unix time in milliseconds, or the last nonce plus one if the time did not move forward.
+func (h *headerBuilder) nonce() string {
+	for {
+		last := atomic.LoadInt64(&h.lastNonce)
+		nonce := time.Now().UnixMilli()
+		if nonce <= last {
+			nonce = last + 1
+		}
+		if atomic.CompareAndSwapInt64(&h.lastNonce, last, nonce) {
+			return strconv.FormatInt(nonce, 10)
+		}
+	}
+}
+
+// credentials returns the client credentials and its decrypted api secret.
+func (h *headerBuilder) credentials(ctx context.Context, clientId string) (*dto.Credentials, string, custom_error.BaseErrorAdapter) {
+	credentials, err := h.credentialsPersistence.GetCredentials(ctx, clientId)
+	if err != nil {
+		return nil, "", h.abort(err, "Error while getting client credentials")
+	}
+
+	encryptionSecrets := &dto.EncryptionSecrets{}
+	err = h.secretsManagerService.GetSecret(ctx, properties.Properties().Aws.SecretsManager.EncryptionSecretName, encryptionSecrets)
+	if err != nil {
+		return nil, "", h.abort(err, "Error while getting encryption key")
+	}
+
+	apiSecret, err := h.encryptionService.AESDecrypt(credentials.ApiSecret, encryptionSecrets.EncryptionKey)
+	if err != nil {
+		return nil, "", h.abort(err, "Error while trying to decrypt secret")
+	}
+
+	return credentials, apiSecret, nil
+}
+
 func (h *headerBuilder) abort(err error, message string, metadata ...interface{}) custom_error.BaseErrorAdapter {
 	headerBuilderError := exceptions.HeaderBuilderError(err, message)
 	h.logger.Error(headerBuilderError, "Header builder failed: "+message, metadata)
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type retryClient struct {
+	logger               adapters2.LoggerAdapter
+	client               adapters.HTTPClientAdapter
+	maxAttempts          int
+	baseBackoff          time.Duration
+	maxBackoff           time.Duration
+	jitter               float64
+	retryableStatusCodes map[int]bool
+}
+
+// RetryClient wraps an adapters.HTTPClientAdapter retrying transport errors and EXCHANGE_RETRY_STATUS_CODES responses
+// with the policy configured on EXCHANGE_RETRY_* env variables, shared by every exchange web service.
+func RetryClient(logger adapters2.LoggerAdapter, client adapters.HTTPClientAdapter) *retryClient {
+	retryableStatusCodes := map[int]bool{}
+	for _, statusCode := range properties.Properties().ExchangeRetry.RetryableStatusCodes {
+		retryableStatusCodes[statusCode] = true
+	}
+
+	return &retryClient{
+		logger:               logger,
+		client:               client,
+		maxAttempts:          properties.Properties().ExchangeRetry.MaxAttempts,
+		baseBackoff:          properties.Properties().ExchangeRetry.BaseBackoff,
+		maxBackoff:           properties.Properties().ExchangeRetry.MaxBackoff,
+		jitter:               properties.Properties().ExchangeRetry.Jitter,
+		retryableStatusCodes: retryableStatusCodes,
+	}
+}
+
+// Do sends the request up to EXCHANGE_RETRY_MAX_ATTEMPTS times. Sign (optional) is called before every attempt so
+// signed requests get a new nonce and signature, its error is returned without sending the request. Attempts wait an
+// exponential backoff (with jitter) or the Retry-After response header when present. The last response or error is
+// returned when attempts are exhausted, Retry-After asks for longer than EXCHANGE_RETRY_MAX_BACKOFF_MILLISECONDS, the
+// wait would exceed the request context deadline or the context is done.
+func (r *retryClient) Do(request *http.Request, sign func(request *http.Request) error) (*http.Response, error) {
+	ctx := request.Context()
+
+	for attempt := 1; ; attempt++ {
+		if sign != nil {
+			if err := sign(request); err != nil {
+				return nil, err
+			}
+		}
+
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request.Body = body
+		}
+
+		response, err := r.client.Do(request)
+		if !r.retryable(ctx, response, err) || attempt >= r.maxAttempts {
+			return response, err
+		}
+
+		delay, ok := r.backoff(attempt, response)
+		if !ok {
+			return response, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return response, err
+		}
+
+		r.logger.Warning(retryError(response, err), "Retrying request", request.Method, request.URL.Path, attempt, delay)
+		if response != nil {
+			_, _ = io.Copy(io.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *retryClient) retryable(ctx context.Context, response *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+
+	return r.retryableStatusCodes[response.StatusCode]
+}
+
+// backoff returns the Retry-After header value when present, otherwise EXCHANGE_RETRY_BASE_BACKOFF_MILLISECONDS
+// doubled on each attempt (limited by EXCHANGE_RETRY_MAX_BACKOFF_MILLISECONDS) plus up to EXCHANGE_RETRY_JITTER of it.
+// Jitter is added on top of the backoff, so the delay is never shorter than the configured backoff. Returns false if
+// Retry-After is longer than EXCHANGE_RETRY_MAX_BACKOFF_MILLISECONDS, the request is not retried.
+func (r *retryClient) backoff(attempt int, response *http.Response) (time.Duration, bool) {
+	if response != nil {
+		if delay, ok := retryAfter(response.Header.Get("Retry-After")); ok {
+			return delay, delay <= r.maxBackoff
+		}
+	}
+
+	delay := r.baseBackoff
+	for i := 1; i < attempt && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.maxBackoff {
+		delay = r.maxBackoff
+	}
+
+	return delay + time.Duration(rand.Float64()*r.jitter*float64(delay)), true
+}
+
+// retryAfter parses the Retry-After header, sent either as seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+func retryError(response *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+
+	return errors.New("status code not Ok: " + response.Status)
+}
@@ -0,0 +1,162 @@
+package webservice
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type binanceWebService struct {
+	logger                adapters.LoggerAdapter
+	client                adapters2.RetryClientAdapter
+	headerBuilder         adapters2.HeaderBuilderAdapter
+	binanceUrl            string
+	binanceGetCryptoPath  string
+	binanceGetBalancePath string
+	recvWindow            string
+}
+
+// BinanceWebService class constructor.
+func BinanceWebService(logger adapters.LoggerAdapter, client adapters2.RetryClientAdapter, headerBuilder adapters2.HeaderBuilderAdapter) *binanceWebService {
+	return &binanceWebService{
+		logger:                logger,
+		client:                client,
+		headerBuilder:         headerBuilder,
+		binanceUrl:            properties.Properties().BinanceUrl,
+		binanceGetCryptoPath:  properties.Properties().BinanceGetCryptoPath,
+		binanceGetBalancePath: properties.Properties().BinanceGetBalancePath,
+		recvWindow:            strconv.FormatInt(properties.Properties().BinanceRecvWindow.Milliseconds(), 10),
+	}
+}
+
+const binanceSymbolKey = "symbol"
+const binanceRecvWindowKey = "recvWindow"
+const binanceTimestampKey = "timestamp"
+
+// GetCrypto finds and return a model.Coin object containing values to buy (ask) and sell (bid) a crypto coin based on
+// symbol and quote (symbol.Symbol), using the Binance book ticker of the pair. The book ticker carries no timestamp,
+// the response Date header is used as the quote time.
+func (b *binanceWebService) GetCrypto(ctx context.Context, symbol symbol.Symbol, quote symbol.Symbol) (*model.Coin, custom_error.BaseErrorAdapter) {
+	b.logger.Info("Get crypto start", symbol, quote)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, b.binanceUrl+b.binanceGetCryptoPath, nil)
+	if err != nil {
+		return nil, b.abort(err, "Error while trying to generate Binance get request")
+	}
+
+	query := url.Values{}
+	query.Add(binanceSymbolKey, symbol.Name()+quote.Name())
+	request.URL.RawQuery = query.Encode()
+	request.Header = publicHeader()
+
+	response, err := b.client.Do(request, nil)
+	if err != nil {
+		return nil, b.abortRequest(ctx, err, "Error while trying to get crypto value from Binance")
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return nil, b.abortStatus(response, "Binance API status code not Ok: "+response.Status)
+	}
+
+	var binanceCoin dto.BinanceCoin
+	if err := json.NewDecoder(response.Body).Decode(&binanceCoin); err != nil {
+		return nil, b.abort(err, "Error while trying to decode Binance book ticker API response")
+	}
+
+	coin, err := binanceCoin.ToModel(symbol, quote, responseTime(response))
+	if err != nil {
+		return nil, b.abort(err, "Could not convert Binance book ticker response to model")
+	}
+
+	b.logger.Info("Get crypto finish", symbol, quote, coin)
+	return coin, nil
+}
+
+// GetBalance will search for client balance on external service. ClientId is used to get the apiKey in credentials DB.
+// The query is signed again on every retry attempt so each one carries a new timestamp.
+func (b *binanceWebService) GetBalance(ctx context.Context, clientId string) (*model.Balance, custom_error.BaseErrorAdapter) {
+	b.logger.Info("Get balance start", clientId)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, b.binanceUrl+b.binanceGetBalancePath, nil)
+	if err != nil {
+		return nil, b.abort(err, "Error while trying to generate Binance get request")
+	}
+
+	var headerErr custom_error.BaseErrorAdapter
+	response, err := b.client.Do(request, func(request *http.Request) error {
+		query := url.Values{}
+		query.Add(binanceRecvWindowKey, b.recvWindow)
+		query.Add(binanceTimestampKey, time_utils.EpochMilli())
+
+		request.Header, request.URL.RawQuery, headerErr = b.headerBuilder.BinanceHeader(ctx, clientId, query.Encode())
+		return headerErr
+	})
+	if headerErr != nil {
+		return nil, b.abort(headerErr, "Error while trying to sign Binance request")
+	}
+	if err != nil {
+		return nil, b.abortRequest(ctx, err, "Error while trying to get balance from Binance")
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return nil, b.abortStatus(response, "Binance API status code not Ok: "+response.Status)
+	}
+
+	var balanceResponse dto.BinanceBalanceResponse
+	if err := json.NewDecoder(response.Body).Decode(&balanceResponse); err != nil {
+		return nil, b.abort(err, "Error while trying to decode Binance account API response")
+	}
+
+	balance, err := balanceResponse.ToModel()
+	if err != nil {
+		return nil, b.abort(err, "Could not convert Binance account response to model")
+	}
+
+	b.logger.Info("Get balance finish", clientId, balance)
+	return balance, nil
+}
+
+// abortRequest flags transport errors with error_code.ServiceUnavailable, unless ctx was cancelled or reached its
+// deadline.
+func (b *binanceWebService) abortRequest(ctx context.Context, err error, message string, metadata ...interface{}) custom_error.BaseErrorAdapter {
+	binanceWebServiceError := b.abort(err, message, metadata...)
+	if unavailableError(ctx) {
+		binanceWebServiceError.SetCode(error_code.ServiceUnavailable.Name())
+	}
+	return binanceWebServiceError
+}
+
+// abortStatus returns the response status and body as the error, 418, 429 and 5xx responses are flagged with
+// error_code.ServiceUnavailable.
+func (b *binanceWebService) abortStatus(response *http.Response, message string) custom_error.BaseErrorAdapter {
+	binanceWebServiceError := b.abort(responseError(response), message)
+	if unavailableStatus(response.StatusCode) {
+		binanceWebServiceError.SetCode(error_code.ServiceUnavailable.Name())
+	}
+	return binanceWebServiceError
+}
+
+func (b *binanceWebService) abort(err error, message string, metadata ...interface{}) custom_error.BaseErrorAdapter {
+	binanceWebServiceError := exceptions.BinanceWebServiceError(err, message)
+	b.logger.Error(binanceWebServiceError, "Binance API failed: "+message, metadata)
+	return binanceWebServiceError
+}
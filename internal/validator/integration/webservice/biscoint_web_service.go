@@ -1,16 +1,17 @@
 package webservice
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
-	"github.com/brienze1/crypto-robot-validator/pkg/log"
 	"io"
 	"net/http"
 	"net/url"
@@ -18,22 +19,20 @@ import (
 
 type biscointWebService struct {
 	logger                 adapters.LoggerAdapter
-	client                 adapters2.HTTPClientAdapter
+	client                 adapters2.RetryClientAdapter
 	headerBuilder          adapters2.HeaderBuilderAdapter
 	biscointUrl            string
-	simulationUrl          string
 	biscointGetCryptoPath  string
 	biscointGetBalancePath string
 }
 
 // BiscointWebService class constructor.
-func BiscointWebService(logger adapters.LoggerAdapter, client adapters2.HTTPClientAdapter, headerBuilder adapters2.HeaderBuilderAdapter) *biscointWebService {
+func BiscointWebService(logger adapters.LoggerAdapter, client adapters2.RetryClientAdapter, headerBuilder adapters2.HeaderBuilderAdapter) *biscointWebService {
 	return &biscointWebService{
 		logger:                 logger,
 		client:                 client,
 		headerBuilder:          headerBuilder,
 		biscointUrl:            properties.Properties().BiscointUrl,
-		simulationUrl:          properties.Properties().SimulationUrl,
 		biscointGetCryptoPath:  properties.Properties().BiscointGetCryptoPath,
 		biscointGetBalancePath: properties.Properties().BiscointGetBalancePath,
 	}
@@ -42,35 +41,32 @@ func BiscointWebService(logger adapters.LoggerAdapter, client adapters2.HTTPClie
 const symbolKey = "symbol"
 const quoteKey = "quote"
 
-// TODO missing header generation
-
 // GetCrypto finds and return a model.Coin object containing values to buy and sell a crypto coin based on symbol
-// and quote (symbol.Symbol).
-func (b *biscointWebService) GetCrypto(symbol symbol.Symbol, quote symbol.Symbol) (*model.Coin, custom_error.BaseErrorAdapter) {
-	b.logger.Info("Get crypto start", symbol, quoteKey)
+// and quote (symbol.Symbol). The ticker is a public endpoint, so the request is not signed.
+func (b *biscointWebService) GetCrypto(ctx context.Context, symbol symbol.Symbol, quote symbol.Symbol) (*model.Coin, custom_error.BaseErrorAdapter) {
+	b.logger.Info("Get crypto start", symbol, quote)
 
-	request, err := http.NewRequest(http.MethodGet, b.biscointUrl+b.biscointGetCryptoPath, nil)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, b.biscointUrl+b.biscointGetCryptoPath, nil)
 	if err != nil {
 		return nil, b.abort(err, "Error while trying to generate Biscoint get request")
 	}
 
-	log.Logger().Info("request", request)
-
 	query := url.Values{}
 	query.Add(symbolKey, symbol.Name())
 	query.Add(quoteKey, quote.Name())
 	request.URL.RawQuery = query.Encode()
+	request.Header = publicHeader()
 
-	response, err := b.client.Do(request)
+	response, err := b.client.Do(request, nil)
 	if err != nil {
-		return nil, b.abort(err, "Error while trying to get crypto value from Biscoint")
+		return nil, b.abortRequest(ctx, err, "Error while trying to get crypto value from Biscoint")
 	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(response.Body)
 
 	if response.StatusCode != http.StatusOK {
-		return nil, b.abort(err, "Biscoint API status code not Ok: "+response.Status)
+		return nil, b.abortStatus(response, "Biscoint API status code not Ok: "+response.Status)
 	}
 
 	var coinResponse dto.CoinResponse
@@ -85,33 +81,32 @@ func (b *biscointWebService) GetCrypto(symbol symbol.Symbol, quote symbol.Symbol
 }
 
 // GetBalance will search for client balance on external service. ClientId is used to get the apiKey in credentials DB.
-func (b *biscointWebService) GetBalance(clientId string, useSimulation bool) (*model.Balance, custom_error.BaseErrorAdapter) {
+// Headers are built again on every retry attempt so each one is signed with a new nonce.
+func (b *biscointWebService) GetBalance(ctx context.Context, clientId string) (*model.Balance, custom_error.BaseErrorAdapter) {
 	b.logger.Info("Get balance start", clientId, quoteKey)
 
-	biscointUrl := b.biscointUrl
-	if useSimulation {
-		biscointUrl = b.simulationUrl
-	}
-	request, err := http.NewRequest(http.MethodPost, biscointUrl+b.biscointGetBalancePath, nil)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, b.biscointUrl+b.biscointGetBalancePath, nil)
 	if err != nil {
 		return nil, b.abort(err, "Error while trying to generate Biscoint get request")
 	}
 
-	request.Header, err = b.headerBuilder.BiscointHeader(clientId, b.biscointGetBalancePath, `{}`)
-	if err != nil {
-		return nil, b.abort(err, "Error while trying to generate Biscoint header")
+	var headerErr custom_error.BaseErrorAdapter
+	response, err := b.client.Do(request, func(request *http.Request) error {
+		request.Header, headerErr = b.headerBuilder.BiscointHeader(ctx, clientId, b.biscointGetBalancePath, `{}`)
+		return headerErr
+	})
+	if headerErr != nil {
+		return nil, b.abort(headerErr, "Error while trying to generate Biscoint header")
 	}
-
-	response, err := b.client.Do(request)
 	if err != nil {
-		return nil, b.abort(err, "Error while trying to get balance from Biscoint")
+		return nil, b.abortRequest(ctx, err, "Error while trying to get balance from Biscoint")
 	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(response.Body)
 
 	if response.StatusCode != http.StatusOK {
-		return nil, b.abort(err, "Biscoint API status code not Ok: "+response.Status, response.Body)
+		return nil, b.abortStatus(response, "Biscoint API status code not Ok: "+response.Status)
 	}
 
 	var balanceResponse dto.BalanceResponse
@@ -128,6 +123,33 @@ func (b *biscointWebService) GetBalance(clientId string, useSimulation bool) (*m
 	return balance, nil
 }
 
+// abortRequest flags transport errors with error_code.ServiceUnavailable, unless ctx was cancelled or reached its
+// deadline.
+func (b *biscointWebService) abortRequest(ctx context.Context, err error, message string, metadata ...interface{}) custom_error.BaseErrorAdapter {
+	biscointWebServiceError := b.abort(err, message, metadata...)
+	if unavailableError(ctx) {
+		biscointWebServiceError.SetCode(error_code.ServiceUnavailable.Name())
+	}
+	return biscointWebServiceError
+}
+
+// abortStatus returns the response status and body as the error, 429 and 5xx responses are flagged with
+// error_code.ServiceUnavailable.
+func (b *biscointWebService) abortStatus(response *http.Response, message string) custom_error.BaseErrorAdapter {
+	biscointWebServiceError := b.abort(responseError(response), message)
+	if unavailableStatus(response.StatusCode) {
+		biscointWebServiceError.SetCode(error_code.ServiceUnavailable.Name())
+	}
+	return biscointWebServiceError
+}
+
+// publicHeader returns the headers sent on Biscoint public (not signed) requests.
+func publicHeader() http.Header {
+	headers := http.Header{}
+	headers.Set("Accept", "application/json")
+	return headers
+}
+
 func (b *biscointWebService) abort(err error, message string, metadata ...interface{}) custom_error.BaseErrorAdapter {
 	biscointWebServiceError := exceptions.BiscointWebServiceError(err, message)
 	b.logger.Error(biscointWebServiceError, "Biscoint API failed: "+message, metadata)
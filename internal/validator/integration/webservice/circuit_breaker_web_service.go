@@ -0,0 +1,60 @@
+package webservice
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type circuitBreakerWebService struct {
+	breaker adapters.CircuitBreakerAdapter
+	service adapters.CryptoServiceAdapter
+}
+
+// CircuitBreakerWebService wraps a crypto and client service with a circuit breaker. Requests fail fast while the
+// circuit is open, errors flagged with error_code.ServiceUnavailable count as circuit failures.
+func CircuitBreakerWebService(breaker adapters.CircuitBreakerAdapter, service adapters.CryptoServiceAdapter) *circuitBreakerWebService {
+	return &circuitBreakerWebService{
+		breaker: breaker,
+		service: service,
+	}
+}
+
+// GetCrypto calls the wrapped service GetCrypto if the circuit allows it.
+func (c *circuitBreakerWebService) GetCrypto(ctx context.Context, symbol symbol.Symbol, quote symbol.Symbol) (*model.Coin, custom_error.BaseErrorAdapter) {
+	if err := c.breaker.Allow(ctx); err != nil {
+		return nil, err
+	}
+
+	coin, err := c.service.GetCrypto(ctx, symbol, quote)
+	c.record(ctx, err)
+
+	return coin, err
+}
+
+// GetBalance calls the wrapped service GetBalance if the circuit allows it.
+func (c *circuitBreakerWebService) GetBalance(ctx context.Context, clientId string) (*model.Balance, custom_error.BaseErrorAdapter) {
+	if err := c.breaker.Allow(ctx); err != nil {
+		return nil, err
+	}
+
+	balance, err := c.service.GetBalance(ctx, clientId)
+	c.record(ctx, err)
+
+	return balance, err
+}
+
+// record counts unavailability errors as failures and successful requests as successes, other errors (invalid
+// requests, credentials or responses) do not change the circuit state and only release the half-open trial.
+func (c *circuitBreakerWebService) record(ctx context.Context, err custom_error.BaseErrorAdapter) {
+	if err == nil {
+		c.breaker.Success(ctx)
+	} else if err.Code() == error_code.ServiceUnavailable.Name() {
+		c.breaker.Failure(ctx)
+	} else {
+		c.breaker.Release(ctx)
+	}
+}
@@ -0,0 +1,45 @@
+package webservice
+
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/exchange"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type registeredExchange struct {
+	service adapters.CryptoServiceAdapter
+	breaker adapters.CircuitBreakerAdapter
+}
+
+type exchangeRegistry struct {
+	exchanges map[exchange.Exchange]registeredExchange
+}
+
+// ExchangeRegistry constructor for class. Exchanges are added with Register.
+func ExchangeRegistry() *exchangeRegistry {
+	return &exchangeRegistry{
+		exchanges: map[exchange.Exchange]registeredExchange{},
+	}
+}
+
+// Register adds the exchange service wrapped by CircuitBreakerWebService with the breaker, so every request to the
+// exchange goes through its circuit.
+func (e *exchangeRegistry) Register(exchange exchange.Exchange, service adapters.CryptoServiceAdapter, breaker adapters.CircuitBreakerAdapter) *exchangeRegistry {
+	e.exchanges[exchange] = registeredExchange{
+		service: CircuitBreakerWebService(breaker, service),
+		breaker: breaker,
+	}
+	return e
+}
+
+// Get returns the crypto service and the circuit breaker of the exchange. Returns an error with
+// error_code.ExchangeNotSupported if the exchange is not registered.
+func (e *exchangeRegistry) Get(exchange exchange.Exchange) (adapters.CryptoServiceAdapter, adapters.CircuitBreakerAdapter, custom_error.BaseErrorAdapter) {
+	registered, ok := e.exchanges[exchange]
+	if !ok {
+		return nil, nil, exceptions.ExchangeRegistryError(nil, "Exchange "+exchange.Name()+" is not registered")
+	}
+
+	return registered.service, registered.breaker, nil
+}
@@ -0,0 +1,178 @@
+package webservice
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type paperExchange struct {
+	logger           adapters.LoggerAdapter
+	ledgerDB         adapters2.LedgerPersistenceAdapter
+	priceFeed        adapters2.PriceFeedAdapter
+	startingBalances map[string]float64
+}
+
+// PaperExchange class constructor. Quotes come from the priceFeed, if it is nil they come from the ticker of the client
+// exchange.
+func PaperExchange(logger adapters.LoggerAdapter, ledgerDB adapters2.LedgerPersistenceAdapter, priceFeed adapters2.PriceFeedAdapter) *paperExchange {
+	return &paperExchange{
+		logger:           logger,
+		ledgerDB:         ledgerDB,
+		priceFeed:        priceFeed,
+		startingBalances: properties.Properties().PaperExchange.StartingBalances,
+	}
+}
+
+type paperExchangeService struct {
+	paperExchange *paperExchange
+	priceFeed     adapters2.PriceFeedAdapter
+}
+
+// Exchange returns the crypto service of simulation clients of the exchange service. Balances come from the client
+// virtual ledger and quotes from the recorded price feed, or from the exchange service ticker.
+func (p *paperExchange) Exchange(exchangeService adapters.CryptoServiceAdapter) adapters.CryptoServiceAdapter {
+	priceFeed := p.priceFeed
+	if priceFeed == nil {
+		priceFeed = exchangeService
+	}
+
+	return &paperExchangeService{
+		paperExchange: p,
+		priceFeed:     priceFeed,
+	}
+}
+
+// RecordedPriceFeed returns true if quotes come from the recorded price feed instead of the client exchange ticker.
+func (p *paperExchange) RecordedPriceFeed() bool {
+	return p.priceFeed != nil
+}
+
+// GetCrypto returns the quote of the crypto coin from the price feed.
+func (p *paperExchangeService) GetCrypto(ctx context.Context, symbol symbol.Symbol, quote symbol.Symbol) (*model.Coin, custom_error.BaseErrorAdapter) {
+	return p.priceFeed.GetCrypto(ctx, symbol, quote)
+}
+
+// GetBalance returns the client ledger balances, the ledger is opened with PAPER_EXCHANGE_STARTING_BALANCES on the
+// first request of the client.
+func (p *paperExchangeService) GetBalance(ctx context.Context, clientId string) (*model.Balance, custom_error.BaseErrorAdapter) {
+	p.paperExchange.logger.Info("Get paper balance start", clientId)
+
+	ledger, err := p.paperExchange.ledger(ctx, clientId)
+	if err != nil {
+		return nil, p.paperExchange.abort(err, "Error while trying to get client ledger", clientId)
+	}
+
+	balance := ledger.ToModel()
+
+	p.paperExchange.logger.Info("Get paper balance finish", clientId, balance)
+	return balance, nil
+}
+
+// Reserve debits the reserved balance of the operation from its base asset (BRL amount plus fee for BUY operations and
+// the crypto amount for SELL operations) on the client ledger. Returns error with error_code.LedgerBalance if the ledger balance is lower
+// than the amount.
+// Reserving an operation again does nothing. The reservation is only released here on compensation, the executor
+// settles it when the simulated operation completes.
+func (p *paperExchange) Reserve(ctx context.Context, clientId string, operation *model.Operation) custom_error.BaseErrorAdapter {
+	p.logger.Info("Reserve paper operation start", clientId, operation)
+
+	ledger, err := p.ledger(ctx, clientId)
+	if err != nil {
+		return p.abort(err, "Error while trying to get client ledger", clientId, operation)
+	}
+
+	if _, ok := ledger.Reservations[operation.Id]; ok {
+		p.logger.Info("Paper operation already reserved, skipping reservation", clientId, operation)
+		return nil
+	}
+
+	asset := operation.Base.Name()
+	if ledger.Balances[asset] < operation.Reserved() {
+		return p.abortWithCode("Client ledger "+asset+" balance is lower than the operation amount", error_code.LedgerBalance, clientId, operation)
+	}
+
+	ledger.Balances[asset] -= operation.Reserved()
+	ledger.Reservations[operation.Id] = &dto.LedgerReservation{
+		Asset:  asset,
+		Amount: operation.Reserved(),
+	}
+
+	if err := p.ledgerDB.Save(ctx, ledger); err != nil {
+		return p.abort(err, "Error while trying to save client ledger reservation", clientId, operation)
+	}
+
+	p.logger.Info("Reserve paper operation finish", clientId, operation, ledger)
+	return nil
+}
+
+// Release credits the amount reserved for the operation back to the client ledger. Does nothing if the operation is
+// not reserved.
+func (p *paperExchange) Release(ctx context.Context, clientId string, operation *model.Operation) custom_error.BaseErrorAdapter {
+	p.logger.Info("Release paper operation start", clientId, operation)
+
+	ledger, err := p.ledger(ctx, clientId)
+	if err != nil {
+		return p.abort(err, "Error while trying to get client ledger", clientId, operation)
+	}
+
+	reservation, ok := ledger.Reservations[operation.Id]
+	if !ok {
+		p.logger.Info("Paper operation not reserved, skipping release", clientId, operation)
+		return nil
+	}
+
+	ledger.Balances[reservation.Asset] += reservation.Amount
+	delete(ledger.Reservations, operation.Id)
+
+	if err := p.ledgerDB.Save(ctx, ledger); err != nil {
+		return p.abort(err, "Error while trying to save client ledger release", clientId, operation)
+	}
+
+	p.logger.Info("Release paper operation finish", clientId, operation, ledger)
+	return nil
+}
+
+// ledger returns the client ledger, a ledger holding the starting balances is created if the client has none.
+func (p *paperExchange) ledger(ctx context.Context, clientId string) (*dto.Ledger, custom_error.BaseErrorAdapter) {
+	ledger, err := p.ledgerDB.Get(ctx, clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	if ledger == nil {
+		ledger = dto.NewLedger(clientId, p.startingBalances)
+		if err := p.ledgerDB.Save(ctx, ledger); err != nil {
+			return nil, err
+		}
+	}
+
+	if ledger.Balances == nil {
+		ledger.Balances = map[string]float64{}
+	}
+	if ledger.Reservations == nil {
+		ledger.Reservations = map[string]*dto.LedgerReservation{}
+	}
+
+	return ledger, nil
+}
+
+func (p *paperExchange) abortWithCode(message string, code error_code.ErrorCode, metadata ...interface{}) custom_error.BaseErrorAdapter {
+	paperExchangeError := exceptions.PaperExchangeError(nil, message)
+	paperExchangeError.SetCode(code.Name())
+	p.logger.Error(paperExchangeError, "Paper exchange failed: "+message, metadata)
+	return paperExchangeError
+}
+
+func (p *paperExchange) abort(err error, message string, metadata ...interface{}) custom_error.BaseErrorAdapter {
+	paperExchangeError := exceptions.PaperExchangeError(err, message)
+	p.logger.Error(paperExchangeError, "Paper exchange failed: "+message, metadata)
+	return paperExchangeError
+}
@@ -0,0 +1,44 @@
+package webservice
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBodySize limits how much of a failed response body is read into the error.
+const maxErrorBodySize = 4096
+
+// responseError returns the response status and body as an error, the body is omitted when empty.
+func responseError(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+
+	if trimmedBody := strings.TrimSpace(string(body)); trimmedBody != "" {
+		return errors.New(response.Status + ": " + trimmedBody)
+	}
+	return errors.New(response.Status)
+}
+
+// unavailableStatus returns true if the status code means the exchange is unavailable or rate limiting requests (429,
+// 418 used by Binance for banned clients, and 5xx).
+func unavailableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusTeapot || statusCode >= http.StatusInternalServerError
+}
+
+// unavailableError returns true for transport errors, unless the caller ctx was cancelled or reached its deadline. The
+// HTTP client timeout does not end ctx, so requests timed out by the client are still flagged.
+func unavailableError(ctx context.Context) bool {
+	return ctx.Err() == nil
+}
+
+// responseTime returns the response Date header, or the current time if the header is missing or invalid.
+func responseTime(response *http.Response) time.Time {
+	if date, err := http.ParseTime(response.Header.Get("Date")); err == nil {
+		return date
+	}
+	return time_utils.Time().Value()
+}
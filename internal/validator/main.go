@@ -11,3 +11,17 @@ func Main() adapters.HandlerAdapter {
 	config.LoadEnv()
 	return config.DependencyInjector().WireDependencies().Handler
 }
+
+// Relay works as a proxy for the handler.RelayHandler class, configuring env vars and injecting dependencies the same
+// way as Main.
+func Relay() adapters.RelayHandlerAdapter {
+	config.LoadEnv()
+	return config.DependencyInjector().WireDependencies().RelayHandler
+}
+
+// Reaper works as a proxy for the handler.ReaperHandler class, configuring env vars and injecting dependencies the same
+// way as Main.
+func Reaper() adapters.ReaperHandlerAdapter {
+	config.LoadEnv()
+	return config.DependencyInjector().WireDependencies().ReaperHandler
+}
@@ -7,12 +7,15 @@ type BaseErrorAdapter interface {
 	LockedClientId() bool
 	LockedClient() bool
 	SetLocks(clientIdLock, clientLock bool)
+	Code() string
+	SetCode(code string)
 }
 
 type BaseError struct {
 	Message            string `json:"error"`
 	InternalMessage    string `json:"internal_error"`
 	DescriptionMessage string `json:"description"`
+	ErrorCode          string `json:"code,omitempty"`
 	lockedClientId     bool
 	lockedClient       bool
 }
@@ -43,6 +46,7 @@ func NewBaseError(err error, messages ...string) *BaseError {
 			Message:            e.Error(),
 			InternalMessage:    e.InternalError(),
 			DescriptionMessage: e.Description(),
+			ErrorCode:          e.Code(),
 			lockedClientId:     e.LockedClientId(),
 			lockedClient:       e.LockedClient(),
 		}
@@ -81,3 +85,11 @@ func (b *BaseError) SetLocks(clientIdLock, clientLock bool) {
 	b.lockedClientId = clientIdLock
 	b.lockedClient = clientLock
 }
+
+func (b *BaseError) Code() string {
+	return b.ErrorCode
+}
+
+func (b *BaseError) SetCode(code string) {
+	b.ErrorCode = code
+}
@@ -48,6 +48,13 @@ func (l *logger) SetCorrelationID(correlationId string) {
 	l.CorrelationId = correlationId
 }
 
+// WithCorrelationID returns a copy of the logger bound to the given correlation id, the original logger is not changed.
+func (l *logger) WithCorrelationID(correlationId string) *logger {
+	logg := l.clone()
+	logg.CorrelationId = correlationId
+	return &logg
+}
+
 func (l *logger) Info(message string, metadata ...interface{}) {
 	logMessage := l.generateLogMessage(infoLevel, message, nil, metadata)
 	log.Println(logMessage)
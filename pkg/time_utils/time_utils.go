@@ -2,9 +2,14 @@ package time_utils
 
 import (
 	"strconv"
+	"strings"
 	"time"
+	_ "time/tzdata"
 )
 
+// timeStringLayout is the layout produced by time.Time String method, without the monotonic clock reading.
+const timeStringLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
 type timeSource struct {
 	year     int
 	day      int
@@ -24,8 +29,36 @@ func Time() *timeSource {
 	}
 }
 
+// TimeIn creates a timeSource with current time in location, period boundaries (day, month, tomorrow...) are computed
+// in this location.
+func TimeIn(location *time.Location) *timeSource {
+	now := time.Now().In(location)
+	return &timeSource{
+		year:     now.Year(),
+		day:      now.Day(),
+		month:    now.Month(),
+		location: location,
+		now:      now,
+	}
+}
+
+// Location loads the location by its IANA name (e.g. "America/Sao_Paulo"). Falls back to UTC if name is invalid.
+func Location(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// From creates a timeSource from a RFC3339 or time.Time String formatted date. Falls back to current time if date
+// cannot be parsed.
 func From(date string) *timeSource {
-	now, err := time.Parse(time.Now().String(), date)
+	now, err := time.Parse(time.RFC3339Nano, date)
+	if err != nil {
+		now, err = time.Parse(timeStringLayout, strings.Split(date, " m=")[0])
+	}
 	if err != nil {
 		now = time.Now()
 	}
@@ -48,10 +81,27 @@ func (t *timeSource) Value() time.Time {
 	return t.now
 }
 
+func (t *timeSource) Year() int {
+	return t.year
+}
+
+func (t *timeSource) Month() int {
+	return int(t.month)
+}
+
+func (t *timeSource) Day() int {
+	return t.day
+}
+
 func Epoch() string {
 	return strconv.FormatInt(time.Now().Unix(), 10)
 }
 
+// EpochMilli returns the current unix time in milliseconds.
+func EpochMilli() string {
+	return strconv.FormatInt(time.Now().UnixMilli(), 10)
+}
+
 func (t *timeSource) Tomorrow() time.Time {
 	return time.Date(t.year, t.month, t.day+1, 00, 0, 0, 0, t.location)
 }
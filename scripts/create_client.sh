@@ -24,20 +24,25 @@ aws dynamodb put-item \
               "cash_reserved": {
                 "N": "0"
               },
-              "crypto_amount": {
-                "N": "1.000000"
-              },
-              "crypto_symbol": {
-                "S": "BTC"
-              },
-              "crypto_reserved": {
-                "N": "0"
+              "crypto": {
+                "M": {
+                  "BTC": {
+                    "M": {
+                      "amount": {
+                        "N": "1.000000"
+                      },
+                      "reserved": {
+                        "N": "0"
+                      }
+                    }
+                  }
+                }
               },
               "buy_on": {
-                "N": "1"
+                "S": "BUY"
               },
               "sell_on": {
-                "N": "1"
+                "S": "SELL"
               },
               "ops_timeout_seconds": {
                 "N": "60"
@@ -1,5 +1,7 @@
 #!/bin/sh
 
+START_TIME=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+
 echo "########### Sending message to SNS ###########"
 aws sns publish \
 --endpoint-url=http://localhost:4566 \
@@ -9,5 +11,6 @@ aws sns publish \
              "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
              "operation": "BUY",
              "symbol": "BTC",
-             "start_time": "2022-09-17T12:05:07.45066-03:00"
+             "analysis": "STRONG_BUY",
+             "start_time": "'"$START_TIME"'"
            }'
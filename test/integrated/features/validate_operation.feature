@@ -2,12 +2,19 @@
 Feature: Validate operation
   In order to validate and trigger operations
   The client must be available and its credentials should be available as well
+  The client must be active
+  The client locked_until date must have been reached
+  The client must have the symbol received configured
+  The analysis received must be equal or less restricting than the client buy_on and sell_on configuration
   The client should not be locked on redis
   The client should not be locked on dynamodb
   The client daily loss should be less than the configured daily stop loss
   The client monthly loss should be less than the configured monthly stop loss
   The client must have enough balance on biscoint
+  The operation request must not be older than the configured max age
+  The price must not have moved beyond the client slippage tolerance since the signal
   An operation request must be received via sns
+  Operation events left pending on the outbox are published by the relay
 
   Background:
     Given test env variables were loaded
@@ -41,9 +48,457 @@ Feature: Validate operation
         "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
         "operation": "BUY",
         "symbol": "BTC",
+        "analysis": "STRONG_BUY",
+        "start_time": "<start_time>"
+      }
+      """
+    Then there should be 1 messages sent via sns
+    And process should exit with 0
+
+  Scenario: Validate operation request for inactive client with failure
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client is not active
+    And client available "brl" balance is 10000.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "BUY",
+        "symbol": "BTC",
+        "analysis": "STRONG_BUY",
+        "start_time": "<start_time>"
+      }
+      """
+    Then there should be 0 messages sent via sns
+    And process should exit with 0
+    And error code should be "CLIENT_INACTIVE"
+
+  Scenario: Validate operation request for client locked until tomorrow with failure
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client is locked until tomorrow
+    And client available "brl" balance is 10000.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "BUY",
+        "symbol": "BTC",
+        "analysis": "STRONG_BUY",
+        "start_time": "<start_time>"
+      }
+      """
+    Then there should be 0 messages sent via sns
+    And process should exit with 0
+    And error code should be "CLIENT_LOCKED_UNTIL"
+
+  Scenario: Validate operation request for symbol not configured on client with failure
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client available "brl" balance is 10000.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "BUY",
+        "symbol": "ETH",
+        "analysis": "STRONG_BUY",
+        "start_time": "<start_time>"
+      }
+      """
+    Then there should be 0 messages sent via sns
+    And process should exit with 0
+    And error code should be "SYMBOL_NOT_ALLOWED"
+
+  Scenario: Validate operation request for client with day stop loss reached locks client until tomorrow
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client day stop loss is 100.00
+    And client day profit is -1000.00
+    And client available "brl" balance is 10000.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "BUY",
+        "symbol": "BTC",
+        "analysis": "STRONG_BUY",
+        "start_time": "<start_time>"
+      }
+      """
+    Then there should be 0 messages sent via sns
+    And process should exit with 0
+    And error code should be "DAY_STOP_LOSS_REACHED"
+    And client should be locked until tomorrow on DynamoDB
+
+  Scenario: Validate operation request with analysis weaker than client buy_on with failure
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client buy on is "STRONG_BUY"
+    And client available "brl" balance is 10000.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "BUY",
+        "symbol": "BTC",
+        "analysis": "BUY",
+        "start_time": "<start_time>"
+      }
+      """
+    Then there should be 0 messages sent via sns
+    And process should exit with 0
+    And error code should be "BUY_ON_NOT_REACHED"
+
+  Scenario: Validate operation request older than max age with failure
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client available "brl" balance is 10000.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "BUY",
+        "symbol": "BTC",
+        "analysis": "STRONG_BUY",
         "start_time": "2022-09-17T12:05:07.45066-03:00"
       }
       """
+    Then there should be 0 messages sent via sns
+    And there should be 0 operations saved on DynamoDB
+    And process should exit with 0
+    And error code should be "STALE_REQUEST"
+
+  Scenario: Validate operation request with price moved beyond client slippage tolerance with failure
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client slippage tolerance is 0.5
+    And client available "brl" balance is 10000.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "BUY",
+        "symbol": "BTC",
+        "analysis": "STRONG_BUY",
+        "start_time": "<start_time>",
+        "price": 99000.00
+      }
+      """
+    Then there should be 0 messages sent via sns
+    And there should be 0 operations saved on DynamoDB
+    And process should exit with 0
+    And error code should be "PRICE_SLIPPAGE"
+
+  Scenario: Validate operation request with price within client slippage tolerance with success
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client slippage tolerance is 0.5
+    And client available "brl" balance is 10000.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "BUY",
+        "symbol": "BTC",
+        "analysis": "STRONG_BUY",
+        "start_time": "<start_time>",
+        "price": 99800.00
+      }
+      """
+    Then there should be 1 messages sent via sns
+    And there should be 1 operation saved on DynamoDB
+    And process should exit with 0
+
+  Scenario: Validate sell operation request for ETH symbol with success
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client also operates symbol "ETH"
+    And client available "eth" balance is 2.0
+    And client reserved "eth" balance is 0.5
+    And client "brl" balance is 10000.00 on biscoint
+    And client "eth" balance is 2.5 on biscoint
+    And crypto current "buy" value is 8000.00 on biscoint
+    And crypto current "sell" value is 7900.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "SELL",
+        "symbol": "ETH",
+        "analysis": "STRONG_SELL",
+        "start_time": "<start_time>"
+      }
+      """
     Then there should be 1 messages sent via sns
     And process should exit with 0
 
+  Scenario: Validate batch of operation requests reporting only failed messages
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client available "brl" balance is 10000.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following messages are received
+      """
+      [
+        {
+          "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+          "operation": "BUY",
+          "symbol": "BTC",
+          "analysis": "STRONG_BUY",
+          "start_time": "<start_time>"
+        },
+        {
+          "client_id": "bb324edf-99fa-4a95-b9c4-a588d1ccb441e",
+          "operation": "BUY",
+          "symbol": "BTC",
+          "analysis": "STRONG_BUY",
+          "start_time": "<start_time>"
+        },
+        "not an operation request"
+      ]
+      """
+    Then there should be 1 messages sent via sns
+    And 1 messages should be reported as failed
+
+  Scenario: Validate operation request wrapped in SNS envelope with success
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client available "brl" balance is 10000.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "Type": "Notification",
+        "MessageId": "5c1a0e4e-7d0e-5c5e-9b4e-1d2f3a4b5c6d",
+        "TopicArn": "arn:aws:sns:sa-east-1:000000000000:cryptoValidatorTopic",
+        "Message": "{\"client_id\":\"aa324edf-99fa-4a95-b9c4-a588d1ccb441e\",\"operation\":\"BUY\",\"symbol\":\"BTC\",\"analysis\":\"STRONG_BUY\",\"start_time\":\"<start_time>\"}",
+        "Timestamp": "2022-09-17T15:05:07.450Z",
+        "SignatureVersion": "1",
+        "Signature": "c2lnbmF0dXJl",
+        "SigningCertURL": "https://sns.sa-east-1.amazonaws.com/SimpleNotificationService-test.pem",
+        "UnsubscribeURL": "https://sns.sa-east-1.amazonaws.com/?Action=Unsubscribe",
+        "MessageAttributes": {
+          "origin": {
+            "Type": "String",
+            "Value": "crypto-robot-analyzer"
+          }
+        }
+      }
+      """
+    Then there should be 1 messages sent via sns
+    And process should exit with 0
+
+  Scenario: Validate replayed operation request republishes the operation without reserving balance again
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client available "brl" balance is 10000.00
+    And client operation amount percentage is 10.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following messages are received
+      """
+      [
+        {
+          "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+          "operation": "BUY",
+          "symbol": "BTC",
+          "analysis": "STRONG_BUY",
+          "start_time": "<start_time>"
+        },
+        {
+          "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+          "operation": "BUY",
+          "symbol": "BTC",
+          "analysis": "STRONG_BUY",
+          "start_time": "<start_time>"
+        }
+      ]
+      """
+    Then there should be 2 messages sent via sns
+    And there should be 1 operation saved on DynamoDB
+    And client reserved "brl" balance should be 1005.00 on DynamoDB
+    And process should exit with 0
+
+  Scenario: Validate operation request with sns failure cancels operation and releases reserved balance
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client available "brl" balance is 10000.00
+    And client operation amount percentage is 10.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    And sns service is down
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "BUY",
+        "symbol": "BTC",
+        "analysis": "STRONG_BUY",
+        "start_time": "<start_time>"
+      }
+      """
+    Then there should be 0 messages sent via sns
+    And operation status should be "CANCELLED" on DynamoDB
+    And operation event should be "PENDING" on the outbox
+    And client reserved "brl" balance should be 0.00 on DynamoDB
+    And process should exit with 1
+    Given sns service is back up
+    When the outbox relay runs
+    Then the outbox relay should report 0 sent and 1 discarded event
+    And operation event should be "DISCARDED" on the outbox
+    And there should be 0 messages sent via sns
+
+  Scenario: Validate operation request marks its outbox event as sent so the relay does not publish it again
+    Given there is a client available on DynamoDB with client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+    And client available "brl" balance is 10000.00
+    And client operation amount percentage is 10.00
+    And client "brl" balance is 10000.00 on biscoint
+    And crypto current "buy" value is 100000.00 on biscoint
+    And crypto current "sell" value is 99000.00 on biscoint
+    And the following credentials available for client id "aa324edf-99fa-4a95-b9c4-a588d1ccb441e"
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_key": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "api_secret": "a7aca6d4f67519fbb4dc65b159b4e9526b069a2cb5f515d4690bce05ba81e6e5967f477e0ce3affa7c80843f3efed1cee9b0c062"
+      }
+      """
+    When the following message is received
+      """
+      {
+        "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
+        "operation": "BUY",
+        "symbol": "BTC",
+        "analysis": "STRONG_BUY",
+        "start_time": "<start_time>"
+      }
+      """
+    Then there should be 1 messages sent via sns
+    And operation event should be "SENT" on the outbox
+    And process should exit with 0
+    When the outbox relay runs
+    Then the outbox relay should report 0 sent and 0 discarded events
+    And there should be 1 messages sent via sns
@@ -3,18 +3,29 @@ package integrated
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/brienze1/crypto-robot-validator/internal/validator"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	dto2 "github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/analysis_strength"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/summary_type"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
 	"github.com/brienze1/crypto-robot-validator/test/mocks"
 	"github.com/cucumber/godog"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestFeatures(test *testing.T) {
@@ -41,16 +52,36 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^redis is up$`, redisIsUp)
 	ctx.Step(`^biscoint api is up$`, biscointApiIsUp)
 	ctx.Step(`^sns service is up$`, snsServiceIsUp)
+	ctx.Step(`^sns service is down$`, snsServiceIsDown)
+	ctx.Step(`^sns service is back up$`, snsServiceIsBackUp)
 	ctx.Step(`^secrets manager service is up$`, secretsManagerServiceIsUp)
 	ctx.Step(`^there is a client available on DynamoDB with client id "([^"]*)"$`, thereIsAClientAvailableOnDynamoDBWithClientId)
-	ctx.Step(`^client available "([^"]*)" balance is (\d+)\.(\d+)$`, clientAvailableBalanceIs)
-	ctx.Step(`^client reserved "([^"]*)" balance is (\d+)\.(\d+)$`, clientReservedBalanceIs)
-	ctx.Step(`^client "([^"]*)" balance is (\d+)\.(\d+) on biscoint$`, clientBalanceIsOnBiscoint)
-	ctx.Step(`^crypto current "([^"]*)" value is (\d+)\.(\d+) on biscoint$`, cryptoCurrentValueIsOnBiscoint)
+	ctx.Step(`^client is not active$`, clientIsNotActive)
+	ctx.Step(`^client is locked until tomorrow$`, clientIsLockedUntilTomorrow)
+	ctx.Step(`^client also operates symbol "([^"]*)"$`, clientAlsoOperatesSymbol)
+	ctx.Step(`^client buy on is "([^"]*)"$`, clientBuyOnIs)
+	ctx.Step(`^client day stop loss is (\d+\.\d+)$`, clientDayStopLossIs)
+	ctx.Step(`^client day profit is (-?\d+\.\d+)$`, clientDayProfitIs)
+	ctx.Step(`^client operation amount percentage is (\d+\.\d+)$`, clientOperationAmountPercentageIs)
+	ctx.Step(`^client slippage tolerance is (\d+\.\d+)$`, clientSlippageToleranceIs)
+	ctx.Step(`^client available "([^"]*)" balance is (\d+\.\d+)$`, clientAvailableBalanceIs)
+	ctx.Step(`^client reserved "([^"]*)" balance is (\d+\.\d+)$`, clientReservedBalanceIs)
+	ctx.Step(`^client "([^"]*)" balance is (\d+\.\d+) on biscoint$`, clientBalanceIsOnBiscoint)
+	ctx.Step(`^crypto current "([^"]*)" value is (\d+\.\d+) on biscoint$`, cryptoCurrentValueIsOnBiscoint)
 	ctx.Step(`^the following credentials available for client id "([^"]*)"$`, theFollowingCredentialsAvailableForClientId)
 	ctx.Step(`^the following message is received$`, theFollowingMessageIsReceived)
+	ctx.Step(`^the following messages are received$`, theFollowingMessagesAreReceived)
+	ctx.Step(`^the outbox relay runs$`, theOutboxRelayRuns)
+	ctx.Step(`^(\d+) messages? should be reported as failed$`, messagesShouldBeReportedAsFailed)
 	ctx.Step(`^there should be (\d+) messages sent via sns$`, thereShouldBeMessagesSentViaSns)
+	ctx.Step(`^there should be (\d+) operations? saved on DynamoDB$`, thereShouldBeOperationsSavedOnDynamoDB)
+	ctx.Step(`^operation status should be "([^"]*)" on DynamoDB$`, operationStatusShouldBeOnDynamoDB)
+	ctx.Step(`^operation event should be "([^"]*)" on the outbox$`, operationEventShouldBeOnTheOutbox)
+	ctx.Step(`^the outbox relay should report (\d+) sent and (\d+) discarded events?$`, theOutboxRelayShouldReportSentAndDiscardedEvents)
 	ctx.Step(`^process should exit with (\d+)$`, processShouldExitWith)
+	ctx.Step(`^error code should be "([^"]*)"$`, errorCodeShouldBe)
+	ctx.Step(`^client should be locked until tomorrow on DynamoDB$`, clientShouldBeLockedUntilTomorrowOnDynamoDB)
+	ctx.Step(`^client reserved "([^"]*)" balance should be (\d+\.\d+) on DynamoDB$`, clientReservedBalanceShouldBeOnDynamoDB)
 }
 
 var (
@@ -58,22 +89,34 @@ var (
 	biscointApi          = mocks.HttpClient()
 	snsClient            = mocks.SNSClient()
 	secretsManagerClient = mocks.SecretsManager()
+	logger               = mocks.Logger()
+	timeSource           = mocks.TimeSource()
 )
 
 var (
-	t         *testing.T
-	client    *dto.Client
-	balance   *dto.BalanceResponse
-	coin      *dto.CoinResponse
-	handleErr error
+	t              *testing.T
+	client         *dto.Client
+	balance        *dto.BalanceResponse
+	coin           *dto.CoinResponse
+	messages       []string
+	startTime      string
+	handleResponse events.SQSEventResponse
+	handleErr      error
+	relayReport    *dto2.RelayReport
+	relayErr       error
 )
 
 func testEnvVariablesWereLoaded() error {
 	config.LoadTestEnv()
+	logger.Reset()
+	config.DependencyInjector().LoggerFactory = logger.Factory
+	startTime = time.Now().Format(time.RFC3339Nano)
+	timeSource.Reset()
 	return nil
 }
 
 func dynamoDBIsUp() error {
+	dynamoDB.Reset()
 	config.DependencyInjector().DynamoDBClient = dynamoDB
 	return nil
 }
@@ -86,8 +129,8 @@ func redisIsUp() error {
 func biscointApiIsUp() error {
 	balance = &dto.BalanceResponse{
 		Balance: dto.Balance{
-			BRL: "0.0",
-			BTC: "0.0",
+			"BRL": "0.0",
+			"BTC": "0.0",
 		},
 	}
 
@@ -98,6 +141,7 @@ func biscointApiIsUp() error {
 			Quote:     "BRL",
 			BuyValue:  100000.00,
 			SellValue: 99000.00,
+			Timestamp: time.Now(),
 		},
 	}
 
@@ -111,10 +155,21 @@ func biscointApiIsUp() error {
 }
 
 func snsServiceIsUp() error {
+	snsClient.Reset()
 	config.DependencyInjector().SNSClient = snsClient
 	return nil
 }
 
+func snsServiceIsDown() error {
+	snsClient.PublishError = errors.New("sns service is down")
+	return nil
+}
+
+func snsServiceIsBackUp() error {
+	snsClient.PublishError = nil
+	return nil
+}
+
 func secretsManagerServiceIsUp() error {
 	encryptionSecret := &dto.EncryptionSecrets{
 		EncryptionKey: "9y$B?E(H+MbQeThWmZq4t7w!z%C*F)J@",
@@ -128,6 +183,8 @@ func thereIsAClientAvailableOnDynamoDBWithClientId(clientId string) error {
 	client = &dto.Client{
 		Id:      clientId,
 		Active:  true,
+		BuyOn:   analysis_strength.Buy,
+		SellOn:  analysis_strength.Sell,
 		Symbols: []string{"BTC"},
 	}
 
@@ -135,41 +192,105 @@ func thereIsAClientAvailableOnDynamoDBWithClientId(clientId string) error {
 	return nil
 }
 
+func clientIsNotActive() error {
+	client.Active = false
+	dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
+	return nil
+}
+
+func clientIsLockedUntilTomorrow() error {
+	client.LockedUntil = time_utils.Time().Tomorrow().Format(time.RFC3339Nano)
+	dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
+	return nil
+}
+
+func clientAlsoOperatesSymbol(symbol string) error {
+	client.Symbols = append(client.Symbols, symbol)
+	dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
+	return nil
+}
+
+func clientBuyOnIs(buyOn string) error {
+	client.BuyOn = analysis_strength.AnalysisStrength(buyOn)
+	dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
+	return nil
+}
+
+func clientDayStopLossIs(value float64) error {
+	client.DayStopLoss = value
+	dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
+	return nil
+}
+
+func clientDayProfitIs(value float64) error {
+	now := time.Now().UTC()
+	client.Summary = append(client.Summary, &dto.Summary{
+		Type:   summary_type.Day,
+		Day:    now.Day(),
+		Month:  int(now.Month()),
+		Year:   now.Year(),
+		Profit: value,
+	})
+	dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
+	return nil
+}
+
+func clientOperationAmountPercentageIs(value float64) error {
+	client.OperationAmountPercentage = value
+	dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
+	return nil
+}
+
+func clientSlippageToleranceIs(value float64) error {
+	client.SlippageTolerance = value
+	dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
+	return nil
+}
+
 func clientAvailableBalanceIs(balanceType string, value float64) error {
 	if balanceType == "brl" {
 		client.CashAvailable = value
-		dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
-	} else if balanceType == "btc" {
-		client.CryptoAvailable = value
-		dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
+	} else {
+		clientCryptoBalance(balanceType).Available = value
 	}
+	dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
 	return nil
 }
 
 func clientReservedBalanceIs(balanceType string, value float64) error {
 	if balanceType == "brl" {
 		client.CashReserved = value
-		dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
-	} else if balanceType == "btc" {
-		client.CryptoReserved = value
-		dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
+	} else {
+		clientCryptoBalance(balanceType).Reserved = value
 	}
+	dynamoDB.AddItem(client.Id, client, properties.Properties().Aws.DynamoDB.ClientTableName)
 	return nil
 }
 
 func clientBalanceIsOnBiscoint(balanceType string, value float64) error {
 	if balanceType == "brl" {
-		balance.Balance.BRL = strconv.FormatFloat(value, 'f', 2, 64)
-		balanceResponse, _ := json.Marshal(balance)
-		biscointApi.GetBalanceResponse = string(balanceResponse)
-	} else if balanceType == "btc" {
-		balance.Balance.BTC = strconv.FormatFloat(value, 'f', 8, 64)
-		balanceResponse, _ := json.Marshal(balance)
-		biscointApi.GetBalanceResponse = string(balanceResponse)
+		balance.Balance["BRL"] = strconv.FormatFloat(value, 'f', 2, 64)
+	} else {
+		balance.Balance[strings.ToUpper(balanceType)] = strconv.FormatFloat(value, 'f', 8, 64)
 	}
+	balanceResponse, _ := json.Marshal(balance)
+	biscointApi.GetBalanceResponse = string(balanceResponse)
 	return nil
 }
 
+func clientCryptoBalance(balanceType string) *dto.CryptoBalance {
+	if client.Crypto == nil {
+		client.Crypto = map[string]*dto.CryptoBalance{}
+	}
+
+	cryptoSymbol := strings.ToUpper(balanceType)
+	if _, ok := client.Crypto[cryptoSymbol]; !ok {
+		client.Crypto[cryptoSymbol] = &dto.CryptoBalance{}
+	}
+
+	return client.Crypto[cryptoSymbol]
+}
+
 func cryptoCurrentValueIsOnBiscoint(operationType string, value float64) error {
 	if operationType == "buy" {
 		coin.Coin.BuyValue = value
@@ -191,10 +312,50 @@ func theFollowingCredentialsAvailableForClientId(clientId string, credentialsJso
 }
 
 func theFollowingMessageIsReceived(messageReceived *godog.DocString) error {
-	event := createSQSEvent(messageReceived.Content)
+	messages = []string{withStartTime(messageReceived.Content)}
+	event := createSQSEvent(messages...)
 	ctx := createContext()
 
-	return validator.Main().Handle(ctx, event)
+	handleResponse, handleErr = validator.Main().Handle(ctx, event)
+	return nil
+}
+
+func theFollowingMessagesAreReceived(messagesReceived *godog.DocString) error {
+	var messagesJson []json.RawMessage
+	if err := json.Unmarshal([]byte(withStartTime(messagesReceived.Content)), &messagesJson); err != nil {
+		return err
+	}
+
+	messages = make([]string, 0, len(messagesJson))
+	for _, message := range messagesJson {
+		messages = append(messages, string(message))
+	}
+
+	event := createSQSEvent(messages...)
+	ctx := createContext()
+
+	handleResponse, handleErr = validator.Main().Handle(ctx, event)
+	return nil
+}
+
+// withStartTime replaces the <start_time> placeholder of the messages with the scenario start time, so requests are
+// not rejected for being older than the max age.
+func withStartTime(message string) string {
+	return strings.ReplaceAll(message, "<start_time>", startTime)
+}
+
+// theOutboxRelayRuns runs the relay once the grace period of the entries created by the scenario is over.
+func theOutboxRelayRuns() error {
+	timeSource.Offset = properties.Properties().RelayGracePeriod + time.Second
+	config.DependencyInjector().TimeSource = timeSource
+	relayReport, relayErr = validator.Relay().Handle(createContext())
+	return nil
+}
+
+func messagesShouldBeReportedAsFailed(numberOfMessages int) error {
+	assert.Nil(t, handleErr)
+	assert.Len(t, handleResponse.BatchItemFailures, numberOfMessages)
+	return nil
 }
 
 func thereShouldBeMessagesSentViaSns(numberOfMessages int) error {
@@ -202,23 +363,114 @@ func thereShouldBeMessagesSentViaSns(numberOfMessages int) error {
 	return nil
 }
 
+func thereShouldBeOperationsSavedOnDynamoDB(numberOfOperations int) error {
+	assert.Equal(t, numberOfOperations, dynamoDB.NumberOfOperations())
+	return nil
+}
+
+func operationStatusShouldBeOnDynamoDB(operationStatus string) error {
+	request := &dto2.OperationRequest{}
+	_ = json.Unmarshal([]byte(messages[len(messages)-1]), request)
+
+	output, _ := dynamoDB.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		Key: map[string]types.AttributeValue{
+			"operation_id": &types.AttributeValueMemberS{Value: request.ToModel().OperationId()},
+		},
+		TableName: properties.Properties().Aws.DynamoDB.OperationTableName,
+	})
+
+	var operationPersisted dto.Operation
+	_ = attributevalue.UnmarshalMap(output.Item, &operationPersisted)
+
+	assert.Equal(t, operationStatus, string(operationPersisted.Status))
+	return nil
+}
+
+func operationEventShouldBeOnTheOutbox(eventStatus string) error {
+	request := &dto2.OperationRequest{}
+	_ = json.Unmarshal([]byte(messages[len(messages)-1]), request)
+
+	output, _ := dynamoDB.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		Key: map[string]types.AttributeValue{
+			"outbox_id": &types.AttributeValueMemberS{Value: "EVENT#" + request.ToModel().OperationId()},
+		},
+		TableName: properties.Properties().Aws.DynamoDB.OutboxTableName,
+	})
+
+	var entryPersisted dto.OutboxEntry
+	_ = attributevalue.UnmarshalMap(output.Item, &entryPersisted)
+
+	assert.Equal(t, eventStatus, string(entryPersisted.Status))
+	return nil
+}
+
+func theOutboxRelayShouldReportSentAndDiscardedEvents(sent, discarded int) error {
+	assert.Nil(t, relayErr)
+	assert.Len(t, relayReport.Sent, sent)
+	assert.Len(t, relayReport.Discarded, discarded)
+	assert.Empty(t, relayReport.Failed)
+	return nil
+}
+
 func processShouldExitWith(status int) error {
+	assert.Nil(t, handleErr)
 	if status == 0 {
-		assert.Nil(t, handleErr)
+		assert.Empty(t, handleResponse.BatchItemFailures)
 	} else if status == 1 {
-		assert.NotNil(t, handleErr)
+		assert.NotEmpty(t, handleResponse.BatchItemFailures)
 	}
 	return nil
 }
 
-func createSQSEvent(message string) events.SQSEvent {
-	return events.SQSEvent{
-		Records: []events.SQSMessage{
-			{
-				Body: message,
-			},
+func errorCodeShouldBe(code string) error {
+	assert.NotNil(t, logger.LastError)
+	assert.Equal(t, code, logger.LastError.(custom_error.BaseErrorAdapter).Code())
+	return nil
+}
+
+func clientShouldBeLockedUntilTomorrowOnDynamoDB() error {
+	clientPersisted := persistedClient()
+
+	assert.Equal(t, true, time_utils.Time().Tomorrow().Equal(clientPersisted.ToModel().LockedUntil))
+	assert.Equal(t, false, clientPersisted.Locked)
+	return nil
+}
+
+func clientReservedBalanceShouldBeOnDynamoDB(balanceType string, value float64) error {
+	clientPersisted := persistedClient()
+
+	if balanceType == "brl" {
+		assert.Equal(t, value, clientPersisted.CashReserved)
+	} else {
+		assert.Equal(t, value, clientPersisted.Crypto[strings.ToUpper(balanceType)].Reserved)
+	}
+	assert.Equal(t, false, clientPersisted.Locked)
+	return nil
+}
+
+func persistedClient() *dto.Client {
+	output, _ := dynamoDB.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		Key: map[string]types.AttributeValue{
+			"client_id": &types.AttributeValueMemberS{Value: client.Id},
 		},
+		TableName: properties.Properties().Aws.DynamoDB.ClientTableName,
+	})
+
+	var clientPersisted dto.Client
+	_ = attributevalue.UnmarshalMap(output.Item, &clientPersisted)
+	return &clientPersisted
+}
+
+func createSQSEvent(messages ...string) events.SQSEvent {
+	event := events.SQSEvent{}
+	for _, message := range messages {
+		event.Records = append(event.Records, events.SQSMessage{
+			MessageId: uuid.NewString(),
+			Body:      message,
+		})
 	}
+
+	return event
 }
 
 type ctx struct {
@@ -234,6 +486,7 @@ func (ctx ctx) Value(any) any {
 
 func createContext() *ctx {
 	return &ctx{
+		Context:      context.Background(),
 		awsRequestId: uuid.NewString(),
 	}
 }
@@ -1,10 +1,12 @@
 package mocks
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"time"
 )
 
 type biscointWebService struct {
@@ -12,19 +14,27 @@ type biscointWebService struct {
 	GetCryptoError        error
 	CoinExpectedBuyValue  float64
 	CoinExpectedSellValue float64
+	CoinTimestamp         time.Time
 	GetBalanceCounter     int
 	GetBalanceError       error
 	ClientBrlBalance      float64
 	ClientCryptoBalance   float64
+	ClientCryptoSymbol    symbol.Symbol
 }
 
 func BiscointWebService() *biscointWebService {
-	return &biscointWebService{}
+	return &biscointWebService{
+		CoinTimestamp: time.Now(),
+	}
 }
 
-func (b *biscointWebService) GetCrypto(symbol symbol.Symbol, quote symbol.Symbol) (*model.Coin, custom_error.BaseErrorAdapter) {
+func (b *biscointWebService) GetCrypto(ctx context.Context, symbol symbol.Symbol, quote symbol.Symbol) (*model.Coin, custom_error.BaseErrorAdapter) {
 	b.GetCryptoCounter++
 
+	if ctx.Err() != nil {
+		return nil, exceptions.BiscointWebServiceError(ctx.Err(), "GetCrypto context error")
+	}
+
 	if b.GetCryptoError != nil {
 		return nil, exceptions.BiscointWebServiceError(b.GetCryptoError, "GetCrypto error")
 	}
@@ -34,19 +44,31 @@ func (b *biscointWebService) GetCrypto(symbol symbol.Symbol, quote symbol.Symbol
 		Quote:     quote,
 		BuyValue:  b.CoinExpectedBuyValue,
 		SellValue: b.CoinExpectedSellValue,
+		Timestamp: b.CoinTimestamp,
 	}, nil
 }
 
-func (b *biscointWebService) GetBalance(string, bool) (*model.Balance, custom_error.BaseErrorAdapter) {
+func (b *biscointWebService) GetBalance(ctx context.Context, _ string) (*model.Balance, custom_error.BaseErrorAdapter) {
 	b.GetBalanceCounter++
 
+	if ctx.Err() != nil {
+		return nil, exceptions.BiscointWebServiceError(ctx.Err(), "GetBalance context error")
+	}
+
 	if b.GetBalanceError != nil {
 		return nil, exceptions.BiscointWebServiceError(b.GetBalanceError, "GetBalance error")
 	}
 
+	cryptoSymbol := b.ClientCryptoSymbol
+	if cryptoSymbol == "" {
+		cryptoSymbol = symbol.Bitcoin
+	}
+
 	return &model.Balance{
-		BrlBalance:    b.ClientBrlBalance,
-		CryptoBalance: b.ClientCryptoBalance,
+		Assets: map[symbol.Symbol]float64{
+			symbol.Brl:   b.ClientBrlBalance,
+			cryptoSymbol: b.ClientCryptoBalance,
+		},
 	}, nil
 }
 
@@ -55,8 +77,10 @@ func (b *biscointWebService) Reset() {
 	b.GetCryptoError = nil
 	b.CoinExpectedBuyValue = 0
 	b.CoinExpectedSellValue = 0
+	b.CoinTimestamp = time.Now()
 	b.GetBalanceCounter = 0
 	b.GetBalanceError = nil
 	b.ClientBrlBalance = 0
 	b.ClientCryptoBalance = 0
+	b.ClientCryptoSymbol = ""
 }
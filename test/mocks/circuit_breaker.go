@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type circuitBreaker struct {
+	AllowCounter   int
+	CheckCounter   int
+	Open           bool
+	SuccessCounter int
+	ReleaseCounter int
+	FailureCounter int
+}
+
+func CircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{}
+}
+
+func (c *circuitBreaker) Allow(context.Context) custom_error.BaseErrorAdapter {
+	c.AllowCounter++
+	if c.Open {
+		return exceptions.CircuitBreakerError(nil, "Circuit is open")
+	}
+	return nil
+}
+
+func (c *circuitBreaker) Check(context.Context) custom_error.BaseErrorAdapter {
+	c.CheckCounter++
+	if c.Open {
+		return exceptions.CircuitBreakerError(nil, "Circuit is open")
+	}
+	return nil
+}
+
+func (c *circuitBreaker) Success(context.Context) {
+	c.SuccessCounter++
+}
+
+func (c *circuitBreaker) Release(context.Context) {
+	c.ReleaseCounter++
+}
+
+func (c *circuitBreaker) Failure(context.Context) {
+	c.FailureCounter++
+}
+
+func (c *circuitBreaker) Reset() {
+	c.AllowCounter = 0
+	c.CheckCounter = 0
+	c.Open = false
+	c.SuccessCounter = 0
+	c.ReleaseCounter = 0
+	c.FailureCounter = 0
+}
@@ -2,27 +2,39 @@ package mocks
 
 import (
 	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"reflect"
+	"strings"
+	"sync"
 )
 
 type dynamoDBClient struct {
-	ScanCounter      int
-	ScanError        error
-	ScanOutput       *dynamodb.ScanOutput
-	GetItemCounter   int
-	GetItemError     error
-	GetItemOutput    *dynamodb.GetItemOutput
-	PutItemCounter   int
-	PutItemError     error
-	PutItemOutput    *dynamodb.PutItemOutput
-	clientItems      map[string]interface{}
-	credentialsItems map[string]interface{}
-	operationsItems  map[string]interface{}
+	ScanCounter               int
+	ScanError                 error
+	ScanOutput                *dynamodb.ScanOutput
+	GetItemCounter            int
+	GetItemError              error
+	GetItemOutput             *dynamodb.GetItemOutput
+	PutItemCounter            int
+	PutItemError              error
+	PutItemOutput             *dynamodb.PutItemOutput
+	UpdateItemCounter         int
+	UpdateItemError           error
+	TransactWriteItemsCounter int
+	TransactWriteItemsError   error
+	clientItems               map[string]interface{}
+	credentialsItems          map[string]interface{}
+	operationsItems           map[string]interface{}
+	outboxItems               map[string]interface{}
+	ledgerItems               map[string]interface{}
+	priceItems                map[string]interface{}
+	mutex                     sync.Mutex
 }
 
 func DynamoDBClient() *dynamoDBClient {
@@ -30,14 +42,41 @@ func DynamoDBClient() *dynamoDBClient {
 		clientItems:      map[string]interface{}{},
 		credentialsItems: map[string]interface{}{},
 		operationsItems:  map[string]interface{}{},
+		outboxItems:      map[string]interface{}{},
+		ledgerItems:      map[string]interface{}{},
+		priceItems:       map[string]interface{}{},
 	}
 }
 
-func (d *dynamoDBClient) Scan(_ context.Context, _ *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
-	return nil, nil
+func (d *dynamoDBClient) Scan(_ context.Context, params *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.ScanCounter++
+
+	if d.ScanError != nil {
+		return nil, d.ScanError
+	}
+
+	output := &dynamodb.ScanOutput{}
+	for key := range d.items(params.TableName) {
+		_, item := d.item(map[string]types.AttributeValue{
+			keyName(params.TableName): &types.AttributeValueMemberS{Value: key},
+		}, params.TableName)
+
+		if params.FilterExpression != nil && !d.conditionHolds(item, *params.FilterExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues) {
+			continue
+		}
+		output.Items = append(output.Items, item)
+	}
+
+	return output, nil
 }
 
 func (d *dynamoDBClient) GetItem(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	d.GetItemCounter++
 
 	if d.GetItemError != nil {
@@ -48,19 +87,14 @@ func (d *dynamoDBClient) GetItem(_ context.Context, params *dynamodb.GetItemInpu
 
 	_ = attributevalue.UnmarshalMap(params.Key, &request)
 
-	var item interface{}
-	if params.TableName == properties.Properties().Aws.DynamoDB.ClientTableName {
-		item = d.clientItems[request["client_id"]]
-	} else if params.TableName == properties.Properties().Aws.DynamoDB.OperationTableName {
-		item = d.operationsItems[request["operation_id"]]
-	} else if params.TableName == properties.Properties().Aws.DynamoDB.CredentialsTableName {
-		item = d.credentialsItems[request["client_id"]]
-	}
+	item := d.items(params.TableName)[request[keyName(params.TableName)]]
 
 	var itemOutput map[string]types.AttributeValue
 
 	if item == nil {
 		itemOutput = nil
+	} else if attributes, ok := item.(map[string]types.AttributeValue); ok {
+		itemOutput = attributes
 	} else {
 		itemOutput, _ = attributevalue.MarshalMap(item)
 	}
@@ -71,49 +105,302 @@ func (d *dynamoDBClient) GetItem(_ context.Context, params *dynamodb.GetItemInpu
 }
 
 func (d *dynamoDBClient) PutItem(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	d.PutItemCounter++
 
 	if d.PutItemError != nil && params.TableName == properties.Properties().Aws.DynamoDB.ClientTableName {
 		return nil, exceptions.DynamoDBClientPersistenceError(d.PutItemError, "PutItem error")
 	} else if d.PutItemError != nil && params.TableName == properties.Properties().Aws.DynamoDB.OperationTableName {
 		return nil, exceptions.DynamoDBOperationPersistenceError(d.PutItemError, "PutItem error")
+	} else if d.PutItemError != nil && params.TableName == properties.Properties().Aws.DynamoDB.OutboxTableName {
+		return nil, exceptions.DynamoDBOutboxPersistenceError(d.PutItemError, "PutItem error")
+	} else if d.PutItemError != nil && params.TableName == properties.Properties().Aws.DynamoDB.LedgerTableName {
+		return nil, exceptions.DynamoDBLedgerPersistenceError(d.PutItemError, "PutItem error")
 	}
 
+	if params.ConditionExpression != nil && !d.conditionHolds(d.stored(params.Item, params.TableName), *params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues) {
+		return nil, &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+
+	d.put(params.Item, params.TableName)
+
+	return nil, nil
+}
+
+// stored returns the item currently stored with the same key as the item received, an empty item is returned if
+// there is none.
+func (d *dynamoDBClient) stored(params map[string]types.AttributeValue, tableName *string) map[string]types.AttributeValue {
+	keys := map[string]types.AttributeValue{
+		keyName(tableName): params[keyName(tableName)],
+	}
+
+	key, item := d.item(keys, tableName)
+
+	if _, exists := d.items(tableName)[key]; !exists {
+		return map[string]types.AttributeValue{}
+	}
+	return item
+}
+
+func (d *dynamoDBClient) put(params map[string]types.AttributeValue, tableName *string) {
 	var item interface{}
 	var key string
-	if params.TableName == properties.Properties().Aws.DynamoDB.ClientTableName {
+	if tableName == properties.Properties().Aws.DynamoDB.ClientTableName {
 		client := &dto.Client{}
-		_ = attributevalue.UnmarshalMap(params.Item, &client)
+		_ = attributevalue.UnmarshalMap(params, &client)
 		item = client
 		key = client.Id
-	} else if params.TableName == properties.Properties().Aws.DynamoDB.OperationTableName {
+	} else if tableName == properties.Properties().Aws.DynamoDB.OperationTableName {
 		operation := &dto.Operation{}
-		_ = attributevalue.UnmarshalMap(params.Item, &operation)
+		_ = attributevalue.UnmarshalMap(params, &operation)
 		item = operation
 		key = operation.Id
-	} else if params.TableName == properties.Properties().Aws.DynamoDB.CredentialsTableName {
+	} else if tableName == properties.Properties().Aws.DynamoDB.CredentialsTableName {
 		credentials := &dto.Credentials{}
-		_ = attributevalue.UnmarshalMap(params.Item, &credentials)
+		_ = attributevalue.UnmarshalMap(params, &credentials)
 		item = credentials
 		key = credentials.ClientId
+	} else if tableName == properties.Properties().Aws.DynamoDB.OutboxTableName {
+		entry := &dto.OutboxEntry{}
+		_ = attributevalue.UnmarshalMap(params, &entry)
+		item = entry
+		key = entry.Id
+	} else if tableName == properties.Properties().Aws.DynamoDB.LedgerTableName {
+		ledger := &dto.Ledger{}
+		_ = attributevalue.UnmarshalMap(params, &ledger)
+		item = ledger
+		key = ledger.ClientId
+	} else if tableName == properties.Properties().Aws.DynamoDB.PriceTableName {
+		price := &dto.Price{}
+		_ = attributevalue.UnmarshalMap(params, &price)
+		item = price
+		key = price.Pair
 	}
 
-	d.AddItem(key, item, params.TableName)
+	d.addItem(key, item, tableName)
+}
 
-	return nil, nil
+// item returns the item key and the item stored as attribute values, an empty item is returned if key is not found.
+func (d *dynamoDBClient) item(params map[string]types.AttributeValue, tableName *string) (string, map[string]types.AttributeValue) {
+	request := map[string]string{}
+	_ = attributevalue.UnmarshalMap(params, &request)
+
+	key := request[keyName(tableName)]
+	stored := d.items(tableName)[key]
+
+	item := map[string]types.AttributeValue{}
+	if attributes, ok := stored.(map[string]types.AttributeValue); ok {
+		for name, value := range attributes {
+			item[name] = value
+		}
+	} else if stored != nil {
+		item, _ = attributevalue.MarshalMap(stored)
+	}
+
+	for name, value := range params {
+		item[name] = value
+	}
+
+	return key, item
+}
+
+// update evaluates "SET a = :a, b = :b REMOVE c, d" update expressions, item is stored as attribute values so attributes
+// unknown to the dto are kept.
+func (d *dynamoDBClient) update(key string, item map[string]types.AttributeValue, tableName *string, updateExpression string, names map[string]string, values map[string]types.AttributeValue) {
+	setExpression, removeExpression, _ := strings.Cut(updateExpression, " REMOVE ")
+	for _, assignment := range strings.Split(strings.TrimPrefix(setExpression, "SET "), ",") {
+		operands := strings.Split(assignment, "=")
+		item[attributeName(operands[0], names)] = values[strings.TrimSpace(operands[1])]
+	}
+	if removeExpression != "" {
+		for _, name := range strings.Split(removeExpression, ",") {
+			delete(item, attributeName(name, names))
+		}
+	}
+
+	d.addItem(key, item, tableName)
+}
+
+// conditionHolds evaluates "a = :a AND b <> :b OR attribute_not_exists(c)" condition expressions, AND is evaluated
+// before OR.
+func (d *dynamoDBClient) conditionHolds(item map[string]types.AttributeValue, conditionExpression string, names map[string]string, values map[string]types.AttributeValue) bool {
+	for _, alternative := range strings.Split(conditionExpression, " OR ") {
+		if d.conditionsHold(item, alternative, names, values) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *dynamoDBClient) conditionsHold(item map[string]types.AttributeValue, conditionExpression string, names map[string]string, values map[string]types.AttributeValue) bool {
+	for _, condition := range strings.Split(conditionExpression, " AND ") {
+		condition = strings.TrimSpace(condition)
+		if strings.HasPrefix(condition, "attribute_not_exists(") {
+			name := attributeName(strings.TrimSuffix(strings.TrimPrefix(condition, "attribute_not_exists("), ")"), names)
+			if _, ok := item[name]; ok {
+				return false
+			}
+			continue
+		}
+
+		operator := "="
+		if strings.Contains(condition, "<>") {
+			operator = "<>"
+		}
+
+		operands := strings.Split(condition, operator)
+		value, ok := item[attributeName(operands[0], names)]
+		if !ok || reflect.DeepEqual(value, values[strings.TrimSpace(operands[1])]) != (operator == "=") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func attributeName(name string, names map[string]string) string {
+	name = strings.TrimSpace(name)
+	if attributeName, ok := names[name]; ok {
+		return attributeName
+	}
+	return name
+}
+
+func (d *dynamoDBClient) UpdateItem(_ context.Context, params *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.UpdateItemCounter++
+
+	if d.UpdateItemError != nil {
+		return nil, exceptions.DynamoDBClientPersistenceError(d.UpdateItemError, "UpdateItem error")
+	}
+
+	key, item := d.item(params.Key, params.TableName)
+
+	if params.ConditionExpression != nil && !d.conditionHolds(item, *params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues) {
+		return nil, &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+
+	d.update(key, item, params.TableName, *params.UpdateExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (d *dynamoDBClient) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, _ ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.TransactWriteItemsCounter++
+
+	if d.TransactWriteItemsError != nil {
+		return nil, d.TransactWriteItemsError
+	}
+
+	var reasons []types.CancellationReason
+	canceled := false
+	for _, transactItem := range params.TransactItems {
+		if transactItem.Update != nil && transactItem.Update.ConditionExpression != nil {
+			_, item := d.item(transactItem.Update.Key, transactItem.Update.TableName)
+			if !d.conditionHolds(item, *transactItem.Update.ConditionExpression, transactItem.Update.ExpressionAttributeNames, transactItem.Update.ExpressionAttributeValues) {
+				reasons = append(reasons, types.CancellationReason{Code: aws.String("ConditionalCheckFailed")})
+				canceled = true
+				continue
+			}
+		}
+		if transactItem.Put != nil && transactItem.Put.ConditionExpression != nil {
+			item := d.stored(transactItem.Put.Item, transactItem.Put.TableName)
+			if !d.conditionHolds(item, *transactItem.Put.ConditionExpression, transactItem.Put.ExpressionAttributeNames, transactItem.Put.ExpressionAttributeValues) {
+				reasons = append(reasons, types.CancellationReason{Code: aws.String("ConditionalCheckFailed")})
+				canceled = true
+				continue
+			}
+		}
+		reasons = append(reasons, types.CancellationReason{Code: aws.String("None")})
+	}
+	if canceled {
+		return nil, &types.TransactionCanceledException{Message: aws.String("Transaction cancelled"), CancellationReasons: reasons}
+	}
+
+	for _, transactItem := range params.TransactItems {
+		if transactItem.Put != nil {
+			d.put(transactItem.Put.Item, transactItem.Put.TableName)
+		}
+		if transactItem.Update != nil {
+			key, item := d.item(transactItem.Update.Key, transactItem.Update.TableName)
+			d.update(key, item, transactItem.Update.TableName, *transactItem.Update.UpdateExpression, transactItem.Update.ExpressionAttributeNames, transactItem.Update.ExpressionAttributeValues)
+		}
+	}
+
+	return &dynamodb.TransactWriteItemsOutput{}, nil
 }
 
 func (d *dynamoDBClient) AddItem(key string, value interface{}, tableName *string) {
-	if tableName == properties.Properties().Aws.DynamoDB.ClientTableName {
-		d.clientItems[key] = value
-	} else if tableName == properties.Properties().Aws.DynamoDB.OperationTableName {
-		d.operationsItems[key] = value
-	} else if tableName == properties.Properties().Aws.DynamoDB.CredentialsTableName {
-		d.credentialsItems[key] = value
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.addItem(key, value, tableName)
+}
+
+func (d *dynamoDBClient) addItem(key string, value interface{}, tableName *string) {
+	if items := d.items(tableName); items != nil {
+		items[key] = value
 	}
 }
 
+// items returns the items stored for the table.
+func (d *dynamoDBClient) items(tableName *string) map[string]interface{} {
+	switch tableName {
+	case properties.Properties().Aws.DynamoDB.ClientTableName:
+		return d.clientItems
+	case properties.Properties().Aws.DynamoDB.OperationTableName:
+		return d.operationsItems
+	case properties.Properties().Aws.DynamoDB.CredentialsTableName:
+		return d.credentialsItems
+	case properties.Properties().Aws.DynamoDB.OutboxTableName:
+		return d.outboxItems
+	case properties.Properties().Aws.DynamoDB.LedgerTableName:
+		return d.ledgerItems
+	case properties.Properties().Aws.DynamoDB.PriceTableName:
+		return d.priceItems
+	}
+	return nil
+}
+
+// keyName returns the name of the table hash key.
+func keyName(tableName *string) string {
+	switch tableName {
+	case properties.Properties().Aws.DynamoDB.OperationTableName:
+		return "operation_id"
+	case properties.Properties().Aws.DynamoDB.OutboxTableName:
+		return "outbox_id"
+	case properties.Properties().Aws.DynamoDB.PriceTableName:
+		return "pair"
+	}
+	return "client_id"
+}
+
+func (d *dynamoDBClient) NumberOfOperations() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return len(d.operationsItems)
+}
+
+func (d *dynamoDBClient) NumberOfOutboxEntries() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return len(d.outboxItems)
+}
+
 func (d *dynamoDBClient) Reset() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	d.ScanCounter = 0
 	d.ScanError = nil
 	d.ScanOutput = &dynamodb.ScanOutput{}
@@ -123,7 +410,14 @@ func (d *dynamoDBClient) Reset() {
 	d.PutItemCounter = 0
 	d.PutItemError = nil
 	d.PutItemOutput = &dynamodb.PutItemOutput{}
+	d.UpdateItemCounter = 0
+	d.UpdateItemError = nil
+	d.TransactWriteItemsCounter = 0
+	d.TransactWriteItemsError = nil
 	d.clientItems = map[string]interface{}{}
 	d.credentialsItems = map[string]interface{}{}
 	d.operationsItems = map[string]interface{}{}
+	d.outboxItems = map[string]interface{}{}
+	d.ledgerItems = map[string]interface{}{}
+	d.priceItems = map[string]interface{}{}
 }
@@ -1,26 +1,39 @@
 package mocks
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 )
 
 type dynamoDBClientPersistence struct {
-	GetClientCounter int
-	GetClientError   error
-	LockCounter      int
-	LockError        error
-	UnlockCounter    int
-	UnlockError      error
-	clientsAvailable []*model.Client
+	GetClientCounter        int
+	GetClientError          error
+	GetLockedClientsCounter int
+	GetLockedClientsError   error
+	LockCounter             int
+	LockError               error
+	LockUntilCounter        int
+	LockUntilError          error
+	SaveReservationCounter  int
+	SaveReservationError    error
+	ReleaseCounter          int
+	ReleaseError            error
+	UnlockCounter           int
+	UnlockError             error
+	ReleaseLockCounter      int
+	ReleaseLockError        error
+	OperationPersistence    *dynamoDBOperationPersistence
+	OutboxPersistence       *dynamoDBOutboxPersistence
+	clientsAvailable        []*model.Client
 }
 
 func DynamoDBClientPersistence() *dynamoDBClientPersistence {
 	return &dynamoDBClientPersistence{}
 }
 
-func (d *dynamoDBClientPersistence) GetClient(clientId string) (*model.Client, custom_error.BaseErrorAdapter) {
+func (d *dynamoDBClientPersistence) GetClient(_ context.Context, clientId string) (*model.Client, custom_error.BaseErrorAdapter) {
 	d.GetClientCounter++
 
 	if d.GetClientError != nil {
@@ -37,7 +50,23 @@ func (d *dynamoDBClientPersistence) GetClient(clientId string) (*model.Client, c
 	return nil, nil
 }
 
-func (d *dynamoDBClientPersistence) Lock(client *model.Client) custom_error.BaseErrorAdapter {
+func (d *dynamoDBClientPersistence) GetLockedClients(_ context.Context) ([]*model.Client, custom_error.BaseErrorAdapter) {
+	d.GetLockedClientsCounter++
+
+	if d.GetLockedClientsError != nil {
+		return nil, exceptions.DynamoDBClientPersistenceError(d.GetLockedClientsError, "GetLockedClients error")
+	}
+
+	var clients []*model.Client
+	for _, client := range d.clientsAvailable {
+		if client.Locked {
+			clients = append(clients, client)
+		}
+	}
+	return clients, nil
+}
+
+func (d *dynamoDBClientPersistence) Lock(_ context.Context, client *model.Client) custom_error.BaseErrorAdapter {
 	d.LockCounter++
 
 	if d.LockError != nil {
@@ -51,9 +80,58 @@ func (d *dynamoDBClientPersistence) Lock(client *model.Client) custom_error.Base
 	return nil
 }
 
-func (d *dynamoDBClientPersistence) Unlock(client *model.Client) custom_error.BaseErrorAdapter {
+func (d *dynamoDBClientPersistence) LockUntil(context.Context, *model.Client) custom_error.BaseErrorAdapter {
+	d.LockUntilCounter++
+
+	if d.LockUntilError != nil {
+		return exceptions.DynamoDBClientPersistenceError(d.LockUntilError, "LockUntil error")
+	}
+
+	return nil
+}
+
+// SaveReservation adds the operation to OperationPersistence and the event to OutboxPersistence when set, as all of
+// them are written in the same transaction.
+func (d *dynamoDBClientPersistence) SaveReservation(_ context.Context, _ *model.Client, operation *model.Operation, event *model.OutboxEntry) custom_error.BaseErrorAdapter {
+	d.SaveReservationCounter++
+
+	if d.SaveReservationError != nil || operation == nil {
+		baseError := exceptions.DynamoDBClientPersistenceError(d.SaveReservationError, "SaveReservation error")
+		baseError.SetLocks(true, true)
+		return baseError
+	}
+
+	if d.OperationPersistence != nil {
+		d.OperationPersistence.AddOperation(operation)
+	}
+	if d.OutboxPersistence != nil {
+		d.OutboxPersistence.AddEntry(event)
+	}
+
+	return nil
+}
+
+func (d *dynamoDBClientPersistence) ReleaseReservation(context.Context, *model.Client, *model.Operation) custom_error.BaseErrorAdapter {
+	d.ReleaseCounter++
+
+	if d.ReleaseError != nil {
+		baseError := exceptions.DynamoDBClientPersistenceError(d.ReleaseError, "ReleaseReservation error")
+		baseError.SetLocks(true, true)
+		return baseError
+	}
+
+	return nil
+}
+
+func (d *dynamoDBClientPersistence) Unlock(ctx context.Context, client *model.Client) custom_error.BaseErrorAdapter {
 	d.UnlockCounter++
 
+	if ctx.Err() != nil {
+		baseError := exceptions.DynamoDBClientPersistenceError(ctx.Err(), "Unlock context error")
+		baseError.SetLocks(true, true)
+		return baseError
+	}
+
 	if d.UnlockError != nil {
 		baseError := exceptions.DynamoDBClientPersistenceError(d.UnlockError, "Unlock error")
 		baseError.SetLocks(true, true)
@@ -65,6 +143,18 @@ func (d *dynamoDBClientPersistence) Unlock(client *model.Client) custom_error.Ba
 	return nil
 }
 
+func (d *dynamoDBClientPersistence) ReleaseLock(_ context.Context, client *model.Client) custom_error.BaseErrorAdapter {
+	d.ReleaseLockCounter++
+
+	if d.ReleaseLockError != nil {
+		return exceptions.DynamoDBClientPersistenceError(d.ReleaseLockError, "ReleaseLock error")
+	}
+
+	client.Unlock()
+
+	return nil
+}
+
 func (d *dynamoDBClientPersistence) AddClient(client *model.Client) {
 	d.clientsAvailable = append(d.clientsAvailable, client)
 }
@@ -72,9 +162,19 @@ func (d *dynamoDBClientPersistence) AddClient(client *model.Client) {
 func (d *dynamoDBClientPersistence) Reset() {
 	d.GetClientCounter = 0
 	d.GetClientError = nil
+	d.GetLockedClientsCounter = 0
+	d.GetLockedClientsError = nil
 	d.LockCounter = 0
 	d.LockError = nil
+	d.LockUntilCounter = 0
+	d.LockUntilError = nil
+	d.SaveReservationCounter = 0
+	d.SaveReservationError = nil
+	d.ReleaseCounter = 0
+	d.ReleaseError = nil
 	d.UnlockCounter = 0
 	d.UnlockError = nil
+	d.ReleaseLockCounter = 0
+	d.ReleaseLockError = nil
 	d.clientsAvailable = []*model.Client{}
 }
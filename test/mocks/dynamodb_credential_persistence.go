@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
@@ -16,7 +17,7 @@ func DynamoDBCredentialPersistence() *dynamoDBCredentialPersistence {
 	return &dynamoDBCredentialPersistence{}
 }
 
-func (d *dynamoDBCredentialPersistence) GetCredentials(clientId string) (*dto.Credentials, custom_error.BaseErrorAdapter) {
+func (d *dynamoDBCredentialPersistence) GetCredentials(_ context.Context, clientId string) (*dto.Credentials, custom_error.BaseErrorAdapter) {
 	d.GetCredentialsCounter++
 
 	if d.GetCredentialsError != nil {
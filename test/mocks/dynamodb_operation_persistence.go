@@ -1,14 +1,15 @@
 package mocks
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 )
 
 type dynamoDBOperationPersistence struct {
-	SaveCounter         int
-	SaveError           error
+	GetCounter          int
+	GetError            error
 	operationsAvailable []*model.Operation
 }
 
@@ -16,23 +17,31 @@ func DynamoDBOperationPersistence() *dynamoDBOperationPersistence {
 	return &dynamoDBOperationPersistence{}
 }
 
-func (d *dynamoDBOperationPersistence) Save(operation *model.Operation) custom_error.BaseErrorAdapter {
-	d.SaveCounter++
+func (d *dynamoDBOperationPersistence) Get(_ context.Context, operationId string) (*model.Operation, custom_error.BaseErrorAdapter) {
+	d.GetCounter++
 
-	if d.SaveError != nil || operation == nil {
-		return exceptions.DynamoDBOperationPersistenceError(d.SaveError, "save error")
+	if d.GetError != nil {
+		return nil, exceptions.DynamoDBOperationPersistenceError(d.GetError, "get error")
 	}
 
-	//for _, operationSaved := range d.operationsAvailable {
-	//	if operation.Id == operationSaved.Id {
-	//		operationSaved = operation
-	//		return nil
-	//	}
-	//}
+	for _, operation := range d.operationsAvailable {
+		if operation.Id == operationId {
+			return operation, nil
+		}
+	}
 
-	d.operationsAvailable = append(d.operationsAvailable, operation)
+	return nil, nil
+}
 
-	return nil
+// AddOperation stores the operation, replacing the stored operation with the same id.
+func (d *dynamoDBOperationPersistence) AddOperation(operation *model.Operation) {
+	for i, stored := range d.operationsAvailable {
+		if stored.Id == operation.Id {
+			d.operationsAvailable[i] = operation
+			return
+		}
+	}
+	d.operationsAvailable = append(d.operationsAvailable, operation)
 }
 
 func (d *dynamoDBOperationPersistence) GetAllOperations() []*model.Operation {
@@ -40,7 +49,7 @@ func (d *dynamoDBOperationPersistence) GetAllOperations() []*model.Operation {
 }
 
 func (d *dynamoDBOperationPersistence) Reset() {
-	d.SaveCounter = 0
-	d.SaveError = nil
+	d.GetCounter = 0
+	d.GetError = nil
 	d.operationsAvailable = []*model.Operation{}
 }
@@ -0,0 +1,121 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type dynamoDBOutboxPersistence struct {
+	SaveCounter         int
+	SaveError           error
+	GetCounter          int
+	GetError            error
+	GetPendingCounter   int
+	GetPendingError     error
+	UpdateStatusCounter int
+	UpdateStatusError   error
+	entries             []*model.OutboxEntry
+}
+
+func DynamoDBOutboxPersistence() *dynamoDBOutboxPersistence {
+	return &dynamoDBOutboxPersistence{}
+}
+
+func (d *dynamoDBOutboxPersistence) Save(_ context.Context, entry *model.OutboxEntry) custom_error.BaseErrorAdapter {
+	d.SaveCounter++
+
+	if d.SaveError != nil {
+		return exceptions.DynamoDBOutboxPersistenceError(d.SaveError, "save error")
+	}
+
+	d.entries = append(d.entries, entry)
+
+	return nil
+}
+
+func (d *dynamoDBOutboxPersistence) Get(_ context.Context, entryId string) (*model.OutboxEntry, custom_error.BaseErrorAdapter) {
+	d.GetCounter++
+
+	if d.GetError != nil {
+		return nil, exceptions.DynamoDBOutboxPersistenceError(d.GetError, "get error")
+	}
+
+	for _, entry := range d.entries {
+		if entry.Id == entryId {
+			return entry, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (d *dynamoDBOutboxPersistence) GetPending(_ context.Context, outboxType outbox_type.OutboxType) ([]*model.OutboxEntry, custom_error.BaseErrorAdapter) {
+	d.GetPendingCounter++
+
+	if d.GetPendingError != nil {
+		return nil, exceptions.DynamoDBOutboxPersistenceError(d.GetPendingError, "get pending error")
+	}
+
+	var entries []*model.OutboxEntry
+	for _, entry := range d.entries {
+		if entry.Type == outboxType && entry.Status == outbox_status.Pending {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// UpdateStatus updates the stored entry with the same id, only pending entries are updated.
+func (d *dynamoDBOutboxPersistence) UpdateStatus(_ context.Context, entry *model.OutboxEntry, status outbox_status.OutboxStatus) custom_error.BaseErrorAdapter {
+	d.UpdateStatusCounter++
+
+	if d.UpdateStatusError != nil {
+		return exceptions.DynamoDBOutboxPersistenceError(d.UpdateStatusError, "update status error")
+	}
+
+	for _, stored := range d.entries {
+		if stored.Id == entry.Id && stored.Status == outbox_status.Pending {
+			stored.Status = status
+			entry.Status = status
+			return nil
+		}
+	}
+
+	baseError := exceptions.DynamoDBOutboxPersistenceError(errors.New("entry not pending"), "update status error")
+	baseError.SetCode(error_code.OutboxEntryModified.Name())
+	return baseError
+}
+
+// AddEntry stores the entry, replacing the stored entry with the same id.
+func (d *dynamoDBOutboxPersistence) AddEntry(entry *model.OutboxEntry) {
+	for i, stored := range d.entries {
+		if stored.Id == entry.Id {
+			d.entries[i] = entry
+			return
+		}
+	}
+	d.entries = append(d.entries, entry)
+}
+
+func (d *dynamoDBOutboxPersistence) GetAllEntries() []*model.OutboxEntry {
+	return d.entries
+}
+
+func (d *dynamoDBOutboxPersistence) Reset() {
+	d.SaveCounter = 0
+	d.SaveError = nil
+	d.GetCounter = 0
+	d.GetError = nil
+	d.GetPendingCounter = 0
+	d.GetPendingError = nil
+	d.UpdateStatusCounter = 0
+	d.UpdateStatusError = nil
+	d.entries = []*model.OutboxEntry{}
+}
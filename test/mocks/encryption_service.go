@@ -10,6 +10,7 @@ import (
 type encryptionService struct {
 	service              adapters.EncryptionServiceAdapter
 	SHA384EncryptCounter int
+	SHA256EncryptCounter int
 	AESDecryptCounter    int
 	AESDecryptError      error
 	AESEncryptCounter    int
@@ -39,8 +40,14 @@ func (e *encryptionService) SHA384Encrypt(string string, secret string) string {
 	return e.service.SHA384Encrypt(string, secret)
 }
 
+func (e *encryptionService) SHA256Encrypt(string string, secret string) string {
+	e.SHA256EncryptCounter++
+	return e.service.SHA256Encrypt(string, secret)
+}
+
 func (e *encryptionService) Reset() {
 	e.SHA384EncryptCounter = 0
+	e.SHA256EncryptCounter = 0
 	e.AESDecryptCounter = 0
 	e.AESDecryptError = nil
 	e.AESEncryptCounter = 0
@@ -0,0 +1,68 @@
+package mocks
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/exchange"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type exchangeRegistry struct {
+	GetCounter     int
+	GetExchanges   []exchange.Exchange
+	Supported      map[exchange.Exchange]bool
+	ClientService  adapters.ClientServiceAdapter
+	CryptoService  adapters.CryptoServiceAdapter
+	CircuitBreaker adapters.CircuitBreakerAdapter
+}
+
+// ExchangeRegistry returns every supported exchange with the same services, GetBalance is sent to clientService and
+// GetCrypto to cryptoService.
+func ExchangeRegistry(clientService adapters.ClientServiceAdapter, cryptoService adapters.CryptoServiceAdapter, breaker adapters.CircuitBreakerAdapter) *exchangeRegistry {
+	return &exchangeRegistry{
+		Supported:      supportedExchanges(),
+		ClientService:  clientService,
+		CryptoService:  cryptoService,
+		CircuitBreaker: breaker,
+	}
+}
+
+func (e *exchangeRegistry) Get(exchange exchange.Exchange) (adapters.CryptoServiceAdapter, adapters.CircuitBreakerAdapter, custom_error.BaseErrorAdapter) {
+	e.GetCounter++
+	e.GetExchanges = append(e.GetExchanges, exchange)
+
+	if !e.Supported[exchange] {
+		return nil, nil, exceptions.ExchangeRegistryError(nil, "Exchange "+exchange.Name()+" is not registered")
+	}
+
+	return &exchangeService{clientService: e.ClientService, cryptoService: e.CryptoService}, e.CircuitBreaker, nil
+}
+
+func (e *exchangeRegistry) Reset() {
+	e.GetCounter = 0
+	e.GetExchanges = nil
+	e.Supported = supportedExchanges()
+}
+
+func supportedExchanges() map[exchange.Exchange]bool {
+	return map[exchange.Exchange]bool{
+		exchange.Biscoint: true,
+		exchange.Binance:  true,
+	}
+}
+
+type exchangeService struct {
+	clientService adapters.ClientServiceAdapter
+	cryptoService adapters.CryptoServiceAdapter
+}
+
+func (e *exchangeService) GetCrypto(ctx context.Context, symbol symbol.Symbol, quote symbol.Symbol) (*model.Coin, custom_error.BaseErrorAdapter) {
+	return e.cryptoService.GetCrypto(ctx, symbol, quote)
+}
+
+func (e *exchangeService) GetBalance(ctx context.Context, clientId string) (*model.Balance, custom_error.BaseErrorAdapter) {
+	return e.clientService.GetBalance(ctx, clientId)
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 	"net/http"
@@ -9,13 +10,15 @@ import (
 type headerBuilder struct {
 	BiscointHeaderCounter int
 	BiscointHeaderError   error
+	BinanceHeaderCounter  int
+	BinanceHeaderError    error
 }
 
 func HeaderBuilder() *headerBuilder {
 	return &headerBuilder{}
 }
 
-func (h *headerBuilder) BiscointHeader(_ string, _ string, _ any) (http.Header, custom_error.BaseErrorAdapter) {
+func (h *headerBuilder) BiscointHeader(_ context.Context, _ string, _ string, _ any) (http.Header, custom_error.BaseErrorAdapter) {
 	h.BiscointHeaderCounter++
 
 	if h.BiscointHeaderError != nil {
@@ -25,7 +28,21 @@ func (h *headerBuilder) BiscointHeader(_ string, _ string, _ any) (http.Header,
 	return http.Header{}, nil
 }
 
+func (h *headerBuilder) BinanceHeader(_ context.Context, _ string, query string) (http.Header, string, custom_error.BaseErrorAdapter) {
+	h.BinanceHeaderCounter++
+
+	if h.BinanceHeaderError != nil {
+		return nil, "", exceptions.HeaderBuilderError(h.BinanceHeaderError, "header builder error")
+	}
+
+	headers := http.Header{}
+	headers.Set("X-MBX-APIKEY", "api-key")
+	return headers, query + "&signature=signature", nil
+}
+
 func (h *headerBuilder) Reset() {
 	h.BiscointHeaderCounter = 0
 	h.BiscointHeaderError = nil
+	h.BinanceHeaderCounter = 0
+	h.BinanceHeaderError = nil
 }
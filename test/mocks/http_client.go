@@ -2,8 +2,11 @@ package mocks
 
 import (
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 )
 
 type httpClient struct {
@@ -11,30 +14,49 @@ type httpClient struct {
 	DoError            error
 	Server             *httptest.Server
 	StatusCode         int
+	StatusCodes        []int
+	RetryAfter         string
+	RequestHeaders     []http.Header
 	GetCryptoResponse  string
 	GetBalanceResponse string
+	SigningCertStatus  int
+	SigningCertPEM     string
+	mutex              sync.Mutex
 }
 
 func HttpClient() *httpClient {
 	return &httpClient{
-		StatusCode: 200,
+		StatusCode:        200,
+		SigningCertStatus: 200,
 	}
 }
 
 func (h *httpClient) Do(req *http.Request) (*http.Response, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
 	h.DoCounter++
+	h.RequestHeaders = append(h.RequestHeaders, req.Header.Clone())
 
 	if h.DoError != nil {
 		return nil, h.DoError
 	}
 
+	if strings.HasSuffix(req.URL.Hostname(), ".amazonaws.com") {
+		return &http.Response{
+			StatusCode: h.SigningCertStatus,
+			Status:     http.StatusText(h.SigningCertStatus),
+			Body:       io.NopCloser(strings.NewReader(h.SigningCertPEM)),
+		}, nil
+	}
+
 	realClient := http.Client{}
 	return realClient.Do(req)
 }
 
 func (h *httpClient) SetupServer() {
 	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(h.StatusCode)
+		w.WriteHeader(h.statusCode(w))
 		var response []byte
 		if r.URL.Path == "/"+properties.Properties().BiscointGetBalancePath {
 			response = []byte(h.GetBalanceResponse)
@@ -46,6 +68,20 @@ func (h *httpClient) SetupServer() {
 	}))
 }
 
+// statusCode returns the next code of StatusCodes while there are codes left, StatusCode after that. RetryAfter is
+// sent with every status code that is not Ok. Only called by the server while Do holds the mutex.
+func (h *httpClient) statusCode(w http.ResponseWriter) int {
+	statusCode := h.StatusCode
+	if len(h.StatusCodes) > 0 {
+		statusCode, h.StatusCodes = h.StatusCodes[0], h.StatusCodes[1:]
+	}
+	if statusCode != http.StatusOK && h.RetryAfter != "" {
+		w.Header().Set("Retry-After", h.RetryAfter)
+	}
+
+	return statusCode
+}
+
 func (h *httpClient) GetUrl() string {
 	return h.Server.URL
 }
@@ -59,6 +95,11 @@ func (h *httpClient) Reset() {
 	h.DoCounter = 0
 	h.DoError = nil
 	h.StatusCode = 200
+	h.StatusCodes = nil
+	h.RetryAfter = ""
+	h.RequestHeaders = nil
 	h.GetCryptoResponse = ""
 	h.GetBalanceResponse = ""
+	h.SigningCertStatus = 200
+	h.SigningCertPEM = ""
 }
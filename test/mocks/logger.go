@@ -1,10 +1,19 @@
 package mocks
 
+import (
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"sync"
+)
+
 type loggerMock struct {
 	CorrelationId      string
 	InfoCallCounter    int
 	ErrorCallCounter   int
 	WarningCallCounter int
+	CorrelationIds     []string
+	LastError          error
+	LastErrorMessage   string
+	mutex              sync.Mutex
 }
 
 func Logger() *loggerMock {
@@ -12,24 +21,47 @@ func Logger() *loggerMock {
 }
 
 func (l *loggerMock) SetCorrelationID(id string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	l.CorrelationId = id
 }
 
+// Factory returns an adapters.LoggerFactory that records each correlation id and returns this mock.
+func (l *loggerMock) Factory(correlationId string) adapters.LoggerAdapter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.CorrelationIds = append(l.CorrelationIds, correlationId)
+	return l
+}
+
 func (l *loggerMock) Info(string, ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	l.InfoCallCounter++
 }
 
-func (l *loggerMock) Error(error, string, ...interface{}) {
+func (l *loggerMock) Error(err error, message string, _ ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	l.ErrorCallCounter++
+	l.LastError = err
+	l.LastErrorMessage = message
 }
 
 func (l *loggerMock) Warning(error, string, ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	l.WarningCallCounter++
 }
 
 func (l *loggerMock) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	l.CorrelationId = ""
 	l.InfoCallCounter = 0
 	l.ErrorCallCounter = 0
 	l.WarningCallCounter = 0
+	l.CorrelationIds = nil
+	l.LastError = nil
+	l.LastErrorMessage = ""
 }
@@ -0,0 +1,66 @@
+package mocks
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+)
+
+type paperExchange struct {
+	ExchangeCounter int
+	PriceFeed       bool
+	ReserveCounter  int
+	ReserveError    error
+	ReleaseCounter  int
+	ReleaseError    error
+	Reserved        map[string]bool
+}
+
+// PaperExchange returns the exchange service received as the paper exchange service, reservations are recorded by
+// operation id.
+func PaperExchange() *paperExchange {
+	return &paperExchange{
+		Reserved: map[string]bool{},
+	}
+}
+
+func (p *paperExchange) Exchange(exchangeService adapters.CryptoServiceAdapter) adapters.CryptoServiceAdapter {
+	p.ExchangeCounter++
+	return exchangeService
+}
+
+func (p *paperExchange) RecordedPriceFeed() bool {
+	return p.PriceFeed
+}
+
+func (p *paperExchange) Reserve(_ context.Context, _ string, operation *model.Operation) custom_error.BaseErrorAdapter {
+	p.ReserveCounter++
+	if p.ReserveError != nil {
+		return exceptions.PaperExchangeError(p.ReserveError, "Reserve error")
+	}
+
+	p.Reserved[operation.Id] = true
+	return nil
+}
+
+func (p *paperExchange) Release(_ context.Context, _ string, operation *model.Operation) custom_error.BaseErrorAdapter {
+	p.ReleaseCounter++
+	if p.ReleaseError != nil {
+		return exceptions.PaperExchangeError(p.ReleaseError, "Release error")
+	}
+
+	delete(p.Reserved, operation.Id)
+	return nil
+}
+
+func (p *paperExchange) Reset() {
+	p.ExchangeCounter = 0
+	p.PriceFeed = false
+	p.ReserveCounter = 0
+	p.ReserveError = nil
+	p.ReleaseCounter = 0
+	p.ReleaseError = nil
+	p.Reserved = map[string]bool{}
+}
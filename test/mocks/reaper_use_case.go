@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+)
+
+type reaperUseCaseMock struct {
+	ReapCallCounter int
+	ReapError       error
+	ReaperReport    *model.ReaperReport
+	Loggers         []adapters.LoggerAdapter
+}
+
+func ReaperUseCase() *reaperUseCaseMock {
+	return &reaperUseCaseMock{}
+}
+
+// Factory returns an adapters.ReaperUseCaseFactory that records the logger of each execution and returns this mock.
+func (r *reaperUseCaseMock) Factory(logger adapters.LoggerAdapter) adapters.ReaperUseCaseAdapter {
+	r.Loggers = append(r.Loggers, logger)
+	return r
+}
+
+func (r *reaperUseCaseMock) Reap(_ context.Context) (*model.ReaperReport, error) {
+	r.ReapCallCounter++
+
+	if r.ReapError != nil {
+		return nil, r.ReapError
+	}
+	return r.ReaperReport, nil
+}
+
+func (r *reaperUseCaseMock) Reset() {
+	r.ReapCallCounter = 0
+	r.ReapError = nil
+	r.ReaperReport = &model.ReaperReport{Released: []string{}, Held: []string{}, Failed: []string{}}
+	r.Loggers = nil
+}
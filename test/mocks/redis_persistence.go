@@ -1,16 +1,22 @@
 package mocks
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 )
 
 type redisPersistence struct {
-	LockCounter   int
-	LockError     error
-	UnlockCounter int
-	UnlockError   error
-	lock          map[string]string
+	LockCounter     int
+	LockError       error
+	UnlockCounter   int
+	UnlockError     error
+	ExtendCounter   int
+	ExtendError     error
+	ExistsCounter   int
+	ExistsError     error
+	WatchdogCounter int
+	lock            map[string]string
 }
 
 func RedisPersistence() *redisPersistence {
@@ -19,7 +25,7 @@ func RedisPersistence() *redisPersistence {
 	}
 }
 
-func (r *redisPersistence) Lock(key string) custom_error.BaseErrorAdapter {
+func (r *redisPersistence) Lock(_ context.Context, key string) custom_error.BaseErrorAdapter {
 	r.LockCounter++
 
 	if r.LockError != nil {
@@ -35,9 +41,13 @@ func (r *redisPersistence) Lock(key string) custom_error.BaseErrorAdapter {
 	return nil
 }
 
-func (r *redisPersistence) Unlock(key string) custom_error.BaseErrorAdapter {
+func (r *redisPersistence) Unlock(ctx context.Context, key string) custom_error.BaseErrorAdapter {
 	r.UnlockCounter++
 
+	if ctx.Err() != nil {
+		return exceptions.RedisPersistenceLockError(ctx.Err(), "Unlock context error", true)
+	}
+
 	if r.UnlockError != nil {
 		return exceptions.RedisPersistenceLockError(r.UnlockError, "Unlock error", true)
 	}
@@ -51,6 +61,34 @@ func (r *redisPersistence) Unlock(key string) custom_error.BaseErrorAdapter {
 	return nil
 }
 
+func (r *redisPersistence) Extend(_ context.Context, key string) custom_error.BaseErrorAdapter {
+	r.ExtendCounter++
+
+	if r.ExtendError != nil {
+		return exceptions.RedisPersistenceLockError(r.ExtendError, "Extend error", true)
+	}
+
+	if _, isLocked := r.lock[key]; !isLocked {
+		return exceptions.RedisPersistenceLockError(r.ExtendError, "key is not locked", false)
+	}
+
+	return nil
+}
+
+func (r *redisPersistence) Exists(_ context.Context, key string) (bool, custom_error.BaseErrorAdapter) {
+	r.ExistsCounter++
+
+	if r.ExistsError != nil {
+		return false, exceptions.RedisPersistenceLockError(r.ExistsError, "Exists error", false)
+	}
+
+	return r.IsLocked(key), nil
+}
+
+func (r *redisPersistence) Watchdog(context.Context, string) {
+	r.WatchdogCounter++
+}
+
 func (r *redisPersistence) IsLocked(key string) bool {
 	_, isLocked := r.lock[key]
 	return isLocked
@@ -61,5 +99,10 @@ func (r *redisPersistence) Reset() {
 	r.LockError = nil
 	r.UnlockCounter = 0
 	r.UnlockError = nil
+	r.ExtendCounter = 0
+	r.ExtendError = nil
+	r.ExistsCounter = 0
+	r.ExistsError = nil
+	r.WatchdogCounter = 0
 	r.lock = make(map[string]string)
 }
@@ -10,6 +10,8 @@ import (
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
 	"github.com/go-redis/redis/v8"
 	"strings"
+	"sync"
+	"time"
 )
 
 type redisServer struct {
@@ -20,8 +22,8 @@ type redisServer struct {
 	CloseCounter int
 	CloseError   error
 	SetError     error
-	GetError     error
-	DelError     error
+	EvalError    error
+	mutex        sync.Mutex
 }
 
 type redisTestHook struct {
@@ -67,13 +69,16 @@ func (r *redisServer) createClient() adapters.RedisAdapter {
 	return r
 }
 
-func (r *redisServer) Open() (*redis.Client, error) {
+func (r *redisServer) Open(ctx context.Context) (*redis.Client, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	r.OpenCounter++
 	if r.OpenError != nil {
 		return nil, r.OpenError
 	}
 
-	client, err := r.client.Open()
+	client, err := r.client.Open(ctx)
 	if r.SetError != nil {
 		client.AddHook(&redisTestHook{
 			target: "before set",
@@ -84,23 +89,13 @@ func (r *redisServer) Open() (*redis.Client, error) {
 			},
 		})
 	}
-	if r.GetError != nil {
-		client.AddHook(&redisTestHook{
-			target: "before get",
-			client: client,
-			match:  "error",
-			action: func(_ *redis.Client) error {
-				return fmt.Errorf("get error")
-			},
-		})
-	}
-	if r.DelError != nil {
+	if r.EvalError != nil {
 		client.AddHook(&redisTestHook{
-			target: "before del",
+			target: "before eval",
 			client: client,
 			match:  "error",
 			action: func(_ *redis.Client) error {
-				return fmt.Errorf("del error")
+				return r.EvalError
 			},
 		})
 	}
@@ -108,22 +103,35 @@ func (r *redisServer) Open() (*redis.Client, error) {
 }
 
 func (r *redisServer) Get(key string) (string, error) {
-	redisClient, _ := r.client.Open()
+	redisClient, _ := r.client.Open(context.Background())
 	value, _ := redisClient.Get(redisClient.Context(), key).Result()
-	err := redisClient.Close()
+	err := r.client.Close()
 
 	return value, err
 }
 
 func (r *redisServer) Set(key string, value string) error {
-	redisClient, _ := r.client.Open()
+	redisClient, _ := r.client.Open(context.Background())
 	_, _ = redisClient.Set(redisClient.Context(), key, value, 0).Result()
-	err := redisClient.Close()
+	err := r.client.Close()
 
 	return err
 }
 
+// TTL returns the key TTL on miniredis, zero if key has no TTL.
+func (r *redisServer) TTL(key string) time.Duration {
+	return r.server.TTL(key)
+}
+
+// FastForward decreases all miniredis TTLs by the given duration, expiring keys with TTL <= 0.
+func (r *redisServer) FastForward(duration time.Duration) {
+	r.server.FastForward(duration)
+}
+
 func (r *redisServer) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	r.CloseCounter++
 	if r.CloseError != nil {
 		return r.CloseError
@@ -139,10 +147,7 @@ func (r *redisTestHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (con
 	if r.target == "before set" && strings.Contains(cmd.String(), r.match) && strings.Contains(cmd.String(), "set") {
 		return nil, r.action(r.client)
 	}
-	if r.target == "before get" && strings.Contains(cmd.String(), r.match) && strings.Contains(cmd.String(), "get") {
-		return nil, r.action(r.client)
-	}
-	if r.target == "before del" && strings.Contains(cmd.String(), r.match) && strings.Contains(cmd.String(), "del") {
+	if r.target == "before eval" && strings.Contains(cmd.String(), r.match) && strings.HasPrefix(cmd.Name(), "eval") {
 		return nil, r.action(r.client)
 	}
 	return ctx, nil
@@ -161,9 +166,8 @@ func (r *redisTestHook) AfterProcessPipeline(context.Context, []redis.Cmder) err
 }
 
 func (r *redisServer) Reset() {
-	r.DelError = nil
 	r.SetError = nil
-	r.GetError = nil
+	r.EvalError = nil
 	r.OpenCounter = 0
 	r.OpenError = nil
 	r.CloseCounter = 0
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+)
+
+type relayUseCaseMock struct {
+	RelayCallCounter int
+	RelayError       error
+	RelayReport      *model.RelayReport
+	Loggers          []adapters.LoggerAdapter
+}
+
+func RelayUseCase() *relayUseCaseMock {
+	return &relayUseCaseMock{}
+}
+
+// Factory returns an adapters.RelayUseCaseFactory that records the logger of each execution and returns this mock.
+func (r *relayUseCaseMock) Factory(logger adapters.LoggerAdapter) adapters.RelayUseCaseAdapter {
+	r.Loggers = append(r.Loggers, logger)
+	return r
+}
+
+func (r *relayUseCaseMock) Relay(_ context.Context) (*model.RelayReport, error) {
+	r.RelayCallCounter++
+
+	if r.RelayError != nil {
+		return nil, r.RelayError
+	}
+	return r.RelayReport, nil
+}
+
+func (r *relayUseCaseMock) Reset() {
+	r.RelayCallCounter = 0
+	r.RelayError = nil
+	r.RelayReport = &model.RelayReport{Sent: []string{}, Discarded: []string{}, Failed: []string{}}
+	r.Loggers = nil
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"sync"
 )
 
 type secretsManager struct {
@@ -14,6 +15,7 @@ type secretsManager struct {
 	ReturnEmptyBinary     bool
 	ReturnEmptyString     bool
 	secrets               map[string][]byte
+	mutex                 sync.Mutex
 }
 
 func SecretsManager() *secretsManager {
@@ -26,6 +28,9 @@ func (s *secretsManager) GetSecretValue(_ context.Context, params *secretsmanage
 	*secretsmanager.GetSecretValueOutput,
 	error,
 ) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.GetSecretValueCounter++
 
 	if s.GetSecretValueError != nil {
@@ -51,6 +56,9 @@ func (s *secretsManager) GetSecretValue(_ context.Context, params *secretsmanage
 }
 
 func (s *secretsManager) SetSecret(key string, value any) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	byteValue, err := json.Marshal(value)
 	if err != nil {
 		panic(err)
@@ -60,6 +68,9 @@ func (s *secretsManager) SetSecret(key string, value any) {
 }
 
 func (s *secretsManager) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.GetSecretValueCounter = 0
 	s.GetSecretValueError = nil
 	s.ReturnEmptyString = false
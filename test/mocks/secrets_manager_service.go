@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
@@ -18,7 +19,7 @@ func SecretsManagerService() *secretsManagerService {
 	}
 }
 
-func (s *secretsManagerService) GetSecret(secretName string, secretObject any) custom_error.BaseErrorAdapter {
+func (s *secretsManagerService) GetSecret(_ context.Context, secretName string, secretObject any) custom_error.BaseErrorAdapter {
 	s.GetSecretCounter++
 	if s.GetSecretError != nil {
 		return exceptions.SecretsManagerError(s.GetSecretError, "secrets manager error")
@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"sync"
+)
+
+type signatureVerifierMock struct {
+	VerifyCounter int
+	VerifyError   custom_error.BaseErrorAdapter
+	mutex         sync.Mutex
+}
+
+func SignatureVerifier() *signatureVerifierMock {
+	return &signatureVerifierMock{}
+}
+
+func (s *signatureVerifierMock) Verify(context.Context, *dto.SNSNotification) custom_error.BaseErrorAdapter {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.VerifyCounter++
+	return s.VerifyError
+}
+
+func (s *signatureVerifierMock) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.VerifyCounter = 0
+	s.VerifyError = nil
+}
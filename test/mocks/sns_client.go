@@ -3,10 +3,13 @@ package mocks
 import (
 	"context"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"sync"
 )
 
 type snsClient struct {
 	NumberOfMessagesSent int
+	PublishError         error
+	mutex                sync.Mutex
 }
 
 func SNSClient() *snsClient {
@@ -14,10 +17,21 @@ func SNSClient() *snsClient {
 }
 
 func (s *snsClient) Publish(_ context.Context, _ *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.PublishError != nil {
+		return nil, s.PublishError
+	}
+
 	s.NumberOfMessagesSent++
 	return nil, nil
 }
 
 func (s *snsClient) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.NumberOfMessagesSent = 0
+	s.PublishError = nil
 }
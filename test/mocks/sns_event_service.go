@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 )
@@ -14,7 +15,7 @@ func SnsEventService() *snsEventService {
 	return &snsEventService{}
 }
 
-func (s *snsEventService) Send(object interface{}) custom_error.BaseErrorAdapter {
+func (s *snsEventService) Send(_ context.Context, object interface{}) custom_error.BaseErrorAdapter {
 	s.SendCounter++
 	if s.SendError != nil {
 		return exceptions.SNSEventServiceError(s.SendError, "Send error")
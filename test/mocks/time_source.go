@@ -0,0 +1,20 @@
+package mocks
+
+import "time"
+
+type timeSource struct {
+	Offset time.Duration
+}
+
+// TimeSource returns the current time moved by Offset.
+func TimeSource() *timeSource {
+	return &timeSource{}
+}
+
+func (t *timeSource) Now() time.Time {
+	return time.Now().Add(t.Offset)
+}
+
+func (t *timeSource) Reset() {
+	t.Offset = 0
+}
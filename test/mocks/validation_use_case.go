@@ -1,22 +1,85 @@
 package mocks
 
-import "github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"sync"
+	"time"
+)
 
 type validationUseCaseMock struct {
-	ValidateCallCounter int
-	ValidateError       error
+	ValidateCallCounter     int
+	ValidateError           error
+	ValidateErrorByClientId map[string]error
+	ValidatePanic           interface{}
+	ValidateDelay           time.Duration
+	ValidatedRequests       []*model.OperationRequest
+	ValidatedContexts       []context.Context
+	MaxConcurrentCalls      int
+	InvocationIds           []string
+	Loggers                 []adapters.LoggerAdapter
+	concurrentCalls         int
+	mutex                   sync.Mutex
 }
 
 func ValidationUseCase() *validationUseCaseMock {
-	return &validationUseCaseMock{}
+	return &validationUseCaseMock{
+		ValidateErrorByClientId: map[string]error{},
+	}
 }
 
-func (v *validationUseCaseMock) Validate(*model.OperationRequest) error {
+// Factory returns an adapters.ValidationUseCaseFactory that records the invocation id and logger of each record and
+// returns this mock.
+func (v *validationUseCaseMock) Factory(invocationId string, logger adapters.LoggerAdapter) adapters.ValidationUseCaseAdapter {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.InvocationIds = append(v.InvocationIds, invocationId)
+	v.Loggers = append(v.Loggers, logger)
+	return v
+}
+
+func (v *validationUseCaseMock) Validate(ctx context.Context, operationRequest *model.OperationRequest) error {
+	v.mutex.Lock()
 	v.ValidateCallCounter++
+	v.ValidatedRequests = append(v.ValidatedRequests, operationRequest)
+	v.ValidatedContexts = append(v.ValidatedContexts, ctx)
+	v.concurrentCalls++
+	if v.concurrentCalls > v.MaxConcurrentCalls {
+		v.MaxConcurrentCalls = v.concurrentCalls
+	}
+	err, hasClientError := v.ValidateErrorByClientId[operationRequest.ClientId]
+	v.mutex.Unlock()
+
+	defer func() {
+		v.mutex.Lock()
+		v.concurrentCalls--
+		v.mutex.Unlock()
+	}()
+
+	time.Sleep(v.ValidateDelay)
+
+	if v.ValidatePanic != nil {
+		panic(v.ValidatePanic)
+	}
+	if hasClientError {
+		return err
+	}
 	return v.ValidateError
 }
 
 func (v *validationUseCaseMock) Reset() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
 	v.ValidateCallCounter = 0
 	v.ValidateError = nil
+	v.ValidateErrorByClientId = map[string]error{}
+	v.ValidatePanic = nil
+	v.ValidateDelay = 0
+	v.ValidatedRequests = nil
+	v.ValidatedContexts = nil
+	v.MaxConcurrentCalls = 0
+	v.InvocationIds = nil
+	v.Loggers = nil
+	v.concurrentCalls = 0
 }
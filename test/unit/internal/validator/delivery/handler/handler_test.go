@@ -2,16 +2,25 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/handler"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/lock_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/exceptions"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 	"github.com/brienze1/crypto-robot-validator/test/mocks"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 type (
@@ -26,6 +35,7 @@ var (
 
 var (
 	validationUseCase = mocks.ValidationUseCase()
+	signatureVerifier = mocks.SignatureVerifier()
 	logger            = mocks.Logger()
 	handlerImpl       adapters.HandlerAdapter
 )
@@ -37,80 +47,465 @@ func (c ctx) Value(any) any {
 }
 
 func setup() {
-	handlerImpl = handler.Handler(validationUseCase, logger)
+	config.LoadTestEnv()
+	handlerImpl = handler.Handler(validationUseCase.Factory, signatureVerifier, logger.Factory)
 
 	logger.Reset()
 	validationUseCase.Reset()
+	signatureVerifier.Reset()
 	awsRequestIdExpected = uuid.NewString()
 }
 
 func TestHandlerSuccess(t *testing.T) {
 	setup()
 
-	ctx := ctx{}
+	ctx := ctx{Context: context.Background()}
 	event := *createSQSEvent()
 
-	err := handlerImpl.Handle(ctx, event)
+	response, err := handlerImpl.Handle(ctx, event)
 
 	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "No record should fail")
 	assert.Equal(t, 1, validationUseCase.ValidateCallCounter, "validate should be called once")
-	assert.Equal(t, 2, logger.InfoCallCounter, "logger info should be called twice")
+	assert.Equal(t, 4, logger.InfoCallCounter, "logger info should be called four times")
 	assert.Equal(t, 0, logger.ErrorCallCounter, "logger exceptions should not be called")
-	assert.Equal(t, awsRequestIdExpected, logger.CorrelationId, "Logger correlationId is same as context awsRequestId")
+	assert.Equal(t, []string{awsRequestIdExpected, event.Records[0].MessageId}, logger.CorrelationIds)
+	assert.Equal(t, []adapters2.LoggerAdapter{logger}, validationUseCase.Loggers, "Use case should log with record logger")
+	assert.Equal(t, []string{awsRequestIdExpected}, validationUseCase.InvocationIds, "Use case should lock on behalf of the invocation")
 }
 
 func TestHandlerJsonSQSError(t *testing.T) {
 	setup()
 
-	ctx := ctx{}
+	ctx := ctx{Context: context.Background()}
 	event := *createSQSEvent()
 	event.Records[0].Body = ""
 
-	var err = handlerImpl.Handle(ctx, event)
+	response, err := handlerImpl.Handle(ctx, event)
 
-	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "Error while trying to parse the SNS message", err.(custom_error.BaseErrorAdapter).InternalError())
-	assert.Equal(t, "Error occurred while handling the event", err.(custom_error.BaseErrorAdapter).Description())
-	assert.Equal(t, "unexpected end of JSON input", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "Invalid record should not be redelivered")
+	assert.Equal(t, "Error while trying to parse the SNS message", logger.LastError.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error occurred while handling the event", logger.LastError.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "unexpected end of JSON input", logger.LastError.Error())
+	assert.Equal(t, error_code.InvalidMessage.Name(), logger.LastError.(custom_error.BaseErrorAdapter).Code())
 	assert.Equal(t, 0, validationUseCase.ValidateCallCounter, "validationUseCase should not be called")
-	assert.Equal(t, 1, logger.InfoCallCounter, "logger info should be called once")
+	assert.Equal(t, 3, logger.InfoCallCounter, "logger info should be called three times")
 	assert.Equal(t, 1, logger.ErrorCallCounter, "logger exceptions should be called once")
-	assert.Equal(t, awsRequestIdExpected, logger.CorrelationId, "Logger correlationId is same as context awsRequestId")
+	assert.Equal(t, []string{awsRequestIdExpected, event.Records[0].MessageId}, logger.CorrelationIds)
 }
 
 func TestHandlerOperationUseCaseError(t *testing.T) {
 	setup()
 
-	ctx := ctx{}
+	ctx := ctx{Context: context.Background()}
 	event := *createSQSEvent()
 	expectedErrorMsg := uuid.NewString()
 	validationUseCase.ValidateError = errors.New(expectedErrorMsg)
 
-	var err = handlerImpl.Handle(ctx, event)
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: event.Records[0].MessageId}}, response.BatchItemFailures)
+	assert.Equal(t, "Error while trying to run ValidationUseCase", logger.LastError.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error occurred while handling the event", logger.LastError.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, expectedErrorMsg, logger.LastError.Error())
+	assert.Equal(t, 1, validationUseCase.ValidateCallCounter, "validationUseCase should be called once")
+	assert.Equal(t, 3, logger.InfoCallCounter, "logger info should be called three times")
+	assert.Equal(t, 1, logger.ErrorCallCounter, "logger exceptions should be called once")
+}
+
+func TestHandlerOperationUseCasePanicFailure(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	validationUseCase.ValidatePanic = "unexpected failure"
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: event.Records[0].MessageId}}, response.BatchItemFailures)
+	assert.Equal(t, "Panic while trying to run ValidationUseCase", logger.LastError.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "unexpected failure", logger.LastError.Error())
+	assert.Equal(t, 1, logger.ErrorCallCounter, "logger exceptions should be called once")
+}
+
+func TestHandlerOperationUseCaseUnlockFailure(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	unlockError := exceptions.NewUnlockError("client-1", []lock_type.LockType{lock_type.Client}, errors.New("unlock error"))
+	validationUseCase.ValidateError = errors.Join(errors.New("validation error"), unlockError)
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: event.Records[0].MessageId}}, response.BatchItemFailures)
+	assert.Equal(t, "Error while trying to run ValidationUseCase", logger.LastError.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Contains(t, logger.LastError.Error(), "validation error")
+	assert.Contains(t, logger.LastError.Error(), "locks [CLIENT] still held for client client-1: unlock error")
+	assert.Equal(t, 2, logger.ErrorCallCounter, "logger exceptions should be called twice")
+}
+
+func TestHandlerOperationUseCaseRejectionSuccess(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	validationUseCase.ValidateError = exceptions.NewValidationError(error_code.ClientInactive, "Client is not active")
 
-	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "Error while trying to run ValidationUseCase", err.(custom_error.BaseErrorAdapter).InternalError())
-	assert.Equal(t, "Error occurred while handling the event", err.(custom_error.BaseErrorAdapter).Description())
-	assert.Equal(t, expectedErrorMsg, err.Error())
-	assert.Equal(t, 1, validationUseCase.ValidateCallCounter, "validationUseCase should not be called")
-	assert.Equal(t, 1, logger.InfoCallCounter, "logger info should be called once")
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "Rejected record should not be redelivered")
+	assert.Equal(t, error_code.ClientInactive.Name(), logger.LastError.(custom_error.BaseErrorAdapter).Code())
 	assert.Equal(t, 1, logger.ErrorCallCounter, "logger exceptions should be called once")
-	assert.Equal(t, awsRequestIdExpected, logger.CorrelationId, "Logger correlationId is same as context awsRequestId")
-}
-
-func createSQSEvent() *events.SQSEvent {
-	operationRequest := `{
-	  "client_id": "aa324edf-99fa-4a95-b9c4-a588d1ccb441e",
-	  "operation": "BUY",
-	  "symbol": "BTC",
-	  "start_time": "2022-09-17T12:05:07.45066-03:00"
-	}`
-
-	return &events.SQSEvent{
-		Records: []events.SQSMessage{
-			{
-				Body: operationRequest,
-			},
+	assert.Equal(t, 4, logger.InfoCallCounter, "logger info should be called four times")
+}
+
+func TestHandlerOperationUseCaseRetryableCodeFailure(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	validationUseCase.ValidateError = exceptions.NewValidationError(error_code.ServiceUnavailable, "Circuit breaker is open")
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: event.Records[0].MessageId}}, response.BatchItemFailures)
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), logger.LastError.(custom_error.BaseErrorAdapter).Code())
+}
+
+func TestHandlerOperationUseCaseRejectionUnlockFailure(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	unlockError := exceptions.NewUnlockError("client-1", []lock_type.LockType{lock_type.Client}, errors.New("unlock error"))
+	validationUseCase.ValidateError = errors.Join(exceptions.NewValidationError(error_code.StaleRequest, "Request is stale"), unlockError)
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: event.Records[0].MessageId}}, response.BatchItemFailures)
+	assert.Equal(t, 2, logger.ErrorCallCounter, "logger exceptions should be called twice")
+}
+
+func TestHandlerMultipleRecordsSuccess(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent("client-1", "client-2", "client-3")
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "No record should fail")
+	assert.Equal(t, 3, validationUseCase.ValidateCallCounter, "validate should be called for every record")
+	assert.Equal(t, 0, logger.ErrorCallCounter, "logger exceptions should not be called")
+	assert.ElementsMatch(t, []string{
+		awsRequestIdExpected,
+		event.Records[0].MessageId,
+		event.Records[1].MessageId,
+		event.Records[2].MessageId,
+	}, logger.CorrelationIds)
+}
+
+func TestHandlerValidationDeadlineSuccess(t *testing.T) {
+	setup()
+
+	deadline := time.Now().Add(time.Minute)
+	parent, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	ctx := ctx{Context: parent}
+	event := *createSQSEvent()
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "No record should fail")
+	validationDeadline, hasDeadline := validationUseCase.ValidatedContexts[0].Deadline()
+	assert.Equal(t, true, hasDeadline, "Validation context should have a deadline")
+	assert.Equal(t, deadline.Add(-properties.Properties().LockReleaseTimeout), validationDeadline,
+		"Validation deadline should leave time to release locks")
+	assert.NotNil(t, validationUseCase.ValidatedContexts[0].Err(), "Validation context should be cancelled after Handle")
+}
+
+func TestHandlerValidationNoDeadlineSuccess(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "No record should fail")
+	_, hasDeadline := validationUseCase.ValidatedContexts[0].Deadline()
+	assert.Equal(t, false, hasDeadline, "Validation context should not have a deadline")
+}
+
+func TestHandlerPartialBatchFailure(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent("client-1", "client-2", "client-3", "client-4")
+	event.Records[1].Body = "{"
+	validationUseCase.ValidateErrorByClientId["client-3"] = errors.New(uuid.NewString())
+	validationUseCase.ValidateErrorByClientId["client-4"] = errors.New(uuid.NewString())
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []events.SQSBatchItemFailure{
+		{ItemIdentifier: event.Records[2].MessageId},
+		{ItemIdentifier: event.Records[3].MessageId},
+	}, response.BatchItemFailures, "Only failed records should be reported, in batch order")
+	assert.Equal(t, 3, validationUseCase.ValidateCallCounter, "validate should be called for every parsed record")
+	assert.Equal(t, 3, logger.ErrorCallCounter, "logger exceptions should be called for every failed or invalid record")
+}
+
+func TestHandlerSameClientRecordsSequential(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent("client-1", "client-1", "client-1")
+	validationUseCase.ValidateDelay = 10 * time.Millisecond
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "No record should fail")
+	assert.Equal(t, 3, validationUseCase.ValidateCallCounter, "validate should be called for every record")
+	assert.Equal(t, 1, validationUseCase.MaxConcurrentCalls, "records of the same client should not run concurrently")
+}
+
+func TestHandlerMaxConcurrency(t *testing.T) {
+	setup()
+
+	maxConcurrency := properties.Properties().HandlerMaxConcurrency
+	properties.Properties().HandlerMaxConcurrency = 2
+	defer func() {
+		properties.Properties().HandlerMaxConcurrency = maxConcurrency
+	}()
+	handlerImpl = handler.Handler(validationUseCase.Factory, signatureVerifier, logger.Factory)
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent("client-1", "client-2", "client-3", "client-4", "client-5")
+	validationUseCase.ValidateDelay = 20 * time.Millisecond
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "No record should fail")
+	assert.Equal(t, 5, validationUseCase.ValidateCallCounter, "validate should be called for every record")
+	assert.Equal(t, 2, validationUseCase.MaxConcurrentCalls, "different clients should run concurrently up to the limit")
+}
+
+func TestHandlerSNSEnvelopeSuccess(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	event.Records[0].Body = createSNSEnvelope(event.Records[0].Body)
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "No record should fail")
+	assert.Equal(t, 1, validationUseCase.ValidateCallCounter, "validate should be called once")
+	assert.Equal(t, "aa324edf-99fa-4a95-b9c4-a588d1ccb441e", validationUseCase.ValidatedRequests[0].ClientId)
+	assert.Equal(t, 0, signatureVerifier.VerifyCounter, "signature should not be verified when disabled")
+}
+
+func TestHandlerSNSEnvelopeMessageIdSuccess(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	event.Records[0].Body = createSNSEnvelope(event.Records[0].Body)
+
+	notification := &dto.SNSNotification{}
+	_ = json.Unmarshal([]byte(event.Records[0].Body), notification)
+
+	_, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, notification.MessageId, validationUseCase.ValidatedRequests[0].MessageId)
+}
+
+func TestHandlerRawMessageIdSuccess(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+
+	_, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, event.Records[0].MessageId, validationUseCase.ValidatedRequests[0].MessageId)
+}
+
+func TestHandlerSNSEnvelopeSignatureVerifiedSuccess(t *testing.T) {
+	setup()
+
+	properties.Properties().SnsVerifySignature = true
+	defer func() {
+		properties.Properties().SnsVerifySignature = false
+	}()
+	handlerImpl = handler.Handler(validationUseCase.Factory, signatureVerifier, logger.Factory)
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	event.Records[0].Body = createSNSEnvelope(event.Records[0].Body)
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "No record should fail")
+	assert.Equal(t, 1, signatureVerifier.VerifyCounter, "signature should be verified")
+	assert.Equal(t, 1, validationUseCase.ValidateCallCounter, "validate should be called once")
+}
+
+func TestHandlerSNSEnvelopeSignatureFailure(t *testing.T) {
+	setup()
+
+	properties.Properties().SnsVerifySignature = true
+	defer func() {
+		properties.Properties().SnsVerifySignature = false
+	}()
+	handlerImpl = handler.Handler(validationUseCase.Factory, signatureVerifier, logger.Factory)
+	signatureVerifier.VerifyError = custom_error.NewBaseError(errors.New("crypto/rsa: verification error"), "Signature is not valid")
+	signatureVerifier.VerifyError.SetCode(error_code.InvalidSignature.Name())
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	event.Records[0].Body = createSNSEnvelope(event.Records[0].Body)
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "Record with invalid signature should not be redelivered")
+	assert.Equal(t, "Signature is not valid", logger.LastError.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.InvalidSignature.Name(), logger.LastError.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, 1, logger.ErrorCallCounter, "logger exceptions should be called once")
+	assert.Equal(t, 0, validationUseCase.ValidateCallCounter, "validationUseCase should not be called")
+}
+
+func TestHandlerSNSEnvelopeSignatureCertificateFailure(t *testing.T) {
+	setup()
+
+	properties.Properties().SnsVerifySignature = true
+	defer func() {
+		properties.Properties().SnsVerifySignature = false
+	}()
+	handlerImpl = handler.Handler(validationUseCase.Factory, signatureVerifier, logger.Factory)
+	signatureVerifier.VerifyError = custom_error.NewBaseError(errors.New("connection refused"), "Error while trying to get signing certificate")
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	event.Records[0].Body = createSNSEnvelope(event.Records[0].Body)
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: event.Records[0].MessageId}}, response.BatchItemFailures)
+	assert.Equal(t, 0, validationUseCase.ValidateCallCounter, "validationUseCase should not be called")
+}
+
+func TestHandlerRawMessageSignatureRequiredFailure(t *testing.T) {
+	setup()
+
+	properties.Properties().SnsVerifySignature = true
+	defer func() {
+		properties.Properties().SnsVerifySignature = false
+	}()
+	handlerImpl = handler.Handler(validationUseCase.Factory, signatureVerifier, logger.Factory)
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "Unsigned record should not be redelivered")
+	assert.Equal(t, error_code.InvalidSignature.Name(), logger.LastError.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, "message is not an SNS notification", logger.LastError.Error())
+	assert.Equal(t, 1, logger.ErrorCallCounter, "logger exceptions should be called once")
+	assert.Equal(t, 0, signatureVerifier.VerifyCounter, "raw messages cannot be verified")
+	assert.Equal(t, 0, validationUseCase.ValidateCallCounter, "validationUseCase should not be called")
+}
+
+func TestHandlerRawAndSNSEnvelopeRecordsSuccess(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent("client-1", "client-2")
+	event.Records[1].Body = createSNSEnvelope(event.Records[1].Body)
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "No record should fail")
+	assert.Equal(t, 2, validationUseCase.ValidateCallCounter, "validate should be called for both formats")
+}
+
+func TestHandlerSNSEnvelopeInvalidMessageFailure(t *testing.T) {
+	setup()
+
+	ctx := ctx{Context: context.Background()}
+	event := *createSQSEvent()
+	event.Records[0].Body = createSNSEnvelope("not an operation request")
+
+	response, err := handlerImpl.Handle(ctx, event)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Empty(t, response.BatchItemFailures, "Invalid record should not be redelivered")
+	assert.Equal(t, "Error while trying to parse the SNS message", logger.LastError.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, 0, validationUseCase.ValidateCallCounter, "validationUseCase should not be called")
+}
+
+func createSNSEnvelope(message string) string {
+	envelope, _ := json.Marshal(dto.SNSNotification{
+		Type:             "Notification",
+		MessageId:        uuid.NewString(),
+		TopicArn:         "arn:aws:sns:sa-east-1:000000000000:cryptoValidatorTopic",
+		Message:          message,
+		Timestamp:        "2022-09-17T15:05:07.450Z",
+		SignatureVersion: "1",
+		Signature:        "c2lnbmF0dXJl",
+		SigningCertURL:   "https://sns.sa-east-1.amazonaws.com/SimpleNotificationService-test.pem",
+		MessageAttributes: map[string]dto.SNSMessageAttribute{
+			"origin": {Type: "String", Value: "crypto-robot-analyzer"},
 		},
+	})
+	return string(envelope)
+}
+
+func createSQSEvent(clientIds ...string) *events.SQSEvent {
+	if len(clientIds) == 0 {
+		clientIds = []string{"aa324edf-99fa-4a95-b9c4-a588d1ccb441e"}
 	}
+
+	event := &events.SQSEvent{}
+	for _, clientId := range clientIds {
+		operationRequest := `{
+		  "client_id": "` + clientId + `",
+		  "operation": "BUY",
+		  "symbol": "BTC",
+		  "analysis": "STRONG_BUY",
+		  "start_time": "2022-09-17T12:05:07.45066-03:00"
+		}`
+
+		event.Records = append(event.Records, events.SQSMessage{
+			MessageId: uuid.NewString(),
+			Body:      operationRequest,
+		})
+	}
+
+	return event
 }
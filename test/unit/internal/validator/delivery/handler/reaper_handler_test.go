@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/handler"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	reaperUseCase     = mocks.ReaperUseCase()
+	reaperHandlerImpl adapters.ReaperHandlerAdapter
+)
+
+func reaperSetup() {
+	config.LoadTestEnv()
+	reaperHandlerImpl = handler.ReaperHandler(reaperUseCase.Factory, logger.Factory)
+
+	logger.Reset()
+	reaperUseCase.Reset()
+	awsRequestIdExpected = uuid.NewString()
+}
+
+func TestReaperHandlerSuccess(t *testing.T) {
+	reaperSetup()
+
+	reaperUseCase.ReaperReport = &model.ReaperReport{
+		Released:    []string{"client-1"},
+		Held:        []string{"client-2"},
+		Failed:      []string{"client-3"},
+		Compensated: []string{"operation-1"},
+	}
+
+	report, err := reaperHandlerImpl.Handle(ctx{Context: context.Background()})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []string{"client-1"}, report.Released)
+	assert.Equal(t, []string{"client-2"}, report.Held)
+	assert.Equal(t, []string{"client-3"}, report.Failed)
+	assert.Equal(t, []string{"operation-1"}, report.Compensated)
+	assert.Equal(t, 1, reaperUseCase.ReapCallCounter)
+	assert.Equal(t, 2, logger.InfoCallCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+	assert.Equal(t, []string{awsRequestIdExpected}, logger.CorrelationIds)
+	assert.Equal(t, []adapters2.LoggerAdapter{logger}, reaperUseCase.Loggers, "Use case should log with execution logger")
+}
+
+func TestReaperHandlerUseCaseFailure(t *testing.T) {
+	reaperSetup()
+
+	reaperUseCase.ReapError = errors.New("reap error")
+
+	report, err := reaperHandlerImpl.Handle(ctx{Context: context.Background()})
+
+	assert.Nil(t, report)
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Error while trying to run ReaperUseCase", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error occurred while handling the event", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "reap error", err.Error())
+	assert.Equal(t, 1, reaperUseCase.ReapCallCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/handler"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	relayUseCase     = mocks.RelayUseCase()
+	relayHandlerImpl adapters.RelayHandlerAdapter
+)
+
+func relaySetup() {
+	config.LoadTestEnv()
+	relayHandlerImpl = handler.RelayHandler(relayUseCase.Factory, logger.Factory)
+
+	logger.Reset()
+	relayUseCase.Reset()
+	awsRequestIdExpected = uuid.NewString()
+}
+
+func TestRelayHandlerSuccess(t *testing.T) {
+	relaySetup()
+
+	relayUseCase.RelayReport = &model.RelayReport{
+		Sent:      []string{"EVENT#1"},
+		Discarded: []string{"EVENT#2"},
+		Failed:    []string{"EVENT#3"},
+	}
+
+	report, err := relayHandlerImpl.Handle(ctx{Context: context.Background()})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []string{"EVENT#1"}, report.Sent)
+	assert.Equal(t, []string{"EVENT#2"}, report.Discarded)
+	assert.Equal(t, []string{"EVENT#3"}, report.Failed)
+	assert.Equal(t, 1, relayUseCase.RelayCallCounter)
+	assert.Equal(t, 2, logger.InfoCallCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+	assert.Equal(t, []string{awsRequestIdExpected}, logger.CorrelationIds)
+	assert.Equal(t, []adapters2.LoggerAdapter{logger}, relayUseCase.Loggers, "Use case should log with execution logger")
+}
+
+func TestRelayHandlerUseCaseFailure(t *testing.T) {
+	relaySetup()
+
+	relayUseCase.RelayError = errors.New("relay error")
+
+	report, err := relayHandlerImpl.Handle(ctx{Context: context.Background()})
+
+	assert.Nil(t, report)
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Error while trying to run RelayUseCase", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error occurred while handling the event", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "relay error", err.Error())
+	assert.Equal(t, 1, relayUseCase.RelayCallCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
@@ -0,0 +1,259 @@
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/delivery/verifier"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const signingCertURL = "https://sns.sa-east-1.amazonaws.com/SimpleNotificationService-test.pem"
+
+var (
+	signatureVerifier adapters.SignatureVerifierAdapter
+	logger            = mocks.Logger()
+	client            = mocks.HttpClient()
+	privateKey        *rsa.PrivateKey
+	certificatePEM    string
+)
+
+func init() {
+	privateKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, _ := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	certificatePEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}))
+}
+
+func setup() {
+	config.LoadTestEnv()
+	properties.Properties().Reload()
+
+	logger.Reset()
+	client.Reset()
+	client.SigningCertPEM = certificatePEM
+
+	signatureVerifier = verifier.SNSSignatureVerifier(logger, client)
+}
+
+func TestVerifySignatureVersion1Success(t *testing.T) {
+	setup()
+
+	notification := signedNotification("1")
+
+	err := signatureVerifier.Verify(context.Background(), notification)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, client.DoCounter)
+	assert.Equal(t, 2, logger.InfoCallCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestVerifySignatureVersion2Success(t *testing.T) {
+	setup()
+
+	notification := signedNotification("2")
+
+	err := signatureVerifier.Verify(context.Background(), notification)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, client.DoCounter)
+}
+
+func TestVerifySignatureWithSubjectSuccess(t *testing.T) {
+	setup()
+
+	notification := &dto.SNSNotification{
+		Type:             "Notification",
+		MessageId:        uuid.NewString(),
+		TopicArn:         "arn:aws:sns:sa-east-1:000000000000:cryptoValidatorTopic",
+		Subject:          "operation",
+		Message:          `{"client_id":"` + uuid.NewString() + `"}`,
+		Timestamp:        time.Now().UTC().Format(time.RFC3339Nano),
+		SignatureVersion: "2",
+		SigningCertURL:   signingCertURL,
+	}
+	notification.Signature = sign(notification, "2")
+
+	err := signatureVerifier.Verify(context.Background(), notification)
+
+	assert.Nil(t, err)
+}
+
+func TestVerifySignatureCertificateCachedSuccess(t *testing.T) {
+	setup()
+
+	err1 := signatureVerifier.Verify(context.Background(), signedNotification("1"))
+	err2 := signatureVerifier.Verify(context.Background(), signedNotification("2"))
+
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Equal(t, 1, client.DoCounter, "certificate should only be downloaded once")
+}
+
+func TestVerifySignatureTamperedMessageFailure(t *testing.T) {
+	setup()
+
+	notification := signedNotification("1")
+	notification.Message = `{"client_id":"` + uuid.NewString() + `"}`
+
+	err := signatureVerifier.Verify(context.Background(), notification)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "Signature is not valid", err.InternalError())
+	assert.Equal(t, error_code.InvalidSignature.Name(), err.Code())
+	assert.Equal(t, "Error while verifying the SNS message signature", err.Description())
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestVerifySignatureWrongTopicFailure(t *testing.T) {
+	setup()
+
+	notification := signedNotification("2")
+	notification.TopicArn = "arn:aws:sns:sa-east-1:000000000000:otherTopic"
+	notification.Signature = sign(notification, "2")
+
+	err := signatureVerifier.Verify(context.Background(), notification)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "Notification topic is not the validator topic: arn:aws:sns:sa-east-1:000000000000:otherTopic", err.InternalError())
+	assert.Equal(t, error_code.InvalidSignature.Name(), err.Code())
+	assert.Equal(t, 0, client.DoCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestVerifySignatureVersionNotSupportedFailure(t *testing.T) {
+	setup()
+
+	notification := signedNotification("1")
+	notification.SignatureVersion = "3"
+
+	err := signatureVerifier.Verify(context.Background(), notification)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "Signature version not supported: 3", err.InternalError())
+	assert.Equal(t, error_code.InvalidSignature.Name(), err.Code())
+	assert.Equal(t, 0, client.DoCounter)
+}
+
+func TestVerifySignatureDecodeFailure(t *testing.T) {
+	setup()
+
+	notification := signedNotification("1")
+	notification.Signature = "not base64!"
+
+	err := signatureVerifier.Verify(context.Background(), notification)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "Error while trying to decode signature", err.InternalError())
+	assert.Equal(t, error_code.InvalidSignature.Name(), err.Code())
+	assert.Equal(t, 0, client.DoCounter)
+}
+
+func TestVerifySignatureUntrustedCertificateURLFailure(t *testing.T) {
+	setup()
+
+	for _, certURL := range []string{
+		"http://sns.sa-east-1.amazonaws.com/SimpleNotificationService-test.pem",
+		"https://sns.sa-east-1.amazonaws.com.attacker.com/SimpleNotificationService-test.pem",
+		"https://attacker.com/SimpleNotificationService-test.pem",
+	} {
+		notification := signedNotification("1")
+		notification.SigningCertURL = certURL
+
+		err := signatureVerifier.Verify(context.Background(), notification)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error while trying to get signing certificate", err.InternalError())
+		assert.Equal(t, "signing certificate url not trusted: "+certURL, err.Error())
+		assert.Equal(t, error_code.InvalidSignature.Name(), err.Code())
+	}
+	assert.Equal(t, 0, client.DoCounter)
+}
+
+func TestVerifySignatureCertificateRequestFailure(t *testing.T) {
+	setup()
+
+	client.DoError = errors.New(uuid.NewString())
+
+	err := signatureVerifier.Verify(context.Background(), signedNotification("1"))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "Error while trying to get signing certificate", err.InternalError())
+	assert.Equal(t, client.DoError.Error(), err.Error())
+	assert.Empty(t, err.Code(), "Certificate request failures should be retried")
+}
+
+func TestVerifySignatureCertificateStatusFailure(t *testing.T) {
+	setup()
+
+	client.SigningCertStatus = http.StatusNotFound
+
+	err := signatureVerifier.Verify(context.Background(), signedNotification("1"))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "Error while trying to get signing certificate", err.InternalError())
+	assert.Equal(t, "signing certificate status code not Ok: Not Found", err.Error())
+}
+
+func TestVerifySignatureCertificateNotPEMFailure(t *testing.T) {
+	setup()
+
+	client.SigningCertPEM = uuid.NewString()
+
+	err := signatureVerifier.Verify(context.Background(), signedNotification("1"))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "signing certificate is not PEM encoded", err.Error())
+}
+
+func signedNotification(signatureVersion string) *dto.SNSNotification {
+	notification := &dto.SNSNotification{
+		Type:             "Notification",
+		MessageId:        uuid.NewString(),
+		TopicArn:         "arn:aws:sns:sa-east-1:000000000000:cryptoValidatorTopic",
+		Message:          `{"client_id":"` + uuid.NewString() + `"}`,
+		Timestamp:        time.Now().UTC().Format(time.RFC3339Nano),
+		SignatureVersion: signatureVersion,
+		SigningCertURL:   signingCertURL,
+	}
+	notification.Signature = sign(notification, signatureVersion)
+	return notification
+}
+
+func sign(notification *dto.SNSNotification, signatureVersion string) string {
+	var signature []byte
+	if signatureVersion == "1" {
+		digest := sha1.Sum([]byte(notification.StringToSign()))
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA1, digest[:])
+	} else {
+		digest := sha256.Sum256([]byte(notification.StringToSign()))
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	}
+	return base64.StdEncoding.EncodeToString(signature)
+}
@@ -0,0 +1,365 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/lock_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/usecase"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var (
+	reaperUseCase adapters.ReaperUseCaseAdapter
+)
+
+func reaperSetup() {
+	setup()
+
+	reaperUseCase = usecase.ReaperUseCase(
+		clientPersistence,
+		lockPersistence,
+		outboxPersistence,
+		operationPersistence,
+		paperExchange,
+		time_utils.Time(),
+		logger,
+	)
+}
+
+// lockedClient locks the client as if a validation locked it at lockedAt and never unlocked it.
+func lockedClient(lockedAt time.Time) *model.Client {
+	client.Locked = true
+	client.LockedAt = lockedAt
+
+	return client
+}
+
+func staleLockedAt() time.Time {
+	return time.Now().Add(-properties.Properties().ReaperLockLease - time.Minute)
+}
+
+func TestReapStaleClientReleasedSuccess(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(staleLockedAt())
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{client.Id}, report.Released)
+	assert.Empty(t, report.Held)
+	assert.Empty(t, report.Failed)
+	assert.False(t, client.Locked, "Client should be unlocked")
+	assert.Equal(t, 1, clientPersistence.GetLockedClientsCounter)
+	assert.Equal(t, 1, lockPersistence.ExistsCounter)
+	assert.Equal(t, 1, clientPersistence.ReleaseLockCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestReapClientWithoutLockedAtReleasedSuccess(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(time.Time{})
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{client.Id}, report.Released)
+	assert.False(t, client.Locked, "Client should be unlocked")
+}
+
+func TestReapExpiredLeaseReleasedSuccess(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(time.Now())
+	client.LockExpiresAt = time.Now().Add(-time.Second)
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{client.Id}, report.Released)
+	assert.False(t, client.Locked, "Client should be unlocked")
+}
+
+func TestReapLeaseNotExpiredSkippedSuccess(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(staleLockedAt())
+	client.LockExpiresAt = time.Now().Add(time.Minute)
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Released)
+	assert.True(t, client.Locked, "Client should be kept locked")
+	assert.Equal(t, 0, clientPersistence.ReleaseLockCounter)
+}
+
+func TestReapClientWithinLeaseSkippedSuccess(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(time.Now())
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Released)
+	assert.Empty(t, report.Held)
+	assert.Empty(t, report.Failed)
+	assert.True(t, client.Locked, "Client should be kept locked")
+	assert.Equal(t, 0, lockPersistence.ExistsCounter)
+	assert.Equal(t, 0, clientPersistence.ReleaseLockCounter)
+}
+
+func TestReapUnlockedClientSkippedSuccess(t *testing.T) {
+	reaperSetup()
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Released)
+	assert.Equal(t, 0, lockPersistence.ExistsCounter)
+	assert.Equal(t, 0, clientPersistence.ReleaseLockCounter)
+}
+
+func TestReapClientIdStillLockedHeldSuccess(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(staleLockedAt())
+	_ = lockPersistence.Lock(context.Background(), client.Id)
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Released)
+	assert.Equal(t, []string{client.Id}, report.Held)
+	assert.True(t, client.Locked, "Client should be kept locked")
+	assert.Equal(t, 0, clientPersistence.ReleaseLockCounter)
+}
+
+func TestReapRecordedUnlockReleasedWithinLeaseSuccess(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(time.Now())
+	entry := model.NewUnlockOutboxEntry(client.Id, []lock_type.LockType{lock_type.Client}, "unlock error")
+	outboxPersistence.AddEntry(entry)
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{client.Id}, report.Released)
+	assert.False(t, client.Locked, "Client should be unlocked")
+	assert.Equal(t, outbox_status.Resolved, entry.Status)
+}
+
+func TestReapRecordedUnlockOfUnlockedClientResolvedSuccess(t *testing.T) {
+	reaperSetup()
+
+	entry := model.NewUnlockOutboxEntry(client.Id, []lock_type.LockType{lock_type.ClientId}, "unlock error")
+	outboxPersistence.AddEntry(entry)
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Released)
+	assert.Equal(t, outbox_status.Resolved, entry.Status)
+	assert.Equal(t, 0, clientPersistence.ReleaseLockCounter)
+}
+
+func TestReapRecordedUnlockHeldKeptPendingSuccess(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(time.Now())
+	_ = lockPersistence.Lock(context.Background(), client.Id)
+	entry := model.NewUnlockOutboxEntry(client.Id, []lock_type.LockType{lock_type.Client, lock_type.ClientId}, "unlock error")
+	outboxPersistence.AddEntry(entry)
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{client.Id}, report.Held)
+	assert.Equal(t, outbox_status.Pending, entry.Status)
+}
+
+func TestReapExistsFailure(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(staleLockedAt())
+	lockPersistence.ExistsError = errors.New("exists error")
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Released)
+	assert.Equal(t, []string{client.Id}, report.Failed)
+	assert.True(t, client.Locked, "Client should be kept locked")
+	assert.Equal(t, 0, clientPersistence.ReleaseLockCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestReapReleaseLockFailure(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(staleLockedAt())
+	entry := model.NewUnlockOutboxEntry(client.Id, []lock_type.LockType{lock_type.Client}, "unlock error")
+	outboxPersistence.AddEntry(entry)
+	clientPersistence.ReleaseLockError = errors.New("release lock error")
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Released)
+	assert.Equal(t, []string{client.Id}, report.Failed)
+	assert.Equal(t, outbox_status.Pending, entry.Status)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestReapResolveFailure(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(time.Now())
+	entry := model.NewUnlockOutboxEntry(client.Id, []lock_type.LockType{lock_type.Client}, "unlock error")
+	outboxPersistence.AddEntry(entry)
+	outboxPersistence.UpdateStatusError = errors.New("update status error")
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{client.Id}, report.Released)
+	assert.Equal(t, outbox_status.Pending, entry.Status)
+	assert.Equal(t, 1, logger.WarningCallCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestReapGetLockedClientsFailure(t *testing.T) {
+	reaperSetup()
+
+	clientPersistence.GetLockedClientsError = errors.New("get locked clients error")
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, report)
+	assert.NotNil(t, err)
+	assert.Equal(t, "GetLockedClients error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error while using DynamoDB Client table", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, 0, outboxPersistence.GetPendingCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestReapGetPendingFailure(t *testing.T) {
+	reaperSetup()
+
+	lockedClient(staleLockedAt())
+	outboxPersistence.GetPendingError = errors.New("get pending error")
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, report)
+	assert.NotNil(t, err)
+	assert.Equal(t, "get pending error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.True(t, client.Locked, "Client should be kept locked")
+	assert.Equal(t, 0, clientPersistence.ReleaseLockCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestReapAfterUnlockFailureSuccess(t *testing.T) {
+	reaperSetup()
+
+	clientPersistence.UnlockError = errors.New("unlock error")
+	_ = validationUseCase.Validate(context.Background(), operationRequest)
+	clientPersistence.UnlockError = nil
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{client.Id}, report.Released)
+	assert.False(t, client.Locked, "Client should be unlocked")
+	assert.Equal(t, outbox_status.Resolved, outboxEntries(outbox_type.Unlock)[0].Status)
+}
+
+func TestReapRecordedCompensationRepairedSuccess(t *testing.T) {
+	reaperSetup()
+
+	compensationNotPersisted()
+	operation := operationPersistence.GetAllOperations()[0]
+	logger.Reset()
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{operation.Id}, report.Compensated)
+	assert.Empty(t, report.Held)
+	assert.Empty(t, report.Failed)
+	assert.Equal(t, status.Cancelled, operation.Status)
+	assert.Equal(t, outbox_status.Resolved, outboxEntries(outbox_type.Compensation)[0].Status)
+	assert.Equal(t, 2, clientPersistence.ReleaseCounter)
+	assert.Equal(t, 1, clientPersistence.ReleaseLockCounter)
+	assert.False(t, client.Locked, "Client should be unlocked")
+	assert.False(t, lockPersistence.IsLocked(client.Id), "Client_id should be unlocked")
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestReapRecordedCompensationOfOperationNotCreatedResolvedSuccess(t *testing.T) {
+	reaperSetup()
+
+	operation := model.NewOperation(uuid.NewString(), client.OperationStopLoss)
+	operation.Cancel()
+	operationPersistence.AddOperation(operation)
+	entry := model.NewOutboxEntry(outbox_type.Compensation, client.Id, operation, "send error")
+	outboxPersistence.AddEntry(entry)
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{operation.Id}, report.Compensated)
+	assert.Equal(t, outbox_status.Resolved, entry.Status)
+	assert.Equal(t, 0, clientPersistence.ReleaseCounter)
+	assert.False(t, lockPersistence.IsLocked(client.Id), "Client_id should be unlocked")
+}
+
+func TestReapRecordedCompensationClientIdLockedHeldSuccess(t *testing.T) {
+	reaperSetup()
+
+	compensationNotPersisted()
+	_ = lockPersistence.Lock(context.Background(), client.Id)
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Compensated)
+	assert.Equal(t, []string{client.Id}, report.Held)
+	assert.Equal(t, status.Created, operationPersistence.GetAllOperations()[0].Status)
+	assert.Equal(t, outbox_status.Pending, outboxEntries(outbox_type.Compensation)[0].Status)
+	assert.Equal(t, 1, clientPersistence.ReleaseCounter)
+}
+
+func TestReapRecordedCompensationReleaseReservationFailure(t *testing.T) {
+	reaperSetup()
+
+	compensationNotPersisted()
+	clientPersistence.ReleaseError = errors.New("release error")
+	logger.Reset()
+
+	report, err := reaperUseCase.Reap(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Compensated)
+	assert.Equal(t, []string{client.Id}, report.Failed)
+	assert.Equal(t, outbox_status.Pending, outboxEntries(outbox_type.Compensation)[0].Status)
+	assert.Equal(t, 2, clientPersistence.ReleaseCounter)
+	assert.Equal(t, 1, clientPersistence.ReleaseLockCounter)
+	assert.False(t, client.Locked, "Client should be unlocked")
+	assert.False(t, lockPersistence.IsLocked(client.Id), "Client_id should be unlocked")
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
@@ -0,0 +1,283 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/usecase"
+	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var (
+	relayUseCase    adapters.RelayUseCaseAdapter
+	relayTimeSource = mocks.TimeSource()
+)
+
+// relaySetup runs the relay after the grace period of entries created now.
+func relaySetup() {
+	setup()
+
+	relayTimeSource.Reset()
+	relayTimeSource.Offset = properties.Properties().RelayGracePeriod + time.Second
+
+	relayUseCase = usecase.RelayUseCase(
+		outboxPersistence,
+		operationPersistence,
+		eventService,
+		relayTimeSource,
+		logger,
+	)
+}
+
+// pendingEvent creates an operation and its pending event, as if the validator stopped right after saving them.
+func pendingEvent() *model.OutboxEntry {
+	operation := model.NewOperation(uuid.NewString(), 50)
+	operationPersistence.AddOperation(operation)
+
+	event := model.NewOutboxEntry(outbox_type.Event, client.Id, operation, "")
+	outboxPersistence.AddEntry(event)
+
+	return event
+}
+
+func TestRelaySentSuccess(t *testing.T) {
+	relaySetup()
+
+	event := pendingEvent()
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{event.Id}, report.Sent)
+	assert.Empty(t, report.Discarded)
+	assert.Empty(t, report.Failed)
+	assert.Equal(t, outbox_status.Sent, event.Status)
+	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, 2, outboxPersistence.GetPendingCounter)
+	assert.Equal(t, 1, outboxPersistence.UpdateStatusCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestRelayNoPendingEventsSuccess(t *testing.T) {
+	relaySetup()
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Sent)
+	assert.Empty(t, report.Discarded)
+	assert.Empty(t, report.Failed)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 0, outboxPersistence.UpdateStatusCounter)
+}
+
+func TestRelayEventInsideGracePeriodSkippedSuccess(t *testing.T) {
+	relaySetup()
+
+	relayTimeSource.Offset = properties.Properties().RelayGracePeriod - time.Second
+	event := pendingEvent()
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Sent)
+	assert.Empty(t, report.Discarded)
+	assert.Empty(t, report.Failed)
+	assert.Equal(t, outbox_status.Pending, event.Status)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 0, operationPersistence.GetCounter)
+	assert.Equal(t, 0, outboxPersistence.UpdateStatusCounter)
+}
+
+func TestRelayValidationRunningSkippedSuccess(t *testing.T) {
+	relaySetup()
+
+	relayTimeSource.Offset = 0
+	outboxPersistence.UpdateStatusError = errors.New("update status error")
+	_ = validationUseCase.Validate(context.Background(), operationRequest)
+	outboxPersistence.UpdateStatusError = nil
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Sent)
+	assert.Equal(t, outbox_status.Pending, outboxEntries(outbox_type.Event)[0].Status)
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestRelaySentEventIsNotSentAgainSuccess(t *testing.T) {
+	relaySetup()
+
+	event := pendingEvent()
+	_, _ = relayUseCase.Relay(context.Background())
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Sent)
+	assert.Equal(t, outbox_status.Sent, event.Status)
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestRelayCancelledOperationDiscardedSuccess(t *testing.T) {
+	relaySetup()
+
+	event := pendingEvent()
+	event.Operation.Cancel()
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Sent)
+	assert.Equal(t, []string{event.Id}, report.Discarded)
+	assert.Equal(t, outbox_status.Discarded, event.Status)
+	assert.Equal(t, 0, eventService.SendCounter)
+}
+
+func TestRelayOperationNotFoundDiscardedSuccess(t *testing.T) {
+	relaySetup()
+
+	event := model.NewOutboxEntry(outbox_type.Event, client.Id, model.NewOperation(uuid.NewString(), 50), "")
+	outboxPersistence.AddEntry(event)
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{event.Id}, report.Discarded)
+	assert.Equal(t, outbox_status.Discarded, event.Status)
+	assert.Equal(t, 0, eventService.SendCounter)
+}
+
+func TestRelayPendingCompensationDiscardedSuccess(t *testing.T) {
+	relaySetup()
+
+	event := pendingEvent()
+	outboxPersistence.AddEntry(model.NewOutboxEntry(outbox_type.Compensation, client.Id, event.Operation, "send error"))
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{event.Id}, report.Discarded)
+	assert.Equal(t, outbox_status.Discarded, event.Status)
+	assert.Equal(t, status.Created, event.Operation.Status)
+	assert.Equal(t, 0, eventService.SendCounter)
+}
+
+func TestRelaySendFailure(t *testing.T) {
+	relaySetup()
+
+	event := pendingEvent()
+	eventService.SendError = errors.New("send error")
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Sent)
+	assert.Equal(t, []string{event.Id}, report.Failed)
+	assert.Equal(t, outbox_status.Pending, event.Status)
+	assert.Equal(t, 0, outboxPersistence.UpdateStatusCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestRelaySendFailureRetriedOnNextExecutionSuccess(t *testing.T) {
+	relaySetup()
+
+	event := pendingEvent()
+	eventService.SendError = errors.New("send error")
+	_, _ = relayUseCase.Relay(context.Background())
+	eventService.SendError = nil
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{event.Id}, report.Sent)
+	assert.Equal(t, outbox_status.Sent, event.Status)
+	assert.Equal(t, 2, eventService.SendCounter)
+}
+
+func TestRelayUpdateStatusFailure(t *testing.T) {
+	relaySetup()
+
+	event := pendingEvent()
+	outboxPersistence.UpdateStatusError = errors.New("update status error")
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Sent)
+	assert.Equal(t, []string{event.Id}, report.Failed)
+	assert.Equal(t, outbox_status.Pending, event.Status)
+	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestRelayGetOperationFailure(t *testing.T) {
+	relaySetup()
+
+	event := pendingEvent()
+	operationPersistence.GetError = errors.New("get error")
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{event.Id}, report.Failed)
+	assert.Equal(t, outbox_status.Pending, event.Status)
+	assert.Equal(t, 0, eventService.SendCounter)
+}
+
+func TestRelayGetPendingFailure(t *testing.T) {
+	relaySetup()
+
+	pendingEvent()
+	outboxPersistence.GetPendingError = errors.New("get pending error")
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, report)
+	assert.NotNil(t, err)
+	assert.Equal(t, "get pending error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error while using DynamoDB Outbox table", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "get pending error", err.Error())
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestRelayAfterValidatorStoppedBeforeMarkSentSuccess(t *testing.T) {
+	relaySetup()
+
+	outboxPersistence.UpdateStatusError = errors.New("update status error")
+	_ = validationUseCase.Validate(context.Background(), operationRequest)
+	outboxPersistence.UpdateStatusError = nil
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(report.Sent))
+	assert.Equal(t, outbox_status.Sent, outboxEntries(outbox_type.Event)[0].Status)
+	assert.Equal(t, 2, eventService.SendCounter)
+}
+
+func TestRelayAfterCompensationSuccess(t *testing.T) {
+	relaySetup()
+
+	eventService.SendError = errors.New("send error")
+	_ = validationUseCase.Validate(context.Background(), operationRequest)
+	eventService.SendError = nil
+
+	report, err := relayUseCase.Relay(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(report.Discarded))
+	assert.Equal(t, outbox_status.Discarded, outboxEntries(outbox_type.Event)[0].Status)
+	assert.Equal(t, 1, eventService.SendCounter)
+}
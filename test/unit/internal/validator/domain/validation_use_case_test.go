@@ -1,19 +1,32 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/analysis_strength"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/exchange"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/lock_type"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/operation_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/status"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/summary_type"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/exceptions"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/usecase"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/persistence"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/webservice"
 	"github.com/brienze1/crypto-robot-validator/pkg/custom_error"
 	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
 	"github.com/brienze1/crypto-robot-validator/test/mocks"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"os"
 	"testing"
 	"time"
 )
@@ -26,6 +39,10 @@ var (
 	cryptoService        = mocks.BiscointWebService()
 	operationPersistence = mocks.DynamoDBOperationPersistence()
 	eventService         = mocks.SnsEventService()
+	outboxPersistence    = mocks.DynamoDBOutboxPersistence()
+	circuitBreaker       = mocks.CircuitBreaker()
+	exchangeRegistry     = mocks.ExchangeRegistry(clientService, cryptoService, circuitBreaker)
+	paperExchange        = mocks.PaperExchange()
 	logger               = mocks.Logger()
 )
 
@@ -44,15 +61,23 @@ func setup() {
 	cryptoService.Reset()
 	operationPersistence.Reset()
 	eventService.Reset()
+	outboxPersistence.Reset()
+	circuitBreaker.Reset()
+	exchangeRegistry.Reset()
+	paperExchange.Reset()
 	logger.Reset()
 
+	clientPersistence.OperationPersistence = operationPersistence
+	clientPersistence.OutboxPersistence = outboxPersistence
+
 	validationUseCase = usecase.ValidationUseCase(
 		lockPersistence,
 		clientPersistence,
-		clientService,
-		cryptoService,
+		exchangeRegistry,
+		paperExchange,
 		operationPersistence,
 		eventService,
+		outboxPersistence,
 		logger,
 	)
 
@@ -60,41 +85,43 @@ func setup() {
 		ClientId:  uuid.NewString(),
 		Operation: operation_type.Buy,
 		Symbol:    symbol.Bitcoin,
+		Analysis:  analysis_strength.StrongBuy,
 		StartTime: time.Now(),
 	}
 
 	client = &model.Client{
-		Id:                        operationRequest.ClientId,
-		Active:                    true,
-		LockedUntil:               time.Now(),
-		Locked:                    false,
-		CashAvailable:             10000,
-		CashAmount:                10000,
-		CashReserved:              0,
-		CryptoAvailable:           1,
-		CryptoAmount:              1,
-		CryptoReserved:            0,
+		Id:            operationRequest.ClientId,
+		Active:        true,
+		Exchange:      exchange.Biscoint,
+		LockedUntil:   time.Now(),
+		Locked:        false,
+		CashAvailable: 10000,
+		CashAmount:    10000,
+		CashReserved:  0,
+		Crypto: map[symbol.Symbol]*model.CryptoBalance{
+			symbol.Bitcoin: {Available: 1, Amount: 1, Reserved: 0},
+		},
 		OperationAmountPercentage: 5,
 		DayStopLoss:               100,
 		MonthStopLoss:             100,
-		BuyOn:                     2,
-		SellOn:                    2,
+		BuyOn:                     analysis_strength.Buy,
+		SellOn:                    analysis_strength.Sell,
 		Symbols:                   []string{"BTC"},
 		Summary: []*model.Summary{
 			{
 				Type:         summary_type.Day,
-				Day:          time.Now().Day(),
-				Month:        int(time.Now().Month()),
-				Year:         time.Now().Year(),
+				Day:          time.Now().UTC().Day(),
+				Month:        int(time.Now().UTC().Month()),
+				Year:         time.Now().UTC().Year(),
 				AmountSold:   0,
 				AmountBought: 0,
 				Profit:       50.00,
 			},
 			{
 				Type:         summary_type.Month,
-				Day:          time.Now().Day(),
-				Month:        int(time.Now().Month()),
-				Year:         time.Now().Year(),
+				Day:          time.Now().UTC().Day(),
+				Month:        int(time.Now().UTC().Month()),
+				Year:         time.Now().UTC().Year(),
 				AmountSold:   0,
 				AmountBought: 0,
 				Profit:       50.00,
@@ -114,7 +141,7 @@ func setup() {
 func TestValidateBuySuccess(t *testing.T) {
 	setup()
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.Nil(t, err)
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
@@ -126,25 +153,49 @@ func TestValidateBuySuccess(t *testing.T) {
 	assert.Equal(t, symbol.Brl, operationPersistence.GetAllOperations()[0].Base)
 	assert.Equal(t, client.OperationStopLoss, operationPersistence.GetAllOperations()[0].StopLoss)
 	assert.Equal(t, client.CashAvailable*client.OperationAmountPercentage/100, operationPersistence.GetAllOperations()[0].Amount)
+	assert.Equal(t, model.ExchangeFee(client.Exchange).Charge(client.CashAvailable*client.OperationAmountPercentage/100), operationPersistence.GetAllOperations()[0].Fee)
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
+	assert.Equal(t, 0, clientPersistence.LockUntilCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 1, operationPersistence.SaveCounter)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, 1, len(outboxEntries(outbox_type.Event)))
+	assert.Equal(t, outbox_status.Sent, outboxEntries(outbox_type.Event)[0].Status)
+	assert.Equal(t, operationPersistence.GetAllOperations()[0].Id, outboxEntries(outbox_type.Event)[0].Operation.Id)
+	assert.Equal(t, 1, outboxPersistence.UpdateStatusCounter)
 	assert.Equal(t, 2, logger.InfoCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
 }
 
+func TestValidateMarkEventSentFailureSuccess(t *testing.T) {
+	setup()
+
+	outboxPersistence.UpdateStatusError = errors.New("update status error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, 1, outboxPersistence.UpdateStatusCounter)
+	assert.Equal(t, outbox_status.Pending, outboxEntries(outbox_type.Event)[0].Status)
+	assert.Equal(t, status.Created, operationPersistence.GetAllOperations()[0].Status)
+	assert.Equal(t, 1, logger.WarningCallCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
 func TestValidateBuyLessThanExpectedOperationCashAmountSuccess(t *testing.T) {
 	setup()
 
 	clientService.ClientBrlBalance = 499.0
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.Nil(t, err)
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
@@ -155,26 +206,102 @@ func TestValidateBuyLessThanExpectedOperationCashAmountSuccess(t *testing.T) {
 	assert.Equal(t, symbol.Bitcoin, operationPersistence.GetAllOperations()[0].Quote)
 	assert.Equal(t, symbol.Brl, operationPersistence.GetAllOperations()[0].Base)
 	assert.Equal(t, client.OperationStopLoss, operationPersistence.GetAllOperations()[0].StopLoss)
-	assert.Equal(t, clientService.ClientBrlBalance, operationPersistence.GetAllOperations()[0].Amount)
+	assert.Equal(t, model.ExchangeFee(client.Exchange).Net(clientService.ClientBrlBalance), operationPersistence.GetAllOperations()[0].Amount)
+	assert.Equal(t, clientService.ClientBrlBalance, operationPersistence.GetAllOperations()[0].Reserved())
+	assert.Equal(t, clientService.ClientBrlBalance, client.CashReserved)
+	assert.Equal(t, 0.0, client.CashAmount)
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 1, operationPersistence.SaveCounter)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 1, eventService.SendCounter)
 	assert.Equal(t, 2, logger.InfoCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
 }
 
+func TestValidateBuyStrongBuyOnSuccess(t *testing.T) {
+	setup()
+
+	client.BuyOn = analysis_strength.StrongBuy
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, operation_type.Buy, operationPersistence.GetAllOperations()[0].Type)
+	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestValidateBuyWithoutAnalysisAndBuyOnSuccess(t *testing.T) {
+	setup()
+
+	operationRequest.Analysis = ""
+	client.BuyOn = ""
+	client.SellOn = ""
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, operation_type.Buy, operationPersistence.GetAllOperations()[0].Type)
+	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestValidateSellWithoutAnalysisAndSellOnSuccess(t *testing.T) {
+	setup()
+
+	operationRequest.Operation = operation_type.Sell
+	operationRequest.Analysis = ""
+	client.BuyOn = ""
+	client.SellOn = ""
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, operation_type.Sell, operationPersistence.GetAllOperations()[0].Type)
+	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestValidateBuyWithoutAnalysisSuccess(t *testing.T) {
+	setup()
+
+	operationRequest.Analysis = ""
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestValidateBuyWithoutBuyOnSuccess(t *testing.T) {
+	setup()
+
+	operationRequest.Analysis = analysis_strength.Neutral
+	client.BuyOn = ""
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
 func TestValidateSellSuccess(t *testing.T) {
 	setup()
 
 	operationRequest.Operation = operation_type.Sell
+	operationRequest.Analysis = analysis_strength.StrongSell
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.Nil(t, err)
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
@@ -185,15 +312,15 @@ func TestValidateSellSuccess(t *testing.T) {
 	assert.Equal(t, symbol.Brl, operationPersistence.GetAllOperations()[0].Quote)
 	assert.Equal(t, symbol.Bitcoin, operationPersistence.GetAllOperations()[0].Base)
 	assert.Equal(t, client.OperationStopLoss, operationPersistence.GetAllOperations()[0].StopLoss)
-	assert.Equal(t, client.CryptoAvailable*client.OperationAmountPercentage/100, operationPersistence.GetAllOperations()[0].Amount)
+	assert.Equal(t, client.Crypto[symbol.Bitcoin].Available*client.OperationAmountPercentage/100, operationPersistence.GetAllOperations()[0].Amount)
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 1, operationPersistence.SaveCounter)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 1, eventService.SendCounter)
 	assert.Equal(t, 2, logger.InfoCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
@@ -204,8 +331,9 @@ func TestValidateSellLessThanExpectedOperationCashAmountSuccess(t *testing.T) {
 
 	clientService.ClientCryptoBalance = 0.00499
 	operationRequest.Operation = operation_type.Sell
+	operationRequest.Analysis = analysis_strength.StrongSell
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.Nil(t, err)
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
@@ -217,14 +345,16 @@ func TestValidateSellLessThanExpectedOperationCashAmountSuccess(t *testing.T) {
 	assert.Equal(t, symbol.Bitcoin, operationPersistence.GetAllOperations()[0].Base)
 	assert.Equal(t, client.OperationStopLoss, operationPersistence.GetAllOperations()[0].StopLoss)
 	assert.Equal(t, clientService.ClientCryptoBalance, operationPersistence.GetAllOperations()[0].Amount)
+	assert.Equal(t, model.ExchangeFee(client.Exchange).Charge(clientService.ClientCryptoBalance*cryptoService.CoinExpectedSellValue), operationPersistence.GetAllOperations()[0].Fee)
+	assert.Equal(t, clientService.ClientCryptoBalance, operationPersistence.GetAllOperations()[0].Reserved())
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 1, operationPersistence.SaveCounter)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 1, eventService.SendCounter)
 	assert.Equal(t, 2, logger.InfoCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
@@ -235,7 +365,7 @@ func TestValidateEventServiceFailure(t *testing.T) {
 
 	eventService.SendError = errors.New("send error")
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.NotNil(t, err, "Error should not be nil")
 	assert.Equal(t, "Send error", err.(custom_error.BaseErrorAdapter).InternalError())
@@ -251,26 +381,181 @@ func TestValidateEventServiceFailure(t *testing.T) {
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 1, operationPersistence.SaveCounter)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 1, eventService.SendCounter)
-	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, status.Cancelled, operationPersistence.GetAllOperations()[0].Status)
+	assert.Equal(t, 0.0, client.CashReserved)
+	assert.Equal(t, 1, clientPersistence.ReleaseCounter)
+	assert.Equal(t, 0, outboxPersistence.SaveCounter)
+	assert.Equal(t, 0, outboxPersistence.UpdateStatusCounter)
+	assert.Equal(t, outbox_status.Pending, outboxEntries(outbox_type.Event)[0].Status)
+	assert.Equal(t, 3, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
-func TestValidateOperationPersistenceFailure(t *testing.T) {
+func TestValidateEventServiceFailureCompensationFailure(t *testing.T) {
+	setup()
+
+	eventService.SendError = errors.New("send error")
+	clientPersistence.ReleaseError = errors.New("release error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "send error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 1, clientPersistence.ReleaseCounter)
+	assert.Equal(t, 1, outboxPersistence.SaveCounter)
+	assert.Equal(t, 1, len(outboxEntries(outbox_type.Compensation)))
+	assert.Equal(t, outbox_status.Pending, outboxEntries(outbox_type.Compensation)[0].Status)
+	assert.Equal(t, client.Id, outboxEntries(outbox_type.Compensation)[0].ClientId)
+	assert.Equal(t, operationPersistence.GetAllOperations()[0].Id, outboxEntries(outbox_type.Compensation)[0].Operation.Id)
+	assert.Equal(t, "send error", outboxEntries(outbox_type.Compensation)[0].Reason)
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 2, logger.ErrorCallCounter)
+}
+
+func TestValidateEventServiceFailureOutboxFailure(t *testing.T) {
+	setup()
+
+	eventService.SendError = errors.New("send error")
+	clientPersistence.ReleaseError = errors.New("release error")
+	outboxPersistence.SaveError = errors.New("outbox error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "send error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 1, clientPersistence.ReleaseCounter)
+	assert.Equal(t, 1, outboxPersistence.SaveCounter)
+	assert.Equal(t, 0, len(outboxEntries(outbox_type.Compensation)))
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 3, logger.ErrorCallCounter)
+}
+
+func TestValidateReplayedRequestEventServiceFailure(t *testing.T) {
+	setup()
+
+	_ = validationUseCase.Validate(context.Background(), operationRequest)
+	eventService.SendError = errors.New("send error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, status.Cancelled, operationPersistence.GetAllOperations()[0].Status)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 1, clientPersistence.ReleaseCounter)
+	assert.Equal(t, 0.0, client.CashReserved)
+}
+
+func TestValidateEventServiceFailureRedeliveredSuccess(t *testing.T) {
+	setup()
+
+	eventService.SendError = errors.New("send error")
+	_ = validationUseCase.Validate(context.Background(), operationRequest)
+	eventService.SendError = nil
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, status.Created, operationPersistence.GetAllOperations()[0].Status)
+	assert.Equal(t, operationPersistence.GetAllOperations()[0].Reserved(), client.CashReserved)
+	assert.Equal(t, 2, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 2, eventService.SendCounter)
+	assert.Equal(t, 1, len(outboxEntries(outbox_type.Event)))
+	assert.Equal(t, outbox_status.Sent, outboxEntries(outbox_type.Event)[0].Status)
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+}
+
+// compensationNotPersisted fails the operation event and its compensation, leaving the operation CREATED as it is
+// still stored when the compensation could not be persisted.
+func compensationNotPersisted() {
+	eventService.SendError = errors.New("send error")
+	clientPersistence.ReleaseError = errors.New("release error")
+	_ = validationUseCase.Validate(context.Background(), operationRequest)
+	eventService.SendError = nil
+	clientPersistence.ReleaseError = nil
+
+	operationPersistence.GetAllOperations()[0].Status = status.Created
+}
+
+func TestValidatePendingCompensationRedeliveredSuccess(t *testing.T) {
+	setup()
+
+	compensationNotPersisted()
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, status.Created, operationPersistence.GetAllOperations()[0].Status)
+	assert.Equal(t, 2, clientPersistence.ReleaseCounter)
+	assert.Equal(t, 2, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 2, eventService.SendCounter)
+	assert.Equal(t, 1, outboxPersistence.GetCounter)
+	assert.Equal(t, outbox_status.Resolved, outboxEntries(outbox_type.Compensation)[0].Status)
+	assert.Equal(t, outbox_status.Sent, outboxEntries(outbox_type.Event)[0].Status)
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+}
+
+func TestValidatePendingCompensationRepairFailure(t *testing.T) {
+	setup()
+
+	compensationNotPersisted()
+	clientPersistence.ReleaseError = errors.New("release error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "release error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, 2, clientPersistence.ReleaseCounter)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, outbox_status.Pending, outboxEntries(outbox_type.Compensation)[0].Status)
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+}
+
+func TestValidatePendingCompensationGetFailure(t *testing.T) {
+	setup()
+
+	compensationNotPersisted()
+	outboxPersistence.GetError = errors.New("get error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "get error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, status.Created, operationPersistence.GetAllOperations()[0].Status)
+	assert.Equal(t, 1, clientPersistence.ReleaseCounter)
+	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+}
+
+func TestValidateSaveReservationFailure(t *testing.T) {
 	setup()
 
-	operationPersistence.SaveError = errors.New("save error")
+	clientPersistence.SaveReservationError = errors.New("save reservation error")
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "save error", err.(custom_error.BaseErrorAdapter).InternalError())
-	assert.Equal(t, "Error while using DynamoDB Operation table", err.(custom_error.BaseErrorAdapter).Description())
-	assert.Equal(t, "save error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, "SaveReservation error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error while using DynamoDB Client table", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "save reservation error", err.(custom_error.BaseErrorAdapter).Error())
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
 	assert.Equal(t, false, client.Locked)
 	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
@@ -279,235 +564,1141 @@ func TestValidateOperationPersistenceFailure(t *testing.T) {
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 1, operationPersistence.SaveCounter)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
-func TestValidateCreateOperationDayStopLossFailure(t *testing.T) {
+func TestValidateCreateOperationClientInactiveFailure(t *testing.T) {
 	setup()
 
-	client.Summary[0].Profit = -1000.00
+	client.Active = false
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "Client day stop loss reached", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Client is not active", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.ClientInactive.Name(), err.(custom_error.BaseErrorAdapter).Code())
 	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
 	assert.Equal(t, "validation error", err.(custom_error.BaseErrorAdapter).Error())
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
 	assert.Equal(t, false, client.Locked)
-	assert.Equal(t, time_utils.Time().Tomorrow(), client.LockedUntil)
 	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 0, operationPersistence.SaveCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
-func TestValidateCreateOperationMonthStopLossFailure(t *testing.T) {
+func TestValidateCreateOperationClientLockedUntilFailure(t *testing.T) {
 	setup()
 
-	client.Summary[1].Profit = -1000.00
+	client.LockedUntil = time_utils.Time().Tomorrow()
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "Client month stop loss reached", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Client locked_until date not reached", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.ClientLockedUntil.Name(), err.(custom_error.BaseErrorAdapter).Code())
 	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
 	assert.Equal(t, "validation error", err.(custom_error.BaseErrorAdapter).Error())
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
 	assert.Equal(t, false, client.Locked)
-	assert.Equal(t, time_utils.Time().NextMonth(), client.LockedUntil)
 	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 0, operationPersistence.SaveCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
-func TestValidateCreateOperationMinCashFailure(t *testing.T) {
+func TestValidateCreateOperationSymbolNotAllowedFailure(t *testing.T) {
 	setup()
 
-	clientService.ClientBrlBalance = 10
+	operationRequest.Symbol = "ETH"
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "Client does not have minimum cash amount", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Client does not operate symbol ETH", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.SymbolNotAllowed.Name(), err.(custom_error.BaseErrorAdapter).Code())
 	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
 	assert.Equal(t, "validation error", err.(custom_error.BaseErrorAdapter).Error())
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
 	assert.Equal(t, false, client.Locked)
-	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
 	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 0, operationPersistence.SaveCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
-func TestValidateCreateOperationMinCryptoFailure(t *testing.T) {
+func TestValidateCreateOperationSymbolNotSupportedFailure(t *testing.T) {
 	setup()
 
-	clientService.ClientCryptoBalance = 0.00001
+	operationRequest.Symbol = "DOGE"
+	client.Symbols = append(client.Symbols, "DOGE")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Symbol DOGE is not supported", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.SymbolNotSupported.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateBuyEthereumSuccess(t *testing.T) {
+	setup()
+
+	operationRequest.Symbol = symbol.Ethereum
+	client.Symbols = append(client.Symbols, symbol.Ethereum.Name())
+	cryptoService.CoinExpectedBuyValue = 8000.0
+	cryptoService.CoinExpectedSellValue = 7900.0
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, operation_type.Buy, operationPersistence.GetAllOperations()[0].Type)
+	assert.Equal(t, symbol.Ethereum, operationPersistence.GetAllOperations()[0].Quote)
+	assert.Equal(t, symbol.Brl, operationPersistence.GetAllOperations()[0].Base)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestValidateSellEthereumSuccess(t *testing.T) {
+	setup()
+
+	operationRequest.Symbol = symbol.Ethereum
 	operationRequest.Operation = operation_type.Sell
+	operationRequest.Analysis = analysis_strength.StrongSell
+	client.Symbols = append(client.Symbols, symbol.Ethereum.Name())
+	client.Crypto[symbol.Ethereum] = &model.CryptoBalance{Available: 2, Amount: 2, Reserved: 0.5}
+	clientService.ClientCryptoSymbol = symbol.Ethereum
+	clientService.ClientCryptoBalance = 2.5
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, operation_type.Sell, operationPersistence.GetAllOperations()[0].Type)
+	assert.Equal(t, symbol.Brl, operationPersistence.GetAllOperations()[0].Quote)
+	assert.Equal(t, symbol.Ethereum, operationPersistence.GetAllOperations()[0].Base)
+	assert.Equal(t, 2*client.OperationAmountPercentage/100, operationPersistence.GetAllOperations()[0].Amount)
+	assert.Equal(t, 0.5+2*client.OperationAmountPercentage/100, client.Crypto[symbol.Ethereum].Reserved)
+	assert.Equal(t, 2-2*client.OperationAmountPercentage/100, client.Crypto[symbol.Ethereum].Amount)
+	assert.Equal(t, 0.0, client.Crypto[symbol.Bitcoin].Amount)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestValidateCreateOperationInvalidAnalysisFailure(t *testing.T) {
+	setup()
 
-	err := validationUseCase.Validate(operationRequest)
+	operationRequest.Analysis = "INVALID"
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "Client does not have minimum crypto amount", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Operation request analysis is not valid", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.InvalidAnalysis.Name(), err.(custom_error.BaseErrorAdapter).Code())
 	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
 	assert.Equal(t, "validation error", err.(custom_error.BaseErrorAdapter).Error())
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
 	assert.Equal(t, false, client.Locked)
-	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
 	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 0, operationPersistence.SaveCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
-func TestValidateGetCryptoFailure(t *testing.T) {
+func TestValidateCreateOperationBuyOnNotReachedFailure(t *testing.T) {
 	setup()
 
-	cryptoService.GetCryptoError = errors.New("get crypto error")
+	operationRequest.Analysis = analysis_strength.Neutral
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "GetCrypto error", err.(custom_error.BaseErrorAdapter).InternalError())
-	assert.Equal(t, "Error while performing Biscoint API request", err.(custom_error.BaseErrorAdapter).Description())
-	assert.Equal(t, "get crypto error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, "Analysis received does not reach client buy_on", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.BuyOnNotReached.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "validation error", err.(custom_error.BaseErrorAdapter).Error())
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
 	assert.Equal(t, false, client.Locked)
-	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
 	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 0, operationPersistence.SaveCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
-func TestValidateGetBalanceFailure(t *testing.T) {
+func TestValidateCreateOperationSellOnNotReachedFailure(t *testing.T) {
 	setup()
 
-	clientService.GetBalanceError = errors.New("get balance error")
+	operationRequest.Operation = operation_type.Sell
+	operationRequest.Analysis = analysis_strength.Buy
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "GetBalance error", err.(custom_error.BaseErrorAdapter).InternalError())
-	assert.Equal(t, "Error while performing Biscoint API request", err.(custom_error.BaseErrorAdapter).Description())
-	assert.Equal(t, "get balance error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, "Analysis received does not reach client sell_on", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.SellOnNotReached.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "validation error", err.(custom_error.BaseErrorAdapter).Error())
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
 	assert.Equal(t, false, client.Locked)
-	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
 	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
-	assert.Equal(t, 0, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 0, operationPersistence.SaveCounter)
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
-func TestValidateLockClientFailure(t *testing.T) {
+func TestValidateCreateOperationDayStopLossFailure(t *testing.T) {
 	setup()
 
-	clientPersistence.LockError = errors.New("lock client error")
+	client.Summary[0].Profit = -1000.00
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "Lock error", err.(custom_error.BaseErrorAdapter).InternalError())
-	assert.Equal(t, "Error while using DynamoDB Client table", err.(custom_error.BaseErrorAdapter).Description())
-	assert.Equal(t, "lock client error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, "Client day stop loss reached", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.DayStopLoss.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "validation error", err.(custom_error.BaseErrorAdapter).Error())
 	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
 	assert.Equal(t, false, client.Locked)
-	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
+	assert.Equal(t, time_utils.TimeIn(client.Location()).Tomorrow(), client.LockedUntil)
 	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
-	assert.Equal(t, 0, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
-	assert.Equal(t, 0, clientService.GetBalanceCounter)
-	assert.Equal(t, 0, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 0, operationPersistence.SaveCounter)
+	assert.Equal(t, 1, clientPersistence.LockUntilCounter)
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
-func TestValidateGetClientFailure(t *testing.T) {
+func TestValidateRollSummaryPrunesStaleSummariesSuccess(t *testing.T) {
 	setup()
 
-	clientPersistence.GetClientError = errors.New("get client error")
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+	lastYear := time.Now().UTC().AddDate(-1, 0, 0)
+	client.Summary = append(client.Summary,
+		&model.Summary{Type: summary_type.Day, Day: yesterday.Day(), Month: int(yesterday.Month()), Year: yesterday.Year(), Profit: -1000.00},
+		&model.Summary{Type: summary_type.Month, Day: 1, Month: int(lastYear.Month()), Year: lastYear.Year(), Profit: -1000.00},
+	)
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
-	assert.NotNil(t, err, "Error should not be nil")
-	assert.Equal(t, "GetClient error", err.(custom_error.BaseErrorAdapter).InternalError())
-	assert.Equal(t, "Error while using DynamoDB Client table", err.(custom_error.BaseErrorAdapter).Description())
-	assert.Equal(t, "get client error", err.(custom_error.BaseErrorAdapter).Error())
-	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
-	assert.Equal(t, false, client.Locked)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(client.Summary))
+	assert.Equal(t, summary_type.Day, client.Summary[0].Type)
+	assert.Equal(t, time.Now().UTC().Day(), client.Summary[0].Day)
+	assert.Equal(t, 50.00, client.Summary[0].Profit)
+	assert.Equal(t, summary_type.Month, client.Summary[1].Type)
+	assert.Equal(t, time.Now().UTC().Year(), client.Summary[1].Year)
+	assert.Equal(t, 50.00, client.Summary[1].Profit)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
+}
+
+func TestValidateRollSummaryCreatesCurrentSummariesSuccess(t *testing.T) {
+	setup()
+
+	client.Summary = nil
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	now := time.Now().UTC()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(client.Summary))
+	assert.Equal(t, &model.Summary{Type: summary_type.Day, Day: now.Day(), Month: int(now.Month()), Year: now.Year()}, client.Summary[0])
+	assert.Equal(t, &model.Summary{Type: summary_type.Month, Day: 1, Month: int(now.Month()), Year: now.Year()}, client.Summary[1])
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
+}
+
+func TestValidateDayStopLossOtherTimezoneDaySuccess(t *testing.T) {
+	setup()
+
+	// Kiritimati (UTC+14) and Pago Pago (UTC-11) are never on the same day
+	client.Timezone = "Pacific/Kiritimati"
+	pagoPagoNow := time.Now().In(time_utils.Location("Pacific/Pago_Pago"))
+	client.Summary[0].Day = pagoPagoNow.Day()
+	client.Summary[0].Month = int(pagoPagoNow.Month())
+	client.Summary[0].Year = pagoPagoNow.Year()
+	client.Summary[0].Profit = -1000.00
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	kiritimatiNow := time.Now().In(time_utils.Location("Pacific/Kiritimati"))
+	assert.Nil(t, err)
+	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
+	assert.Equal(t, kiritimatiNow.Day(), client.Summary[len(client.Summary)-1].Day)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
+}
+
+func TestValidateCreateOperationDayStopLossClientTimezoneFailure(t *testing.T) {
+	setup()
+
+	client.Timezone = "Pacific/Kiritimati"
+	location := time_utils.Location(client.Timezone)
+	now := time.Now().In(location)
+	client.Summary[0].Day = now.Day()
+	client.Summary[0].Month = int(now.Month())
+	client.Summary[0].Year = now.Year()
+	client.Summary[0].Profit = -1000.00
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, error_code.DayStopLoss.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, location), client.LockedUntil)
+	assert.Equal(t, 1, clientPersistence.LockUntilCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+}
+
+func TestValidateCreateOperationDayStopLossLockUntilFailure(t *testing.T) {
+	setup()
+
+	client.Summary[0].Profit = -1000.00
+	clientPersistence.LockUntilError = errors.New("lock until error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Client day stop loss reached", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.DayStopLoss.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, time_utils.TimeIn(client.Location()).Tomorrow(), client.LockedUntil)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, lockPersistence.LockCounter)
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientPersistence.LockCounter)
+	assert.Equal(t, 1, clientPersistence.LockUntilCounter)
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+	assert.Equal(t, 1, logger.WarningCallCounter)
+}
+
+func TestValidateCreateOperationMonthStopLossFailure(t *testing.T) {
+	setup()
+
+	client.Summary[1].Profit = -1000.00
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Client month stop loss reached", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.MonthStopLoss.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "validation error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, time_utils.TimeIn(client.Location()).NextMonth(), client.LockedUntil)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, lockPersistence.LockCounter)
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientPersistence.LockCounter)
+	assert.Equal(t, 1, clientPersistence.LockUntilCounter)
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCreateOperationMinCashFailure(t *testing.T) {
+	setup()
+
+	clientService.ClientBrlBalance = 10
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Client does not have minimum cash amount", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.MinimumCashAmount.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "validation error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, lockPersistence.LockCounter)
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientPersistence.LockCounter)
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCreateOperationMinCryptoFailure(t *testing.T) {
+	setup()
+
+	clientService.ClientCryptoBalance = 0.00001
+	operationRequest.Operation = operation_type.Sell
+	operationRequest.Analysis = analysis_strength.StrongSell
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Client does not have minimum crypto amount", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.MinimumCryptoAmount.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, "Error while validating operation", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "validation error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, lockPersistence.LockCounter)
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientPersistence.LockCounter)
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateBuyReservesFeeSuccess(t *testing.T) {
+	setup()
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	operation := operationPersistence.GetAllOperations()[0]
+	assert.Nil(t, err)
+	assert.Equal(t, 500.0, operation.Amount)
+	assert.Equal(t, 2.5, operation.Fee)
+	assert.Equal(t, 502.5, client.CashReserved)
+	assert.Equal(t, 497.5, client.CashAmount)
+	assert.Equal(t, 2.5, outboxEntries(outbox_type.Event)[0].Operation.Fee)
+}
+
+func TestValidateBinanceClientFeeSuccess(t *testing.T) {
+	setup()
+
+	client.Exchange = exchange.Binance
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 500.0, operationPersistence.GetAllOperations()[0].Amount)
+	assert.Equal(t, 0.5, operationPersistence.GetAllOperations()[0].Fee)
+}
+
+func TestValidateEventServiceFailureReleasesFeeSuccess(t *testing.T) {
+	setup()
+
+	eventService.SendError = errors.New("send error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, 0.0, client.CashReserved)
+	assert.Equal(t, clientService.ClientBrlBalance, client.CashAmount)
+}
+
+func TestValidateCreateOperationMinCashAfterFeeFailure(t *testing.T) {
+	setup()
+
+	clientService.ClientBrlBalance = cryptoService.CoinExpectedBuyValue * model.MinimumOrderSize(operation_type.Buy, symbol.Bitcoin)
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, error_code.MinimumCashAmount.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 0, eventService.SendCounter)
+}
+
+func TestValidateCreateOperationSellFeeChargedOnProceedsSuccess(t *testing.T) {
+	setup()
+
+	clientService.ClientCryptoBalance = model.MinimumOrderSize(operation_type.Sell, symbol.Bitcoin)
+	client.OperationAmountPercentage = 100
+	operationRequest.Operation = operation_type.Sell
+	operationRequest.Analysis = analysis_strength.StrongSell
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, model.MinimumOrderSize(operation_type.Sell, symbol.Bitcoin), operationPersistence.GetAllOperations()[0].Amount)
+	assert.Equal(t, model.ExchangeFee(client.Exchange).Charge(model.MinimumOrderSize(operation_type.Sell, symbol.Bitcoin)*cryptoService.CoinExpectedSellValue), operationPersistence.GetAllOperations()[0].Fee)
+	assert.Equal(t, model.MinimumOrderSize(operation_type.Sell, symbol.Bitcoin), client.Crypto[symbol.Bitcoin].Reserved)
+	assert.Equal(t, 0.0, client.Crypto[symbol.Bitcoin].Amount)
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestValidateSellEthereumMinCryptoFailure(t *testing.T) {
+	setup()
+
+	operationRequest.Symbol = symbol.Ethereum
+	operationRequest.Operation = operation_type.Sell
+	operationRequest.Analysis = analysis_strength.StrongSell
+	client.Symbols = append(client.Symbols, symbol.Ethereum.Name())
+	client.Crypto[symbol.Ethereum] = &model.CryptoBalance{Available: 0.005, Amount: 0.005}
+	clientService.ClientCryptoSymbol = symbol.Ethereum
+	clientService.ClientCryptoBalance = 0.005
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, error_code.MinimumCryptoAmount.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Greater(t, 0.005, model.MinimumOrderSize(operation_type.Sell, symbol.Bitcoin), "Amount covers the BTC minimum order size")
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 0, eventService.SendCounter)
+}
+
+func TestValidateSellConfiguredMinCryptoFailure(t *testing.T) {
+	setup()
+
+	minimumCryptoSellOperation := os.Getenv("MINIMUM_CRYPTO_SELL_OPERATION")
+	_ = os.Setenv("MINIMUM_CRYPTO_SELL_OPERATION", "BTC:0.01")
+	properties.Properties().Reload()
+	t.Cleanup(func() {
+		_ = os.Setenv("MINIMUM_CRYPTO_SELL_OPERATION", minimumCryptoSellOperation)
+		properties.Properties().Reload()
+	})
+
+	operationRequest.Operation = operation_type.Sell
+	operationRequest.Analysis = analysis_strength.StrongSell
+	client.Crypto[symbol.Bitcoin] = &model.CryptoBalance{Available: 0.005, Amount: 0.005}
+	clientService.ClientCryptoBalance = 0.005
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, error_code.MinimumCryptoAmount.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Greater(t, 0.005, symbol.Bitcoin.MinimumOrderSize(), "Amount covers the default BTC minimum order size")
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 0, eventService.SendCounter)
+}
+
+func TestValidateCreateOperationZeroPriceFailure(t *testing.T) {
+	setup()
+
+	cryptoService.CoinExpectedBuyValue = 0
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Quote buy and sell values must be positive", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.InvalidQuote.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCreateOperationNegativePriceFailure(t *testing.T) {
+	setup()
+
+	cryptoService.CoinExpectedSellValue = -1
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Quote buy and sell values must be positive", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.InvalidQuote.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCreateOperationBuyLowerThanSellFailure(t *testing.T) {
+	setup()
+
+	cryptoService.CoinExpectedBuyValue = 98000.0
+	cryptoService.CoinExpectedSellValue = 99000.0
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Quote buy value is lower than sell value", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.InvalidQuote.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCreateOperationStaleQuoteFailure(t *testing.T) {
+	setup()
+
+	cryptoService.CoinTimestamp = time.Now().Add(-properties.Properties().QuoteMaxAge - time.Second)
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Quote is older than max age", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.StaleQuote.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCreateOperationMissingTimestampFailure(t *testing.T) {
+	setup()
+
+	cryptoService.CoinTimestamp = time.Time{}
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Quote timestamp is missing", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.StaleQuote.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCreateOperationStaleRequestFailure(t *testing.T) {
+	setup()
+
+	operationRequest.StartTime = time.Now().Add(-properties.Properties().RequestMaxAge - time.Second)
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Operation request is older than max age", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.StaleRequest.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCreateOperationMissingStartTimeSuccess(t *testing.T) {
+	setup()
+
+	operationRequest.StartTime = time.Time{}
+	operationRequest.MessageId = uuid.NewString()
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, operationRequest.OperationId(), operationPersistence.GetAllOperations()[0].Id)
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestValidateSignalPriceSuccess(t *testing.T) {
+	setup()
+
+	client.SlippageTolerance = 1
+	operationRequest.SignalPrice = 99500.0
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, operationRequest.SignalPrice, operationPersistence.GetAllOperations()[0].SignalPrice)
+	assert.Equal(t, operationRequest.SignalPrice, outboxEntries(outbox_type.Event)[0].Operation.SignalPrice)
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestValidateSlippageToleranceDisabledSuccess(t *testing.T) {
+	setup()
+
+	operationRequest.SignalPrice = 50000.0
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, operationRequest.SignalPrice, operationPersistence.GetAllOperations()[0].SignalPrice)
+}
+
+func TestValidateCreateOperationBuySlippageFailure(t *testing.T) {
+	setup()
+
+	client.SlippageTolerance = 1
+	operationRequest.SignalPrice = 98000.0
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Price moved beyond client slippage_tolerance since the signal", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, error_code.PriceSlippage.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCreateOperationSellSlippageFailure(t *testing.T) {
+	setup()
+
+	client.SlippageTolerance = 1
+	operationRequest.Operation = operation_type.Sell
+	operationRequest.Analysis = analysis_strength.StrongSell
+	operationRequest.SignalPrice = 100000.0
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+
+	operationRequest.StartTime = time.Now()
+	operationRequest.SignalPrice = 101000.0
+
+	err = validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, error_code.PriceSlippage.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestValidateGetCryptoFailure(t *testing.T) {
+	setup()
+
+	cryptoService.GetCryptoError = errors.New("get crypto error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "GetCrypto error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error while performing Biscoint API request", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "get crypto error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, lockPersistence.LockCounter)
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientPersistence.LockCounter)
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateGetBalanceFailure(t *testing.T) {
+	setup()
+
+	clientService.GetBalanceError = errors.New("get balance error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "GetBalance error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error while performing Biscoint API request", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "get balance error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, lockPersistence.LockCounter)
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientPersistence.LockCounter)
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 0, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCircuitOpenFailure(t *testing.T) {
+	setup()
+
+	circuitBreaker.Open = true
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, "Circuit is open", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, 1, circuitBreaker.CheckCounter)
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, lockPersistence.LockCounter)
+	assert.Equal(t, 0, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 0, clientPersistence.LockCounter)
+	assert.Equal(t, 0, clientPersistence.UnlockCounter)
+	assert.Equal(t, 0, clientService.GetBalanceCounter)
+	assert.Equal(t, 0, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateCircuitOpenNotLockedFailure(t *testing.T) {
+	setup()
+
+	circuitBreaker.Open = true
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, 0, lockPersistence.LockCounter)
+	assert.Equal(t, 0, lockPersistence.WatchdogCounter)
+	assert.Equal(t, 0, lockPersistence.UnlockCounter)
+	assert.Equal(t, 0, clientPersistence.LockCounter)
+	assert.Equal(t, 0, len(outboxEntries(outbox_type.Unlock)))
+}
+
+func TestValidateSimulationClientCircuitOpenFailure(t *testing.T) {
+	setup()
+
+	client.Simulation = true
+	circuitBreaker.Open = true
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, 1, circuitBreaker.CheckCounter)
+	assert.Equal(t, 0, clientPersistence.LockCounter)
+	assert.Equal(t, 0, paperExchange.ReserveCounter)
+}
+
+func TestValidateSimulationClientRecordedPriceFeedCircuitOpenSuccess(t *testing.T) {
+	setup()
+
+	client.Simulation = true
+	paperExchange.PriceFeed = true
+	circuitBreaker.Open = true
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, circuitBreaker.CheckCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
+	assert.Equal(t, 1, paperExchange.ReserveCounter)
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestValidateCircuitHalfOpenTrialClosesSuccess(t *testing.T) {
+	setup()
+
+	redis := mocks.RedisServer()
+	defer redis.Teardown()
+
+	breaker := persistence.RedisCircuitBreaker(logger, redis, exchange.Biscoint.Name())
+	clientService.CoinExpectedBuyValue = cryptoService.CoinExpectedBuyValue
+	clientService.CoinExpectedSellValue = cryptoService.CoinExpectedSellValue
+	validationUseCase = usecase.ValidationUseCase(
+		lockPersistence,
+		clientPersistence,
+		webservice.ExchangeRegistry().Register(exchange.Biscoint, clientService, breaker),
+		paperExchange,
+		operationPersistence,
+		eventService,
+		outboxPersistence,
+		logger,
+	)
+
+	for i := 0; i < properties.Properties().CircuitBreaker.FailureThreshold; i++ {
+		breaker.Failure(context.Background())
+	}
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, 0, clientService.GetBalanceCounter)
+
+	redis.FastForward(properties.Properties().CircuitBreaker.OpenDuration)
+
+	err = validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 1, clientService.GetCryptoCounter)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Nil(t, breaker.Allow(context.Background()))
+	assert.Nil(t, breaker.Allow(context.Background()))
+}
+
+func TestValidateClientExchangeSuccess(t *testing.T) {
+	setup()
+
+	client.Exchange = exchange.Binance
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, exchangeRegistry.GetCounter)
+	assert.Equal(t, []exchange.Exchange{exchange.Binance, exchange.Binance}, exchangeRegistry.GetExchanges)
+	assert.Equal(t, 1, circuitBreaker.CheckCounter)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+}
+
+func TestValidateSimulationClientSuccess(t *testing.T) {
+	setup()
+
+	client.Simulation = true
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, circuitBreaker.CheckCounter)
+	assert.Equal(t, 1, paperExchange.ExchangeCounter)
+	assert.Equal(t, 1, paperExchange.ReserveCounter)
+	assert.Equal(t, 0, paperExchange.ReleaseCounter)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, true, operationPersistence.GetAllOperations()[0].Simulation)
+	assert.Equal(t, true, paperExchange.Reserved[operationPersistence.GetAllOperations()[0].Id])
+	assert.Equal(t, true, outboxEntries(outbox_type.Event)[0].Operation.Simulation)
+	assert.Equal(t, 1, eventService.SendCounter)
+}
+
+func TestValidateClientNotSimulatedSuccess(t *testing.T) {
+	setup()
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, paperExchange.ExchangeCounter)
+	assert.Equal(t, 0, paperExchange.ReserveCounter)
+	assert.Equal(t, false, operationPersistence.GetAllOperations()[0].Simulation)
+	assert.Equal(t, false, outboxEntries(outbox_type.Event)[0].Operation.Simulation)
+}
+
+func TestValidateSimulationClientReserveFailure(t *testing.T) {
+	setup()
+
+	client.Simulation = true
+	paperExchange.ReserveError = errors.New("reserve error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Reserve error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error while using the paper exchange", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 1, paperExchange.ReserveCounter)
+	assert.Equal(t, 1, paperExchange.ReleaseCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, status.Cancelled, operationPersistence.GetAllOperations()[0].Status)
+	assert.Equal(t, 0.0, client.CashReserved)
+	assert.Equal(t, 1, clientPersistence.ReleaseCounter)
+}
+
+func TestValidateSimulationClientEventServiceFailure(t *testing.T) {
+	setup()
+
+	client.Simulation = true
+	eventService.SendError = errors.New("send error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, 1, paperExchange.ReserveCounter)
+	assert.Equal(t, 1, paperExchange.ReleaseCounter)
+	assert.Equal(t, 0, len(paperExchange.Reserved))
+	assert.Equal(t, status.Cancelled, operationPersistence.GetAllOperations()[0].Status)
+	assert.Equal(t, 1, clientPersistence.ReleaseCounter)
+}
+
+func TestValidateExchangeNotSupportedFailure(t *testing.T) {
+	setup()
+
+	client.Exchange = exchange.Exchange("KRAKEN")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, error_code.ExchangeNotSupported.Name(), err.(custom_error.BaseErrorAdapter).Code())
+	assert.Equal(t, "Exchange KRAKEN is not registered", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, lockPersistence.LockCounter)
+	assert.Equal(t, 0, lockPersistence.UnlockCounter)
+	assert.Equal(t, 0, clientPersistence.LockCounter)
+	assert.Equal(t, 0, clientPersistence.UnlockCounter)
+	assert.Equal(t, 0, circuitBreaker.CheckCounter)
+	assert.Equal(t, 0, clientService.GetBalanceCounter)
+	assert.Equal(t, 0, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateContextCancelledFailure(t *testing.T) {
+	setup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := validationUseCase.Validate(ctx, operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "GetBalance context error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, context.Canceled.Error(), err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, lockPersistence.LockCounter)
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientPersistence.LockCounter)
+	assert.Equal(t, 1, clientPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 0, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateLockClientFailure(t *testing.T) {
+	setup()
+
+	clientPersistence.LockError = errors.New("lock client error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Lock error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error while using DynamoDB Client table", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "lock client error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
 	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
 	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 1, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, clientPersistence.LockCounter)
+	assert.Equal(t, 0, clientPersistence.UnlockCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
+	assert.Equal(t, 0, clientService.GetBalanceCounter)
+	assert.Equal(t, 0, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestValidateGetClientFailure(t *testing.T) {
+	setup()
+
+	clientPersistence.GetClientError = errors.New("get client error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "GetClient error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, "Error while using DynamoDB Client table", err.(custom_error.BaseErrorAdapter).Description())
+	assert.Equal(t, "get client error", err.(custom_error.BaseErrorAdapter).Error())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 0, lockPersistence.LockCounter)
+	assert.Equal(t, 0, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.GetClientCounter)
 	assert.Equal(t, 0, clientPersistence.LockCounter)
 	assert.Equal(t, 0, clientPersistence.UnlockCounter)
 	assert.Equal(t, 0, clientService.GetBalanceCounter)
 	assert.Equal(t, 0, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 0, operationPersistence.SaveCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
@@ -518,7 +1709,7 @@ func TestValidateLockFailure(t *testing.T) {
 
 	lockPersistence.LockError = errors.New("lock error")
 
-	err := validationUseCase.Validate(operationRequest)
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
 	assert.NotNil(t, err, "Error should not be nil")
 	assert.Equal(t, "Lock error", err.(custom_error.BaseErrorAdapter).InternalError())
@@ -530,12 +1721,12 @@ func TestValidateLockFailure(t *testing.T) {
 	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
 	assert.Equal(t, 1, lockPersistence.LockCounter)
 	assert.Equal(t, 0, lockPersistence.UnlockCounter)
-	assert.Equal(t, 0, clientPersistence.GetClientCounter)
+	assert.Equal(t, 1, clientPersistence.GetClientCounter)
 	assert.Equal(t, 0, clientPersistence.LockCounter)
 	assert.Equal(t, 0, clientPersistence.UnlockCounter)
 	assert.Equal(t, 0, clientService.GetBalanceCounter)
 	assert.Equal(t, 0, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 0, operationPersistence.SaveCounter)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
@@ -546,11 +1737,16 @@ func TestValidateUnlockFailure(t *testing.T) {
 
 	lockPersistence.UnlockError = errors.New("unlock error")
 
-	panicFunction := func() {
-		_ = validationUseCase.Validate(operationRequest)
-	}
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
-	assert.Panicsf(t, panicFunction, "Should panic")
+	var unlockError *exceptions.UnlockError
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.True(t, errors.As(err, &unlockError), "Error should be an UnlockError")
+	assert.Equal(t, client.Id, unlockError.ClientId)
+	assert.Equal(t, []lock_type.LockType{lock_type.ClientId}, unlockError.Locks)
+	assert.Equal(t, true, unlockError.Holds(lock_type.ClientId))
+	assert.Equal(t, false, unlockError.Holds(lock_type.Client))
+	assert.Contains(t, unlockError.Error(), "unlock error")
 	assert.Equal(t, true, lockPersistence.IsLocked(client.Id))
 	assert.Equal(t, false, client.Locked)
 	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
@@ -564,13 +1760,16 @@ func TestValidateUnlockFailure(t *testing.T) {
 	assert.Equal(t, 2, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 1, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 1, operationPersistence.SaveCounter)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, 1, len(outboxEntries(outbox_type.Unlock)))
+	assert.Equal(t, client.Id, outboxEntries(outbox_type.Unlock)[0].ClientId)
+	assert.Equal(t, []lock_type.LockType{lock_type.ClientId}, outboxEntries(outbox_type.Unlock)[0].Locks)
 	assert.Equal(t, 1, logger.InfoCallCounter)
-	assert.Equal(t, 1, logger.ErrorCallCounter)
+	assert.Equal(t, 2, logger.ErrorCallCounter)
 }
 
 func TestValidateClientUnlockFailure(t *testing.T) {
@@ -578,12 +1777,13 @@ func TestValidateClientUnlockFailure(t *testing.T) {
 
 	clientPersistence.UnlockError = errors.New("unlock error")
 
-	panicFunction := func() {
-		_ = validationUseCase.Validate(operationRequest)
-	}
+	err := validationUseCase.Validate(context.Background(), operationRequest)
 
-	assert.Panicsf(t, panicFunction, "Should panic")
-	assert.Equal(t, true, lockPersistence.IsLocked(client.Id))
+	var unlockError *exceptions.UnlockError
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.True(t, errors.As(err, &unlockError), "Error should be an UnlockError")
+	assert.Equal(t, []lock_type.LockType{lock_type.Client}, unlockError.Locks)
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
 	assert.Equal(t, true, client.Locked)
 	assert.Equal(t, true, client.LockedUntil.Before(time.Now()))
 	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
@@ -593,14 +1793,174 @@ func TestValidateClientUnlockFailure(t *testing.T) {
 	assert.Equal(t, client.OperationStopLoss, operationPersistence.GetAllOperations()[0].StopLoss)
 	assert.Equal(t, client.CashAvailable*client.OperationAmountPercentage/100, operationPersistence.GetAllOperations()[0].Amount)
 	assert.Equal(t, 1, lockPersistence.LockCounter)
-	assert.Equal(t, 0, lockPersistence.UnlockCounter)
+	assert.Equal(t, 1, lockPersistence.UnlockCounter)
 	assert.Equal(t, 1, clientPersistence.LockCounter)
 	assert.Equal(t, 2, clientPersistence.UnlockCounter)
-	assert.Equal(t, 1, clientPersistence.GetClientCounter)
+	assert.Equal(t, 2, clientPersistence.GetClientCounter)
 	assert.Equal(t, 1, clientService.GetBalanceCounter)
 	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
-	assert.Equal(t, 1, operationPersistence.SaveCounter)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
 	assert.Equal(t, 1, eventService.SendCounter)
+	assert.Equal(t, 1, len(outboxEntries(outbox_type.Unlock)))
+	assert.Equal(t, []lock_type.LockType{lock_type.Client}, outboxEntries(outbox_type.Unlock)[0].Locks)
 	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 2, logger.ErrorCallCounter)
+}
+
+func TestValidateClientAndClientIdUnlockFailure(t *testing.T) {
+	setup()
+
+	clientPersistence.UnlockError = errors.New("unlock error")
+	lockPersistence.UnlockError = errors.New("lock unlock error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	var unlockError *exceptions.UnlockError
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.True(t, errors.As(err, &unlockError), "Error should be an UnlockError")
+	assert.Equal(t, []lock_type.LockType{lock_type.Client, lock_type.ClientId}, unlockError.Locks)
+	assert.Contains(t, unlockError.Error(), "unlock error")
+	assert.Contains(t, unlockError.Error(), "lock unlock error")
+	assert.Equal(t, true, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, true, client.Locked)
+	assert.Equal(t, 1, len(outboxEntries(outbox_type.Unlock)))
+	assert.Equal(t, []lock_type.LockType{lock_type.Client, lock_type.ClientId}, outboxEntries(outbox_type.Unlock)[0].Locks)
+}
+
+func TestValidateUnlockFailureKeepsValidationError(t *testing.T) {
+	setup()
+
+	client.Active = false
+	lockPersistence.UnlockError = errors.New("unlock error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	var validationError custom_error.BaseErrorAdapter
+	var unlockError *exceptions.UnlockError
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.True(t, errors.As(err, &validationError), "Error should keep the validation error")
+	assert.Equal(t, error_code.ClientInactive.Name(), validationError.Code())
+	assert.True(t, errors.As(err, &unlockError), "Error should be an UnlockError")
+	assert.Equal(t, []lock_type.LockType{lock_type.ClientId}, unlockError.Locks)
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, clientPersistence.SaveReservationCounter)
+}
+
+func TestValidateUnlockFailureOutboxFailure(t *testing.T) {
+	setup()
+
+	clientPersistence.UnlockError = errors.New("unlock error")
+	outboxPersistence.SaveError = errors.New("outbox error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	var unlockError *exceptions.UnlockError
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.True(t, errors.As(err, &unlockError), "Error should be an UnlockError")
+	assert.Equal(t, 1, outboxPersistence.SaveCounter)
+	assert.Equal(t, 0, len(outboxEntries(outbox_type.Unlock)))
+	assert.Equal(t, 3, logger.ErrorCallCounter)
+}
+
+func TestValidateReplayedRequestSuccess(t *testing.T) {
+	setup()
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+	assert.Nil(t, err)
+
+	cashReserved := client.CashReserved
+	cashAmount := client.CashAmount
+
+	err = validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()), "replay should not create a new operation")
+	assert.Equal(t, operationRequest.OperationId(), operationPersistence.GetAllOperations()[0].Id)
+	assert.Equal(t, cashReserved, client.CashReserved, "replay should not reserve cash again")
+	assert.Equal(t, cashAmount, client.CashAmount, "replay should not reserve cash again")
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 2, operationPersistence.GetCounter)
+	assert.Equal(t, 1, clientPersistence.SaveReservationCounter)
+	assert.Equal(t, 1, clientService.GetBalanceCounter)
+	assert.Equal(t, 1, cryptoService.GetCryptoCounter)
+	assert.Equal(t, 2, eventService.SendCounter, "replay should republish the operation event")
+	assert.Equal(t, 2, clientPersistence.UnlockCounter)
+	assert.Equal(t, 2, lockPersistence.UnlockCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestValidateReplayedRequestOperationNotCreatedSuccess(t *testing.T) {
+	setup()
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+	assert.Nil(t, err)
+
+	operationPersistence.GetAllOperations()[0].Status = status.Status("EXECUTED")
+
+	err = validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, eventService.SendCounter, "operation already past CREATED should not be republished")
+	assert.Equal(t, 2, clientPersistence.UnlockCounter)
+	assert.Equal(t, 2, lockPersistence.UnlockCounter)
+}
+
+func TestValidateDifferentRequestsCreateDifferentOperationsSuccess(t *testing.T) {
+	setup()
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+	assert.Nil(t, err)
+
+	operationRequest.StartTime = operationRequest.StartTime.Add(time.Minute)
+
+	err = validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(operationPersistence.GetAllOperations()))
+	assert.NotEqual(t, operationPersistence.GetAllOperations()[0].Id, operationPersistence.GetAllOperations()[1].Id)
+	assert.Equal(t, 2, eventService.SendCounter)
+}
+
+func TestOperationIdWithoutStartTimeUsesMessageIdSuccess(t *testing.T) {
+	setup()
+
+	operationRequest.StartTime = time.Time{}
+	operationRequest.MessageId = uuid.NewString()
+	operationId := operationRequest.OperationId()
+
+	assert.Equal(t, operationId, operationRequest.OperationId(), "redelivered message should keep the operation id")
+
+	operationRequest.MessageId = uuid.NewString()
+
+	assert.NotEqual(t, operationId, operationRequest.OperationId(), "different messages should not be taken as replays")
+}
+
+func TestValidateGetOperationFailure(t *testing.T) {
+	setup()
+
+	operationPersistence.GetError = errors.New("get error")
+
+	err := validationUseCase.Validate(context.Background(), operationRequest)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "get error", err.(custom_error.BaseErrorAdapter).InternalError())
+	assert.Equal(t, false, lockPersistence.IsLocked(client.Id))
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 0, len(operationPersistence.GetAllOperations()))
+	assert.Equal(t, 1, operationPersistence.GetCounter)
+	assert.Equal(t, 0, clientService.GetBalanceCounter)
+	assert.Equal(t, 0, eventService.SendCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
+
+func outboxEntries(outboxType outbox_type.OutboxType) []*model.OutboxEntry {
+	var entries []*model.OutboxEntry
+	for _, entry := range outboxPersistence.GetAllEntries() {
+		if entry.Type == outboxType {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -1,6 +1,7 @@
 package aws_test
 
 import (
+	"context"
 	"errors"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/aws"
@@ -40,7 +41,7 @@ func TestGetSecretFromStringSuccess(t *testing.T) {
 	setup()
 
 	secret := &dto.RedisSecrets{}
-	err := secretsManagerService.GetSecret("secretName", secret)
+	err := secretsManagerService.GetSecret(context.Background(), "secretName", secret)
 
 	assert.Nil(t, err)
 	assert.Equal(t, &secrets, secret)
@@ -55,7 +56,7 @@ func TestGetSecretFromStringFailure(t *testing.T) {
 	secretsManager.SetSecret("secretName", "")
 
 	secret := &dto.RedisSecrets{}
-	err := secretsManagerService.GetSecret("secretName", secret)
+	err := secretsManagerService.GetSecret(context.Background(), "secretName", secret)
 
 	assert.Equal(t, "json: cannot unmarshal string into Go value of type dto.RedisSecrets", err.Error())
 	assert.Equal(t, "error while unmarshalling secret string", err.InternalError())
@@ -71,7 +72,7 @@ func TestGetSecretFromBinarySuccess(t *testing.T) {
 	secretsManager.ReturnEmptyString = true
 
 	secret := &dto.RedisSecrets{}
-	err := secretsManagerService.GetSecret("secretName", secret)
+	err := secretsManagerService.GetSecret(context.Background(), "secretName", secret)
 
 	assert.Nil(t, err)
 	assert.Equal(t, &secrets, secret)
@@ -87,7 +88,7 @@ func TestGetSecretFromBinaryUnmarshalFailure(t *testing.T) {
 	secretsManager.ReturnEmptyBinary = true
 
 	secret := &dto.RedisSecrets{}
-	err := secretsManagerService.GetSecret("secretName", secret)
+	err := secretsManagerService.GetSecret(context.Background(), "secretName", secret)
 
 	assert.Equal(t, "unexpected end of JSON input", err.Error())
 	assert.Equal(t, "error while unmarshalling secret binary", err.InternalError())
@@ -103,7 +104,7 @@ func TestGetSecretSecretsManagerFailure(t *testing.T) {
 	secretsManager.GetSecretValueError = errors.New("error test")
 
 	secret := &dto.RedisSecrets{}
-	err := secretsManagerService.GetSecret("secretName", secret)
+	err := secretsManagerService.GetSecret(context.Background(), "secretName", secret)
 
 	assert.Equal(t, "error test", err.Error())
 	assert.Equal(t, "error while getting secret", err.InternalError())
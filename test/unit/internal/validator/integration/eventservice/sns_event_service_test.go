@@ -85,7 +85,7 @@ func setup() {
 func TestSendSuccess(t *testing.T) {
 	setup()
 
-	err := snsEventService.Send(payload)
+	err := snsEventService.Send(context.Background(), payload)
 
 	assert.Nil(t, err)
 	assert.Equal(t, 1, snsPublishCounter)
@@ -100,7 +100,7 @@ func TestSendPublishFailure(t *testing.T) {
 
 	snsPublishError = errors.New("test error")
 
-	err := snsEventService.Send(payload)
+	err := snsEventService.Send(context.Background(), payload)
 
 	assert.Equal(t, "test error", err.Error())
 	assert.Equal(t, "Error while trying to publish", err.InternalError())
@@ -117,7 +117,7 @@ func TestSendMarshalFailure(t *testing.T) {
 
 	payload = make(chan int)
 
-	err := snsEventService.Send(payload)
+	err := snsEventService.Send(context.Background(), payload)
 
 	assert.Equal(t, "json: unsupported type: chan int", err.Error())
 	assert.Equal(t, "Error while trying create string message", err.InternalError())
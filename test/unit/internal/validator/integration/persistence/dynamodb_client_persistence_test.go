@@ -1,17 +1,31 @@
 package persistence
 
 import (
+	"context"
 	"errors"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/analysis_strength"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/exchange"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/operation_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/persistence"
+	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
 	"github.com/brienze1/crypto-robot-validator/test/mocks"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 var (
@@ -21,6 +35,7 @@ var (
 )
 
 var (
+	owner           string
 	clientPersisted *dto.Client
 	clientUnlocked  *model.Client
 	clientLocked    *model.Client
@@ -29,7 +44,8 @@ var (
 func clientPersistenceSetup() {
 	config.LoadTestEnv()
 
-	clientPersistence = persistence.DynamoDBClientPersistence(logger, dynamoDBClient)
+	owner = uuid.NewString()
+	clientPersistence = persistence.DynamoDBClientPersistence(logger, dynamoDBClient, owner)
 
 	logger.Reset()
 	dynamoDBClient.Reset()
@@ -39,12 +55,14 @@ func clientPersistenceSetup() {
 	clientLocked = &model.Client{Id: uuid.NewString(), Locked: true}
 
 	dynamoDBClient.AddItem(clientPersisted.Id, clientPersisted, properties.Properties().Aws.DynamoDB.ClientTableName)
+	dynamoDBClient.AddItem(clientUnlocked.Id, dto.ClientDto(clientUnlocked), properties.Properties().Aws.DynamoDB.ClientTableName)
+	dynamoDBClient.AddItem(clientLocked.Id, dto.ClientDto(clientLocked), properties.Properties().Aws.DynamoDB.ClientTableName)
 }
 
 func TestGetClientsSuccess(t *testing.T) {
 	clientPersistenceSetup()
 
-	client, err := clientPersistence.GetClient(clientPersisted.Id)
+	client, err := clientPersistence.GetClient(context.Background(), clientPersisted.Id)
 
 	assert.Nilf(t, err, "Should be nil")
 	assert.NotNilf(t, client, "Should not be nil")
@@ -57,7 +75,7 @@ func TestGetClientsSuccess(t *testing.T) {
 func TestGetClientsClientNotFoundFailure(t *testing.T) {
 	clientPersistenceSetup()
 
-	client, err := clientPersistence.GetClient(uuid.NewString())
+	client, err := clientPersistence.GetClient(context.Background(), uuid.NewString())
 
 	assert.Equal(t, "Client not found.", err.Error())
 	assert.Equal(t, "Client not found.", err.InternalError())
@@ -73,7 +91,7 @@ func TestGetClientsDynamoDBClientFailure(t *testing.T) {
 
 	dynamoDBClient.GetItemError = errors.New("dynamodb client error")
 
-	client, err := clientPersistence.GetClient(uuid.NewString())
+	client, err := clientPersistence.GetClient(context.Background(), uuid.NewString())
 
 	assert.Equal(t, "dynamodb client error", err.Error())
 	assert.Equal(t, "Error while trying to get client.", err.InternalError())
@@ -94,7 +112,7 @@ func TestGetClientsUnmarshalFailure(t *testing.T) {
 
 	dynamoDBClient.AddItem(clientId, fakeClient, properties.Properties().Aws.DynamoDB.ClientTableName)
 
-	client, err := clientPersistence.GetClient(clientId)
+	client, err := clientPersistence.GetClient(context.Background(), clientId)
 
 	assert.Equal(t, "unmarshal failed, cannot unmarshal string into Go value type bool", err.Error())
 	assert.Equal(t, "Error while trying to unmarshal get client response.", err.InternalError())
@@ -110,90 +128,413 @@ func TestGetClientsClientLockedFailure(t *testing.T) {
 
 	dynamoDBClient.AddItem(clientLocked.Id, clientLocked, properties.Properties().Aws.DynamoDB.ClientTableName)
 
-	client, err := clientPersistence.GetClient(clientLocked.Id)
+	client, err := clientPersistence.GetClient(context.Background(), clientLocked.Id)
 
 	assert.Equal(t, "Client is locked.", err.Error())
 	assert.Equal(t, "Client is locked.", err.InternalError())
 	assert.Equal(t, "Error while using DynamoDB Client table", err.Description())
+	assert.Equal(t, error_code.ClientLocked.Name(), err.Code())
 	assert.Nilf(t, client, "Should be nil")
 	assert.Equal(t, 1, dynamoDBClient.GetItemCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
+func TestGetClientsClientLockedUntilFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	clientPersisted.LockedUntil = time_utils.Time().Tomorrow().Format(time.RFC3339Nano)
+
+	client, err := clientPersistence.GetClient(context.Background(), clientPersisted.Id)
+
+	assert.Equal(t, "Client is locked until locked_until date.", err.Error())
+	assert.Equal(t, "Client is locked until locked_until date.", err.InternalError())
+	assert.Equal(t, "Error while using DynamoDB Client table", err.Description())
+	assert.Equal(t, error_code.ClientLockedUntil.Name(), err.Code())
+	assert.Nilf(t, client, "Should be nil")
+	assert.Equal(t, 1, dynamoDBClient.GetItemCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestGetClientsClientLockedUntilExpiredSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	clientPersisted.LockedUntil = time.Now().Add(-time.Hour).Format(time.RFC3339Nano)
+
+	client, err := clientPersistence.GetClient(context.Background(), clientPersisted.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.NotNilf(t, client, "Should not be nil")
+	assert.Equal(t, false, client.IsLockedUntil())
+	assert.Equal(t, 1, dynamoDBClient.GetItemCounter)
+	assert.Equal(t, 2, logger.InfoCallCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestGetClientsCryptoBalancesSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	clientPersisted.Crypto = map[string]*dto.CryptoBalance{
+		"BTC": {Available: 1, Amount: 0.5, Reserved: 0.1},
+		"ETH": {Available: 10, Amount: 5, Reserved: 1},
+	}
+
+	client, err := clientPersistence.GetClient(context.Background(), clientPersisted.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 2, len(client.Crypto))
+	assert.Equal(t, &model.CryptoBalance{Available: 1, Amount: 0.5, Reserved: 0.1}, client.Crypto[symbol.Bitcoin])
+	assert.Equal(t, &model.CryptoBalance{Available: 10, Amount: 5, Reserved: 1}, client.Crypto[symbol.Ethereum])
+}
+
+func TestGetClientsLegacyCryptoBalanceSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	clientPersisted.CryptoAvailable = 1
+	clientPersisted.CryptoAmount = 0.5
+	clientPersisted.CryptoReserved = 0.1
+
+	client, err := clientPersistence.GetClient(context.Background(), clientPersisted.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 1, len(client.Crypto))
+	assert.Equal(t, &model.CryptoBalance{Available: 1, Amount: 0.5, Reserved: 0.1}, client.Crypto[symbol.Bitcoin])
+}
+
+func TestGetClientsLegacyAnalysisStrengthSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	item, _ := attributevalue.MarshalMap(clientPersisted)
+	item["buy_on"] = &types.AttributeValueMemberN{Value: "1"}
+	item["sell_on"] = &types.AttributeValueMemberN{Value: "2"}
+	dynamoDBClient.AddItem(clientPersisted.Id, item, properties.Properties().Aws.DynamoDB.ClientTableName)
+
+	client, err := clientPersistence.GetClient(context.Background(), clientPersisted.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, analysis_strength.Buy, client.BuyOn)
+	assert.Equal(t, analysis_strength.StrongSell, client.SellOn)
+}
+
+func TestGetClientsExchangeSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	clientPersisted.Exchange = exchange.Binance.Name()
+
+	client, err := clientPersistence.GetClient(context.Background(), clientPersisted.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, exchange.Binance, client.Exchange)
+}
+
+func TestGetClientsLegacyExchangeSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, err := clientPersistence.GetClient(context.Background(), clientPersisted.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, exchange.Biscoint, client.Exchange)
+}
+
+func TestGetClientsSimulationSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	clientPersisted.Simulation = true
+
+	client, err := clientPersistence.GetClient(context.Background(), clientPersisted.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, true, client.Simulation)
+}
+
+func TestLockUntilSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	client := clientPersisted.ToModel()
+	client.LockedUntil = time_utils.Time().Tomorrow()
+
+	err := clientPersistence.LockUntil(context.Background(), client)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 1, dynamoDBClient.UpdateItemCounter)
+	assert.Equal(t, 0, dynamoDBClient.PutItemCounter)
+	assert.Equal(t, 2, logger.InfoCallCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+
+	_, err = clientPersistence.GetClient(context.Background(), client.Id)
+
+	assert.Equal(t, "Client is locked until locked_until date.", err.InternalError())
+	assert.Equal(t, error_code.ClientLockedUntil.Name(), err.Code())
+}
+
+func TestLockUntilUpdateItemFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	dynamoDBClient.UpdateItemError = errors.New("lock until error")
+
+	err := clientPersistence.LockUntil(context.Background(), clientUnlocked)
+
+	assert.Equal(t, "lock until error", err.Error())
+	assert.Equal(t, "UpdateItem error", err.InternalError())
+	assert.Equal(t, "Error while using DynamoDB Client table", err.Description())
+	assert.Equal(t, 1, dynamoDBClient.UpdateItemCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
 func TestLockSuccess(t *testing.T) {
 	clientPersistenceSetup()
 
 	assert.Equal(t, false, clientUnlocked.Locked)
 
-	err := clientPersistence.Lock(clientUnlocked)
+	err := clientPersistence.Lock(context.Background(), clientUnlocked)
 
 	assert.Nilf(t, err, "Should be nil")
 	assert.Equal(t, true, clientUnlocked.Locked)
-	assert.Equal(t, 1, dynamoDBClient.PutItemCounter)
+	assert.Equal(t, 1, dynamoDBClient.UpdateItemCounter)
 	assert.Equal(t, 2, logger.InfoCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
 
-	_, err = clientPersistence.GetClient(clientUnlocked.Id)
+	_, err = clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
 
 	assert.Equal(t, "Client is locked.", err.Error())
 	assert.Equal(t, "Client is locked.", err.InternalError())
 	assert.Equal(t, "Error while using DynamoDB Client table", err.Description())
 }
 
-func TestLockPutItemFailure(t *testing.T) {
+func TestLockUpdateItemFailure(t *testing.T) {
 	clientPersistenceSetup()
 
-	dynamoDBClient.PutItemError = errors.New("lock error")
+	dynamoDBClient.UpdateItemError = errors.New("lock error")
 
-	err := clientPersistence.Lock(clientUnlocked)
+	err := clientPersistence.Lock(context.Background(), clientUnlocked)
 
 	assert.Equal(t, "lock error", err.Error())
-	assert.Equal(t, "PutItem error", err.InternalError())
+	assert.Equal(t, "UpdateItem error", err.InternalError())
 	assert.Equal(t, "Error while using DynamoDB Client table", err.Description())
-	assert.Equal(t, true, clientUnlocked.Locked)
-	assert.Equal(t, 1, dynamoDBClient.PutItemCounter)
+	assert.Equal(t, false, clientUnlocked.Locked)
+	assert.Equal(t, 0, clientUnlocked.Version)
+	assert.Equal(t, 1, dynamoDBClient.UpdateItemCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
-	assert.Equal(t, 2, logger.ErrorCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
 func TestUnlockSuccess(t *testing.T) {
 	clientPersistenceSetup()
 
-	assert.Equal(t, true, clientLocked.Locked)
+	_ = clientPersistence.Lock(context.Background(), clientUnlocked)
+	assert.Equal(t, true, clientUnlocked.Locked)
 
-	err := clientPersistence.Unlock(clientLocked)
+	err := clientPersistence.Unlock(context.Background(), clientUnlocked)
 
 	assert.Nilf(t, err, "Should be nil")
-	assert.Equal(t, false, clientLocked.Locked)
-	assert.Equal(t, 1, dynamoDBClient.PutItemCounter)
-	assert.Equal(t, 2, logger.InfoCallCounter)
+	assert.Equal(t, false, clientUnlocked.Locked)
+	assert.Equal(t, "", clientUnlocked.LockedBy)
+	assert.True(t, clientUnlocked.LockExpiresAt.IsZero(), "Lease should be cleared")
+	assert.Equal(t, 2, dynamoDBClient.UpdateItemCounter)
+	assert.Equal(t, 4, logger.InfoCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
 
-	clientUpdated, err := clientPersistence.GetClient(clientLocked.Id)
+	output := storedClient(clientUnlocked.Id)
+
+	assert.Equal(t, &types.AttributeValueMemberBOOL{Value: false}, output["locked"])
+	assert.NotContains(t, output, "locked_by")
+	assert.NotContains(t, output, "lock_expires_at")
+
+	clientUpdated, err := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
 
 	assert.Nilf(t, err, "Should be nil")
 	assert.NotNilf(t, clientUpdated, "Should not be nil")
-	assert.Equal(t, clientLocked.Id, clientUpdated.Id)
-	assert.Equal(t, 1, dynamoDBClient.GetItemCounter)
-	assert.Equal(t, 4, logger.InfoCallCounter)
+	assert.Equal(t, clientUnlocked.Id, clientUpdated.Id)
+	assert.Equal(t, 6, logger.InfoCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
 }
 
-func TestUnlockPutItemFailure(t *testing.T) {
+func TestUnlockNotOwnerFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	err := clientPersistence.Unlock(context.Background(), clientLocked)
+
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, "Client lock is not owned by this process or client was modified by another process.", err.InternalError())
+	assert.Equal(t, error_code.ClientModified.Name(), err.Code())
+	assert.Equal(t, true, err.LockedClientId())
+	assert.Equal(t, true, err.LockedClient())
+	assert.Equal(t, true, clientLocked.Locked)
+	assert.Equal(t, &types.AttributeValueMemberBOOL{Value: true}, storedClient(clientLocked.Id)["locked"])
+}
+
+func TestLockWritesLeaseSuccess(t *testing.T) {
 	clientPersistenceSetup()
 
-	dynamoDBClient.PutItemError = errors.New("unlock error")
+	before := time.Now()
+	err := clientPersistence.Lock(context.Background(), clientUnlocked)
 
-	err := clientPersistence.Unlock(clientLocked)
+	output := storedClient(clientUnlocked.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, owner, clientUnlocked.LockedBy)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: owner}, output["locked_by"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: clientUnlocked.LockExpiresAt.Format(time.RFC3339Nano)}, output["lock_expires_at"])
+	assert.False(t, clientUnlocked.LockExpiresAt.Before(before.Add(properties.Properties().ClientLockLease)), "Lease should last CLIENT_LOCK_LEASE_SECONDS")
+}
+
+func TestLockLeaseNotExpiredFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	client := &model.Client{Id: clientLocked.Id, Locked: true, LockExpiresAt: time.Now().Add(time.Minute)}
+
+	err := clientPersistence.Lock(context.Background(), client)
+
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, "Client is locked.", err.InternalError())
+	assert.Equal(t, error_code.ClientLocked.Name(), err.Code())
+	assert.Equal(t, 0, dynamoDBClient.UpdateItemCounter)
+}
+
+func TestGetClientLeaseExpiredSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	clientExpired := &model.Client{Id: uuid.NewString(), Locked: true, LockedBy: uuid.NewString(), LockExpiresAt: time.Now().Add(-time.Second)}
+	dynamoDBClient.AddItem(clientExpired.Id, dto.ClientDto(clientExpired), properties.Properties().Aws.DynamoDB.ClientTableName)
+
+	client, err := clientPersistence.GetClient(context.Background(), clientExpired.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, clientExpired.Id, client.Id)
+	assert.Equal(t, false, client.IsLocked())
+}
+
+func TestLockLeaseExpiredTakenOverSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	lease := properties.Properties().ClientLockLease
+	properties.Properties().ClientLockLease = -time.Second
+	firstOwner := persistence.DynamoDBClientPersistence(logger, dynamoDBClient, uuid.NewString())
+	properties.Properties().ClientLockLease = lease
+	secondOwner := persistence.DynamoDBClientPersistence(logger, dynamoDBClient, uuid.NewString())
+
+	firstRead, _ := firstOwner.GetClient(context.Background(), clientUnlocked.Id)
+	firstLockErr := firstOwner.Lock(context.Background(), firstRead)
+
+	secondRead, getErr := secondOwner.GetClient(context.Background(), clientUnlocked.Id)
+	secondLockErr := secondOwner.Lock(context.Background(), secondRead)
+
+	firstUnlockErr := firstOwner.Unlock(context.Background(), firstRead)
+
+	assert.Nilf(t, firstLockErr, "Should be nil")
+	assert.Nilf(t, getErr, "Expired lease should be free")
+	assert.Nilf(t, secondLockErr, "Should be nil")
+	assert.NotEqual(t, firstRead.LockedBy, secondRead.LockedBy)
+	assert.NotNil(t, firstUnlockErr, "Previous owner should not unlock")
+	assert.Equal(t, error_code.ClientModified.Name(), firstUnlockErr.Code())
+	assert.Equal(t, &types.AttributeValueMemberS{Value: secondRead.LockedBy}, storedClient(clientUnlocked.Id)["locked_by"])
+}
+
+func TestReleaseLockSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	err := clientPersistence.ReleaseLock(context.Background(), clientLocked)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, false, clientLocked.Locked)
+	assert.Equal(t, &types.AttributeValueMemberBOOL{Value: false}, storedClient(clientLocked.Id)["locked"])
+	assert.Equal(t, 2, logger.InfoCallCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestReleaseLockVersionConflictFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	client := &model.Client{Id: clientLocked.Id, Locked: true, Version: 5}
+
+	err := clientPersistence.ReleaseLock(context.Background(), client)
+
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, "Client was modified by another process.", err.InternalError())
+	assert.Equal(t, error_code.ClientModified.Name(), err.Code())
+	assert.Equal(t, true, client.Locked)
+}
+
+func TestUnlockUpdateItemFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	dynamoDBClient.UpdateItemError = errors.New("unlock error")
+
+	err := clientPersistence.Unlock(context.Background(), clientLocked)
 
 	assert.Equal(t, "unlock error", err.Error())
-	assert.Equal(t, "PutItem error", err.InternalError())
+	assert.Equal(t, "UpdateItem error", err.InternalError())
 	assert.Equal(t, "Error while using DynamoDB Client table", err.Description())
-	assert.Equal(t, false, clientLocked.Locked)
-	assert.Equal(t, 1, dynamoDBClient.PutItemCounter)
+	assert.Equal(t, true, err.LockedClientId())
+	assert.Equal(t, true, err.LockedClient())
+	assert.Equal(t, true, clientLocked.Locked)
+	assert.Equal(t, 1, dynamoDBClient.UpdateItemCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
-	assert.Equal(t, 2, logger.ErrorCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestGetLockedClientsSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	clients, err := clientPersistence.GetLockedClients(context.Background())
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 1, len(clients))
+	assert.Equal(t, clientLocked.Id, clients[0].Id)
+	assert.Equal(t, true, clients[0].Locked)
+	assert.Equal(t, 1, dynamoDBClient.ScanCounter)
+	assert.Equal(t, 2, logger.InfoCallCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestGetLockedClientsLockedAtSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	before := time.Now()
+	err := clientPersistence.Lock(context.Background(), clientUnlocked)
+	assert.Nilf(t, err, "Should be nil")
+	assert.False(t, clientUnlocked.LockedAt.Before(before), "Lock should set locked_at")
+
+	clients, err := clientPersistence.GetLockedClients(context.Background())
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 2, len(clients))
+	for _, client := range clients {
+		if client.Id == clientUnlocked.Id {
+			assert.True(t, clientUnlocked.LockedAt.Equal(client.LockedAt), "locked_at should be persisted")
+		} else {
+			assert.True(t, client.LockedAt.IsZero(), "Clients locked without locked_at should have zero value")
+		}
+	}
+}
+
+func TestGetLockedClientsReleaseLockDoesNotWriteLockedAtSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	err := clientPersistence.ReleaseLock(context.Background(), clientLocked)
+	assert.Nilf(t, err, "Should be nil")
+
+	clients, err := clientPersistence.GetLockedClients(context.Background())
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Empty(t, clients)
+	assert.True(t, clientLocked.LockedAt.IsZero(), "ReleaseLock should not set locked_at")
+}
+
+func TestGetLockedClientsScanFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	dynamoDBClient.ScanError = errors.New("scan error")
+
+	clients, err := clientPersistence.GetLockedClients(context.Background())
+
+	assert.Nil(t, clients)
+	assert.Equal(t, "scan error", err.Error())
+	assert.Equal(t, "Error while trying to scan locked clients.", err.InternalError())
+	assert.Equal(t, "Error while using DynamoDB Client table", err.Description())
+	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
 func TestLockAndUnlockSuccess(t *testing.T) {
@@ -201,29 +542,29 @@ func TestLockAndUnlockSuccess(t *testing.T) {
 
 	assert.Equal(t, false, clientUnlocked.Locked)
 
-	err := clientPersistence.Lock(clientUnlocked)
+	err := clientPersistence.Lock(context.Background(), clientUnlocked)
 
 	assert.Nilf(t, err, "Should be nil")
 	assert.Equal(t, true, clientUnlocked.Locked)
-	assert.Equal(t, 1, dynamoDBClient.PutItemCounter)
+	assert.Equal(t, 1, dynamoDBClient.UpdateItemCounter)
 	assert.Equal(t, 2, logger.InfoCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
 
-	_, err = clientPersistence.GetClient(clientUnlocked.Id)
+	_, err = clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
 
 	assert.Equal(t, "Client is locked.", err.Error())
 	assert.Equal(t, "Client is locked.", err.InternalError())
 	assert.Equal(t, "Error while using DynamoDB Client table", err.Description())
 
-	err = clientPersistence.Unlock(clientUnlocked)
+	err = clientPersistence.Unlock(context.Background(), clientUnlocked)
 
 	assert.Nilf(t, err, "Should be nil")
 	assert.Equal(t, false, clientUnlocked.Locked)
-	assert.Equal(t, 2, dynamoDBClient.PutItemCounter)
+	assert.Equal(t, 2, dynamoDBClient.UpdateItemCounter)
 	assert.Equal(t, 5, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 
-	clientUpdated, err := clientPersistence.GetClient(clientUnlocked.Id)
+	clientUpdated, err := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
 	assert.Nilf(t, err, "Should be nil")
 	assert.NotNilf(t, clientUpdated, "Should not be nil")
 	assert.Equal(t, clientUnlocked.Id, clientUpdated.Id)
@@ -231,3 +572,336 @@ func TestLockAndUnlockSuccess(t *testing.T) {
 	assert.Equal(t, 7, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
+
+func TestLockAlreadyLockedFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	client := &model.Client{Id: clientLocked.Id, Locked: false}
+
+	err := clientPersistence.Lock(context.Background(), client)
+
+	assert.Equal(t, "Client is locked or was modified by another process.", err.InternalError())
+	assert.Equal(t, error_code.ClientLocked.Name(), err.Code())
+	assert.Equal(t, false, err.LockedClient())
+	assert.Equal(t, false, client.Locked)
+	assert.Equal(t, 1, dynamoDBClient.UpdateItemCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestLockConcurrentValidatorsFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	firstRead, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	secondRead, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+
+	firstErr := clientPersistence.Lock(context.Background(), firstRead)
+	secondErr := clientPersistence.Lock(context.Background(), secondRead)
+
+	assert.Nilf(t, firstErr, "Should be nil")
+	assert.Equal(t, true, firstRead.Locked)
+	assert.Equal(t, 1, firstRead.Version)
+	assert.NotNilf(t, secondErr, "Should not be nil")
+	assert.Equal(t, error_code.ClientLocked.Name(), secondErr.Code())
+	assert.Equal(t, false, secondRead.Locked)
+}
+
+func TestUnlockVersionConflictFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	_ = clientPersistence.Lock(context.Background(), client)
+	client.Version = 5
+
+	err := clientPersistence.Unlock(context.Background(), client)
+
+	assert.Equal(t, "Client lock is not owned by this process or client was modified by another process.", err.InternalError())
+	assert.Equal(t, error_code.ClientModified.Name(), err.Code())
+	assert.Equal(t, true, client.Locked)
+	assert.Equal(t, 2, dynamoDBClient.UpdateItemCounter)
+}
+
+func TestLockAndUnlockKeepsUnknownAttributesSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	clientId := uuid.NewString()
+	dynamoDBClient.AddItem(clientId, map[string]interface{}{
+		"client_id":           clientId,
+		"locked":              false,
+		"cash_amount":         100.0,
+		"ops_timeout_seconds": 60,
+	}, properties.Properties().Aws.DynamoDB.ClientTableName)
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientId)
+	lockErr := clientPersistence.Lock(context.Background(), client)
+	client.CashReserved = 10
+	operation := model.NewOperation(uuid.NewString(), 50)
+	reservationErr := clientPersistence.SaveReservation(context.Background(), client, operation, operationEvent(client, operation))
+	unlockErr := clientPersistence.Unlock(context.Background(), client)
+
+	output := storedClient(clientId)
+
+	assert.Nilf(t, lockErr, "Should be nil")
+	assert.Nilf(t, reservationErr, "Should be nil")
+	assert.Nilf(t, unlockErr, "Should be nil")
+	assert.Equal(t, 3, client.Version)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "60"}, output["ops_timeout_seconds"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "10"}, output["cash_reserved"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "3"}, output["version"])
+	assert.Equal(t, &types.AttributeValueMemberBOOL{Value: false}, output["locked"])
+	assert.Equal(t, 0, dynamoDBClient.PutItemCounter)
+}
+
+func TestUnlockDoesNotWriteBalancesSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	_ = clientPersistence.Lock(context.Background(), client)
+	client.CashAmount = 90
+	client.CashReserved = 10
+
+	err := clientPersistence.Unlock(context.Background(), client)
+
+	output := storedClient(client.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "0"}, output["cash_amount"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "0"}, output["cash_reserved"])
+	assert.Equal(t, &types.AttributeValueMemberBOOL{Value: false}, output["locked"])
+}
+
+func TestSaveReservationSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	_ = clientPersistence.Lock(context.Background(), client)
+	client.CashAmount = 90
+	client.CashReserved = 10
+	operation := model.NewOperation(uuid.NewString(), 50)
+
+	err := clientPersistence.SaveReservation(context.Background(), client, operation, operationEvent(client, operation))
+
+	output := storedClient(client.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 2, client.Version)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "90"}, output["cash_amount"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "10"}, output["cash_reserved"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, output["version"])
+	assert.Equal(t, &types.AttributeValueMemberBOOL{Value: true}, output["locked"])
+	assert.Equal(t, 1, dynamoDBClient.NumberOfOperations())
+	assert.Equal(t, 1, dynamoDBClient.NumberOfOutboxEntries())
+	assert.Equal(t, 1, dynamoDBClient.TransactWriteItemsCounter)
+	assert.Equal(t, 0, dynamoDBClient.PutItemCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestSaveReservationClientNotLockedFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	client.CashReserved = 10
+
+	operation := model.NewOperation(uuid.NewString(), 50)
+
+	err := clientPersistence.SaveReservation(context.Background(), client, operation, operationEvent(client, operation))
+
+	output := storedClient(client.Id)
+
+	assert.NotNilf(t, err, "Should not be nil")
+	assert.Equal(t, "Client was modified by another process.", err.InternalError())
+	assert.Equal(t, error_code.ClientModified.Name(), err.Code())
+	assert.Equal(t, true, err.LockedClient())
+	assert.Equal(t, true, err.LockedClientId())
+	assert.Equal(t, 0, client.Version)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "0"}, output["cash_reserved"])
+	assert.Equal(t, 0, dynamoDBClient.NumberOfOperations())
+	assert.Equal(t, 0, dynamoDBClient.NumberOfOutboxEntries())
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func TestSaveReservationVersionConflictFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	_ = clientPersistence.Lock(context.Background(), client)
+	client.Version = 5
+
+	operation := model.NewOperation(uuid.NewString(), 50)
+
+	err := clientPersistence.SaveReservation(context.Background(), client, operation, operationEvent(client, operation))
+
+	assert.NotNilf(t, err, "Should not be nil")
+	assert.Equal(t, error_code.ClientModified.Name(), err.Code())
+	assert.Equal(t, 5, client.Version)
+	assert.Equal(t, 0, dynamoDBClient.NumberOfOperations())
+}
+
+func TestSaveReservationOperationExistsFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	_ = clientPersistence.Lock(context.Background(), client)
+	operation := model.NewOperation(uuid.NewString(), 50)
+	_ = clientPersistence.SaveReservation(context.Background(), client, operation, operationEvent(client, operation))
+	client.CashReserved = 20
+
+	err := clientPersistence.SaveReservation(context.Background(), client, operation, operationEvent(client, operation))
+
+	output := storedClient(client.Id)
+
+	assert.NotNilf(t, err, "Should not be nil")
+	assert.Equal(t, "Operation already exists.", err.InternalError())
+	assert.Equal(t, error_code.OperationExists.Name(), err.Code())
+	assert.Equal(t, true, err.LockedClient())
+	assert.Equal(t, 2, client.Version)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "0"}, output["cash_reserved"])
+	assert.Equal(t, 1, dynamoDBClient.NumberOfOperations())
+}
+
+func TestSaveReservationCancelledOperationSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	_ = clientPersistence.Lock(context.Background(), client)
+	operation := model.NewOperation(uuid.NewString(), 50)
+	_ = clientPersistence.SaveReservation(context.Background(), client, operation, operationEvent(client, operation))
+	operation.Cancel()
+	_ = clientPersistence.ReleaseReservation(context.Background(), client, operation)
+
+	recreated := model.NewOperation(operation.Id, 50)
+	err := clientPersistence.SaveReservation(context.Background(), client, recreated, operationEvent(client, recreated))
+
+	operationOutput, _ := dynamoDBClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		Key:       map[string]types.AttributeValue{"operation_id": &types.AttributeValueMemberS{Value: operation.Id}},
+		TableName: properties.Properties().Aws.DynamoDB.OperationTableName,
+	})
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 4, client.Version)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: string(status.Created)}, operationOutput.Item["status"])
+	assert.Equal(t, 1, dynamoDBClient.NumberOfOperations())
+	assert.Equal(t, 1, dynamoDBClient.NumberOfOutboxEntries())
+}
+
+func TestSaveReservationEventExistsFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	_ = clientPersistence.Lock(context.Background(), client)
+	operation := model.NewOperation(uuid.NewString(), 50)
+	event := operationEvent(client, operation)
+	event.Status = outbox_status.Sent
+	dynamoDBClient.AddItem(event.Id, dto.OutboxEntryDto(event), properties.Properties().Aws.DynamoDB.OutboxTableName)
+	event.Status = outbox_status.Pending
+	client.CashReserved = 20
+
+	err := clientPersistence.SaveReservation(context.Background(), client, operation, event)
+
+	output := storedClient(client.Id)
+
+	assert.NotNilf(t, err, "Should not be nil")
+	assert.Equal(t, error_code.OperationExists.Name(), err.Code())
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "0"}, output["cash_reserved"])
+	assert.Equal(t, 0, dynamoDBClient.NumberOfOperations())
+	assert.Equal(t, 1, dynamoDBClient.NumberOfOutboxEntries())
+}
+
+func TestSaveReservationTransactWriteItemsFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	dynamoDBClient.TransactWriteItemsError = errors.New("transact error")
+
+	operation := model.NewOperation(uuid.NewString(), 50)
+
+	err := clientPersistence.SaveReservation(context.Background(), clientLocked, operation, operationEvent(clientLocked, operation))
+
+	assert.NotNilf(t, err, "Should not be nil")
+	assert.Equal(t, "transact error", err.Error())
+	assert.Equal(t, "Error while trying to save client balances.", err.InternalError())
+	assert.Equal(t, "Error while using DynamoDB Client table", err.Description())
+	assert.Equal(t, true, err.LockedClient())
+	assert.Equal(t, 1, dynamoDBClient.TransactWriteItemsCounter)
+	assert.Equal(t, 1, logger.InfoCallCounter)
+	assert.Equal(t, 1, logger.ErrorCallCounter)
+}
+
+func operationEvent(client *model.Client, operation *model.Operation) *model.OutboxEntry {
+	return model.NewOutboxEntry(outbox_type.Event, client.Id, operation, "")
+}
+
+func storedClient(clientId string) map[string]types.AttributeValue {
+	output, _ := dynamoDBClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		Key:       map[string]types.AttributeValue{"client_id": &types.AttributeValueMemberS{Value: clientId}},
+		TableName: properties.Properties().Aws.DynamoDB.ClientTableName,
+	})
+
+	return output.Item
+}
+
+func TestReleaseReservationSuccess(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	_ = clientPersistence.Lock(context.Background(), client)
+	client.CashAmount = 90
+	client.CashReserved = 10
+	operation := model.NewOperation(uuid.NewString(), 50)
+	operation.Type = operation_type.Buy
+	operation.Amount = 10
+	_ = clientPersistence.SaveReservation(context.Background(), client, operation, operationEvent(client, operation))
+
+	client.ReleaseReservation(operation)
+	operation.Cancel()
+
+	err := clientPersistence.ReleaseReservation(context.Background(), client, operation)
+
+	output := storedClient(client.Id)
+	operationOutput, _ := dynamoDBClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		Key:       map[string]types.AttributeValue{"operation_id": &types.AttributeValueMemberS{Value: operation.Id}},
+		TableName: properties.Properties().Aws.DynamoDB.OperationTableName,
+	})
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 3, client.Version)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "100"}, output["cash_amount"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "0"}, output["cash_reserved"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "CANCELLED"}, operationOutput.Item["status"])
+	assert.Equal(t, 2, dynamoDBClient.TransactWriteItemsCounter)
+	assert.Equal(t, 0, logger.ErrorCallCounter)
+}
+
+func TestReleaseReservationOperationNotCreatedFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	_ = clientPersistence.Lock(context.Background(), client)
+	operation := model.NewOperation(uuid.NewString(), 50)
+	_ = clientPersistence.SaveReservation(context.Background(), client, operation, operationEvent(client, operation))
+	operation.Cancel()
+	_ = clientPersistence.ReleaseReservation(context.Background(), client, operation)
+
+	err := clientPersistence.ReleaseReservation(context.Background(), client, operation)
+
+	assert.NotNilf(t, err, "Should not be nil")
+	assert.Equal(t, "Operation was modified by another process.", err.InternalError())
+	assert.Equal(t, error_code.OperationModified.Name(), err.Code())
+	assert.Equal(t, true, err.LockedClient())
+	assert.Equal(t, 3, client.Version)
+}
+
+func TestReleaseReservationVersionConflictFailure(t *testing.T) {
+	clientPersistenceSetup()
+
+	client, _ := clientPersistence.GetClient(context.Background(), clientUnlocked.Id)
+	_ = clientPersistence.Lock(context.Background(), client)
+	operation := model.NewOperation(uuid.NewString(), 50)
+	_ = clientPersistence.SaveReservation(context.Background(), client, operation, operationEvent(client, operation))
+	client.Version = 1
+	operation.Cancel()
+
+	err := clientPersistence.ReleaseReservation(context.Background(), client, operation)
+
+	assert.NotNilf(t, err, "Should not be nil")
+	assert.Equal(t, error_code.ClientModified.Name(), err.Code())
+	assert.Equal(t, 1, client.Version)
+}
@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"context"
 	"errors"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
@@ -40,7 +41,7 @@ func setupCredentialsPersistence() {
 func TestGetCredentialsSuccess(t *testing.T) {
 	setupCredentialsPersistence()
 
-	credentialsPersisted, err := credentialsPersistence.GetCredentials(credentials.ClientId)
+	credentialsPersisted, err := credentialsPersistence.GetCredentials(context.Background(), credentials.ClientId)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, credentialsPersisted)
@@ -57,7 +58,7 @@ func TestGetCredentialsDynamoDBErrorFailure(t *testing.T) {
 
 	dynamoDBCredentials.GetItemError = errors.New("get item error")
 
-	credentialsPersisted, err := credentialsPersistence.GetCredentials(credentials.ClientId)
+	credentialsPersisted, err := credentialsPersistence.GetCredentials(context.Background(), credentials.ClientId)
 
 	assert.Nil(t, credentialsPersisted)
 	assert.NotNil(t, err)
@@ -74,7 +75,7 @@ func TestGetCredentialsNotFoundFailure(t *testing.T) {
 
 	dynamoDBCredentials.Reset()
 
-	credentialsPersisted, err := credentialsPersistence.GetCredentials(credentials.ClientId)
+	credentialsPersisted, err := credentialsPersistence.GetCredentials(context.Background(), credentials.ClientId)
 
 	assert.Nil(t, credentialsPersisted)
 	assert.NotNil(t, err)
@@ -95,7 +96,7 @@ func TestGetCredentialsUnmarshalFailure(t *testing.T) {
 
 	dynamoDBCredentials.AddItem(credentials.ClientId, fakeClient, properties.Properties().Aws.DynamoDB.CredentialsTableName)
 
-	credentialsPersisted, err := credentialsPersistence.GetCredentials(credentials.ClientId)
+	credentialsPersisted, err := credentialsPersistence.GetCredentials(context.Background(), credentials.ClientId)
 
 	assert.Nil(t, credentialsPersisted)
 	assert.NotNil(t, err)
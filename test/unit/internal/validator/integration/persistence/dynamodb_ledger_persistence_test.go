@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/persistence"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ledgerPersistence adapters.LedgerPersistenceAdapter
+	loggerLedger      = mocks.Logger()
+	dynamoDBLedger    = mocks.DynamoDBClient()
+)
+
+var (
+	ledger *dto.Ledger
+)
+
+func setupLedgerPersistence() {
+	config.LoadTestEnv()
+
+	loggerLedger.Reset()
+	dynamoDBLedger.Reset()
+
+	ledger = dto.NewLedger(uuid.NewString(), map[string]float64{"BRL": 1000})
+
+	ledgerPersistence = persistence.DynamoDBLedgerPersistence(loggerLedger, dynamoDBLedger)
+}
+
+func TestGetLedgerSuccess(t *testing.T) {
+	setupLedgerPersistence()
+
+	ledger.Version = 3
+	dynamoDBLedger.AddItem(ledger.ClientId, ledger, properties.Properties().Aws.DynamoDB.LedgerTableName)
+
+	ledgerPersisted, err := ledgerPersistence.Get(context.Background(), ledger.ClientId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, ledger.ClientId, ledgerPersisted.ClientId)
+	assert.Equal(t, 1000.0, ledgerPersisted.Balances["BRL"])
+	assert.Equal(t, 3, ledgerPersisted.Version)
+	assert.Equal(t, 1, dynamoDBLedger.GetItemCounter)
+	assert.Equal(t, 0, loggerLedger.ErrorCallCounter)
+}
+
+func TestGetLedgerNotFoundSuccess(t *testing.T) {
+	setupLedgerPersistence()
+
+	ledgerPersisted, err := ledgerPersistence.Get(context.Background(), ledger.ClientId)
+
+	assert.Nil(t, err)
+	assert.Nil(t, ledgerPersisted)
+	assert.Equal(t, 0, loggerLedger.ErrorCallCounter)
+}
+
+func TestGetLedgerDynamoDBErrorFailure(t *testing.T) {
+	setupLedgerPersistence()
+
+	dynamoDBLedger.GetItemError = errors.New("get item error")
+
+	ledgerPersisted, err := ledgerPersistence.Get(context.Background(), ledger.ClientId)
+
+	assert.Nil(t, ledgerPersisted)
+	assert.Equal(t, "get item error", err.Error())
+	assert.Equal(t, "Error while trying to get ledger.", err.InternalError())
+	assert.Equal(t, "Error while using DynamoDB Ledger table", err.Description())
+	assert.Equal(t, 1, loggerLedger.ErrorCallCounter)
+}
+
+func TestSaveLedgerCreateSuccess(t *testing.T) {
+	setupLedgerPersistence()
+
+	err := ledgerPersistence.Save(context.Background(), ledger)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, ledger.Version)
+	assert.Equal(t, 1, dynamoDBLedger.PutItemCounter)
+
+	ledgerPersisted, _ := ledgerPersistence.Get(context.Background(), ledger.ClientId)
+	assert.Equal(t, 1, ledgerPersisted.Version)
+	assert.Equal(t, 1000.0, ledgerPersisted.Balances["BRL"])
+}
+
+func TestSaveLedgerUpdateSuccess(t *testing.T) {
+	setupLedgerPersistence()
+
+	_ = ledgerPersistence.Save(context.Background(), ledger)
+	ledger.Balances["BRL"] = 900
+
+	err := ledgerPersistence.Save(context.Background(), ledger)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, ledger.Version)
+
+	ledgerPersisted, _ := ledgerPersistence.Get(context.Background(), ledger.ClientId)
+	assert.Equal(t, 2, ledgerPersisted.Version)
+	assert.Equal(t, 900.0, ledgerPersisted.Balances["BRL"])
+}
+
+func TestSaveLedgerExistsFailure(t *testing.T) {
+	setupLedgerPersistence()
+
+	_ = ledgerPersistence.Save(context.Background(), dto.NewLedger(ledger.ClientId, map[string]float64{}))
+
+	err := ledgerPersistence.Save(context.Background(), ledger)
+
+	assert.Equal(t, error_code.LedgerModified.Name(), err.Code())
+	assert.Equal(t, "Ledger was modified by another process.", err.InternalError())
+	assert.Equal(t, 0, ledger.Version)
+	assert.Equal(t, 1, loggerLedger.ErrorCallCounter)
+}
+
+func TestSaveLedgerModifiedFailure(t *testing.T) {
+	setupLedgerPersistence()
+
+	_ = ledgerPersistence.Save(context.Background(), ledger)
+	stale := *ledger
+	_ = ledgerPersistence.Save(context.Background(), ledger)
+
+	err := ledgerPersistence.Save(context.Background(), &stale)
+
+	assert.Equal(t, error_code.LedgerModified.Name(), err.Code())
+	assert.Equal(t, "Ledger was modified by another process.", err.InternalError())
+	assert.Equal(t, 1, stale.Version)
+}
+
+func TestSaveLedgerPutItemFailure(t *testing.T) {
+	setupLedgerPersistence()
+
+	dynamoDBLedger.PutItemError = errors.New("put item error")
+
+	err := ledgerPersistence.Save(context.Background(), ledger)
+
+	assert.Equal(t, "put item error", err.Error())
+	assert.Equal(t, "PutItem error", err.InternalError())
+	assert.Equal(t, "", err.Code())
+	assert.Equal(t, 0, ledger.Version)
+}
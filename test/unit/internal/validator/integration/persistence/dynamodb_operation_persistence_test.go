@@ -4,8 +4,6 @@ import (
 	"context"
 	"errors"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
@@ -13,6 +11,7 @@ import (
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/persistence"
 	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -35,43 +34,65 @@ func setup() {
 	loggerMock.Reset()
 	dynamoDBClientMock.Reset()
 
-	operation = model.NewOperation(50.00)
+	operation = model.NewOperation(uuid.NewString(), 50.00)
 }
 
-func TestSaveSuccess(t *testing.T) {
+// addOperation stores the operation on the operation table, operations are created by the client reservation.
+func addOperation(operation *model.Operation) {
+	item, _ := attributevalue.MarshalMap(dto.OperationDto(operation))
+	dynamoDBClientMock.AddItem(operation.Id, item, properties.Properties().Aws.DynamoDB.OperationTableName)
+}
+
+func TestGetSuccess(t *testing.T) {
 	setup()
 
-	err := operationPersistence.Save(operation)
+	addOperation(operation)
 
-	assert.Nilf(t, err, "Should be nil")
-	assert.Equal(t, 1, dynamoDBClientMock.PutItemCounter)
-	assert.Equal(t, 2, loggerMock.InfoCallCounter)
+	operationFound, err := operationPersistence.Get(context.Background(), operation.Id)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, operationFound)
+	assert.Equal(t, operation.Id, operationFound.Id)
+	assert.Equal(t, operation.Status, operationFound.Status)
+	assert.Equal(t, operation.StopLoss, operationFound.StopLoss)
+	assert.Equal(t, 1, dynamoDBClientMock.GetItemCounter)
 	assert.Equal(t, 0, loggerMock.ErrorCallCounter)
+}
+
+func TestGetSimulationSuccess(t *testing.T) {
+	setup()
 
-	response, _ := dynamoDBClientMock.GetItem(context.TODO(), &dynamodb.GetItemInput{
-		Key: map[string]types.AttributeValue{
-			"operation_id": &types.AttributeValueMemberS{Value: operation.Id},
-		},
-		TableName: properties.Properties().Aws.DynamoDB.OperationTableName,
-	})
+	operation.Simulation = true
+	addOperation(operation)
 
-	var operationSaved *dto.Operation
-	_ = attributevalue.UnmarshalMap(response.Item, &operationSaved)
+	operationFound, err := operationPersistence.Get(context.Background(), operation.Id)
 
-	assert.Equal(t, operation.Id, operationSaved.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, true, operationFound.Simulation)
+}
+
+func TestGetNotFoundSuccess(t *testing.T) {
+	setup()
+
+	operationFound, err := operationPersistence.Get(context.Background(), uuid.NewString())
+
+	assert.Nil(t, err)
+	assert.Nil(t, operationFound)
+	assert.Equal(t, 1, dynamoDBClientMock.GetItemCounter)
+	assert.Equal(t, 2, loggerMock.InfoCallCounter)
+	assert.Equal(t, 0, loggerMock.ErrorCallCounter)
 }
 
-func TestSavePutItemFailure(t *testing.T) {
+func TestGetItemFailure(t *testing.T) {
 	setup()
 
-	dynamoDBClientMock.PutItemError = errors.New("put item error")
+	dynamoDBClientMock.GetItemError = errors.New("get item error")
 
-	err := operationPersistence.Save(operation)
+	operationFound, err := operationPersistence.Get(context.Background(), operation.Id)
 
-	assert.Equal(t, "put item error", err.Error())
-	assert.Equal(t, "PutItem error", err.InternalError())
+	assert.Nil(t, operationFound)
+	assert.Equal(t, "get item error", err.Error())
+	assert.Equal(t, "Error while trying to get operation.", err.InternalError())
 	assert.Equal(t, "Error while using DynamoDB Operation table", err.Description())
-	assert.Equal(t, 1, dynamoDBClientMock.PutItemCounter)
-	assert.Equal(t, 1, loggerMock.InfoCallCounter)
 	assert.Equal(t, 1, loggerMock.ErrorCallCounter)
 }
@@ -0,0 +1,232 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/lock_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_status"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/outbox_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	outboxPersistence adapters.OutboxPersistenceAdapter
+	outboxEntry       *model.OutboxEntry
+)
+
+func outboxPersistenceSetup() {
+	config.LoadTestEnv()
+
+	outboxPersistence = persistence.DynamoDBOutboxPersistence(loggerMock, dynamoDBClientMock)
+
+	loggerMock.Reset()
+	dynamoDBClientMock.Reset()
+
+	outboxEntry = model.NewOutboxEntry(outbox_type.Compensation, uuid.NewString(), model.NewOperation(uuid.NewString(), 50.00), "send error")
+}
+
+func TestSaveOutboxEntrySuccess(t *testing.T) {
+	outboxPersistenceSetup()
+
+	err := outboxPersistence.Save(context.Background(), outboxEntry)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 1, dynamoDBClientMock.PutItemCounter)
+	assert.Equal(t, 1, dynamoDBClientMock.NumberOfOutboxEntries())
+	assert.Equal(t, 2, loggerMock.InfoCallCounter)
+	assert.Equal(t, 0, loggerMock.ErrorCallCounter)
+
+	response, _ := dynamoDBClientMock.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		Key: map[string]types.AttributeValue{
+			"outbox_id": &types.AttributeValueMemberS{Value: outboxEntry.Id},
+		},
+		TableName: properties.Properties().Aws.DynamoDB.OutboxTableName,
+	})
+
+	var entrySaved *dto.OutboxEntry
+	_ = attributevalue.UnmarshalMap(response.Item, &entrySaved)
+
+	assert.Equal(t, "COMPENSATION#"+outboxEntry.Operation.Id, entrySaved.Id)
+	assert.Equal(t, outbox_status.Pending, entrySaved.Status)
+	assert.Equal(t, outboxEntry.ClientId, entrySaved.ClientId)
+	assert.Equal(t, outboxEntry.Operation.Id, entrySaved.Operation.Id)
+	assert.Equal(t, "send error", entrySaved.Reason)
+}
+
+func TestSaveOutboxEntrySameOperationSuccess(t *testing.T) {
+	outboxPersistenceSetup()
+
+	_ = outboxPersistence.Save(context.Background(), outboxEntry)
+	err := outboxPersistence.Save(context.Background(), model.NewOutboxEntry(outbox_type.Compensation, outboxEntry.ClientId, outboxEntry.Operation, "send error"))
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 2, dynamoDBClientMock.PutItemCounter)
+	assert.Equal(t, 1, dynamoDBClientMock.NumberOfOutboxEntries())
+}
+
+func TestSaveUnlockOutboxEntrySuccess(t *testing.T) {
+	outboxPersistenceSetup()
+
+	entry := model.NewUnlockOutboxEntry(outboxEntry.ClientId, []lock_type.LockType{lock_type.Client, lock_type.ClientId}, "unlock error")
+
+	err := outboxPersistence.Save(context.Background(), entry)
+
+	entries, _ := outboxPersistence.GetPending(context.Background(), outbox_type.Unlock)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "UNLOCK#"+outboxEntry.ClientId, entries[0].Id)
+	assert.Equal(t, outboxEntry.ClientId, entries[0].ClientId)
+	assert.Equal(t, []lock_type.LockType{lock_type.Client, lock_type.ClientId}, entries[0].Locks)
+	assert.Nil(t, entries[0].Operation)
+	assert.Equal(t, "unlock error", entries[0].Reason)
+}
+
+func TestSaveOutboxEntryPutItemFailure(t *testing.T) {
+	outboxPersistenceSetup()
+
+	dynamoDBClientMock.PutItemError = errors.New("put item error")
+
+	err := outboxPersistence.Save(context.Background(), outboxEntry)
+
+	assert.Equal(t, "put item error", err.Error())
+	assert.Equal(t, "PutItem error", err.InternalError())
+	assert.Equal(t, "Error while using DynamoDB Outbox table", err.Description())
+	assert.Equal(t, 1, dynamoDBClientMock.PutItemCounter)
+	assert.Equal(t, 1, loggerMock.InfoCallCounter)
+	assert.Equal(t, 1, loggerMock.ErrorCallCounter)
+}
+
+func TestGetOutboxEntrySuccess(t *testing.T) {
+	outboxPersistenceSetup()
+
+	_ = outboxPersistence.Save(context.Background(), outboxEntry)
+
+	entry, err := outboxPersistence.Get(context.Background(), outboxEntry.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, outboxEntry.Id, entry.Id)
+	assert.Equal(t, outbox_type.Compensation, entry.Type)
+	assert.Equal(t, outbox_status.Pending, entry.Status)
+	assert.Equal(t, outboxEntry.ClientId, entry.ClientId)
+	assert.Equal(t, outboxEntry.Operation.Id, entry.Operation.Id)
+	assert.Equal(t, 1, dynamoDBClientMock.GetItemCounter)
+	assert.Equal(t, 0, loggerMock.ErrorCallCounter)
+}
+
+func TestGetOutboxEntryNotFoundSuccess(t *testing.T) {
+	outboxPersistenceSetup()
+
+	entry, err := outboxPersistence.Get(context.Background(), outboxEntry.Id)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Nil(t, entry)
+	assert.Equal(t, 1, dynamoDBClientMock.GetItemCounter)
+}
+
+func TestGetOutboxEntryGetItemFailure(t *testing.T) {
+	outboxPersistenceSetup()
+
+	dynamoDBClientMock.GetItemError = errors.New("get item error")
+
+	entry, err := outboxPersistence.Get(context.Background(), outboxEntry.Id)
+
+	assert.Nil(t, entry)
+	assert.Equal(t, "get item error", err.Error())
+	assert.Equal(t, "Error while trying to get outbox entry.", err.InternalError())
+	assert.Equal(t, "Error while using DynamoDB Outbox table", err.Description())
+	assert.Equal(t, 1, loggerMock.ErrorCallCounter)
+}
+
+func TestGetPendingOutboxEntriesSuccess(t *testing.T) {
+	outboxPersistenceSetup()
+
+	event := model.NewOutboxEntry(outbox_type.Event, uuid.NewString(), model.NewOperation(uuid.NewString(), 50.00), "")
+	sent := model.NewOutboxEntry(outbox_type.Event, uuid.NewString(), model.NewOperation(uuid.NewString(), 50.00), "")
+	_ = outboxPersistence.Save(context.Background(), outboxEntry)
+	_ = outboxPersistence.Save(context.Background(), event)
+	_ = outboxPersistence.Save(context.Background(), sent)
+	_ = outboxPersistence.UpdateStatus(context.Background(), sent, outbox_status.Sent)
+
+	entries, err := outboxPersistence.GetPending(context.Background(), outbox_type.Event)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, event.Id, entries[0].Id)
+	assert.Equal(t, outbox_type.Event, entries[0].Type)
+	assert.Equal(t, outbox_status.Pending, entries[0].Status)
+	assert.Equal(t, event.ClientId, entries[0].ClientId)
+	assert.Equal(t, event.Operation.Id, entries[0].Operation.Id)
+	assert.Equal(t, 1, dynamoDBClientMock.ScanCounter)
+}
+
+func TestGetPendingOutboxEntriesScanFailure(t *testing.T) {
+	outboxPersistenceSetup()
+
+	dynamoDBClientMock.ScanError = errors.New("scan error")
+
+	entries, err := outboxPersistence.GetPending(context.Background(), outbox_type.Event)
+
+	assert.Nil(t, entries)
+	assert.Equal(t, "scan error", err.Error())
+	assert.Equal(t, "Error while trying to scan outbox entries.", err.InternalError())
+	assert.Equal(t, "Error while using DynamoDB Outbox table", err.Description())
+	assert.Equal(t, 1, loggerMock.ErrorCallCounter)
+}
+
+func TestUpdateOutboxEntryStatusSuccess(t *testing.T) {
+	outboxPersistenceSetup()
+
+	_ = outboxPersistence.Save(context.Background(), outboxEntry)
+
+	err := outboxPersistence.UpdateStatus(context.Background(), outboxEntry, outbox_status.Discarded)
+
+	entries, _ := outboxPersistence.GetPending(context.Background(), outbox_type.Compensation)
+
+	assert.Nilf(t, err, "Should be nil")
+	assert.Equal(t, outbox_status.Discarded, outboxEntry.Status)
+	assert.Equal(t, 0, len(entries))
+	assert.Equal(t, 1, dynamoDBClientMock.UpdateItemCounter)
+	assert.Equal(t, 1, dynamoDBClientMock.NumberOfOutboxEntries())
+}
+
+func TestUpdateOutboxEntryStatusNotPendingFailure(t *testing.T) {
+	outboxPersistenceSetup()
+
+	_ = outboxPersistence.Save(context.Background(), outboxEntry)
+	_ = outboxPersistence.UpdateStatus(context.Background(), outboxEntry, outbox_status.Sent)
+
+	err := outboxPersistence.UpdateStatus(context.Background(), outboxEntry, outbox_status.Discarded)
+
+	assert.NotNilf(t, err, "Should not be nil")
+	assert.Equal(t, "Outbox entry is not pending.", err.InternalError())
+	assert.Equal(t, error_code.OutboxEntryModified.Name(), err.Code())
+	assert.Equal(t, outbox_status.Sent, outboxEntry.Status)
+	assert.Equal(t, 1, loggerMock.ErrorCallCounter)
+}
+
+func TestUpdateOutboxEntryStatusUpdateItemFailure(t *testing.T) {
+	outboxPersistenceSetup()
+
+	dynamoDBClientMock.UpdateItemError = errors.New("update item error")
+
+	err := outboxPersistence.UpdateStatus(context.Background(), outboxEntry, outbox_status.Sent)
+
+	assert.NotNilf(t, err, "Should not be nil")
+	assert.Equal(t, "update item error", err.Error())
+	assert.Equal(t, outbox_status.Pending, outboxEntry.Status)
+	assert.Equal(t, 1, dynamoDBClientMock.UpdateItemCounter)
+	assert.Equal(t, 1, loggerMock.ErrorCallCounter)
+}
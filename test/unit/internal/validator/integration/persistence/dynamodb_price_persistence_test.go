@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/persistence"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var (
+	pricePersistence adapters.PriceFeedAdapter
+	loggerPrice      = mocks.Logger()
+	dynamoDBPrice    = mocks.DynamoDBClient()
+)
+
+func setupPricePersistence() {
+	config.LoadTestEnv()
+
+	loggerPrice.Reset()
+	dynamoDBPrice.Reset()
+
+	pricePersistence = persistence.DynamoDBPricePersistence(loggerPrice, dynamoDBPrice)
+}
+
+func TestGetPriceSuccess(t *testing.T) {
+	setupPricePersistence()
+
+	timestamp := time.Now().UTC()
+	dynamoDBPrice.AddItem("BTCBRL", &dto.Price{
+		Pair:      "BTCBRL",
+		Symbol:    symbol.Bitcoin,
+		Quote:     symbol.Brl,
+		BuyValue:  100500,
+		SellValue: 100000,
+		Timestamp: timestamp,
+	}, properties.Properties().Aws.DynamoDB.PriceTableName)
+
+	coin, err := pricePersistence.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, err)
+	assert.Equal(t, symbol.Bitcoin, coin.Symbol)
+	assert.Equal(t, symbol.Brl, coin.Quote)
+	assert.Equal(t, 100500.0, coin.BuyValue)
+	assert.Equal(t, 100000.0, coin.SellValue)
+	assert.Equal(t, true, timestamp.Equal(coin.Timestamp))
+	assert.Equal(t, 0, loggerPrice.ErrorCallCounter)
+}
+
+func TestGetPriceNotRecordedFailure(t *testing.T) {
+	setupPricePersistence()
+
+	coin, err := pricePersistence.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, "Price not recorded for BTCBRL.", err.InternalError())
+	assert.Equal(t, "Error while using DynamoDB Price table", err.Description())
+	assert.Equal(t, true, err.LockedClient())
+	assert.Equal(t, 1, loggerPrice.ErrorCallCounter)
+}
+
+func TestGetPriceDynamoDBErrorFailure(t *testing.T) {
+	setupPricePersistence()
+
+	dynamoDBPrice.GetItemError = errors.New("get item error")
+
+	coin, err := pricePersistence.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, "get item error", err.Error())
+	assert.Equal(t, "Error while trying to get price.", err.InternalError())
+}
@@ -0,0 +1,307 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	adapters2 "github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var (
+	circuitBreaker adapters2.CircuitBreakerAdapter
+	circuitName    string
+)
+
+func circuitBreakerSetup() {
+	config.LoadTestEnv()
+	properties.Properties().Reload()
+
+	properties.Properties().CircuitBreaker.FailureThreshold = 3
+	properties.Properties().CircuitBreaker.FailureWindow = 10 * time.Second
+	properties.Properties().CircuitBreaker.OpenDuration = 5 * time.Second
+	properties.Properties().CircuitBreaker.ProbeTimeout = 2 * time.Second
+	properties.Properties().HTTPClientTimeout = 1 * time.Second
+	properties.Properties().ExchangeRetry.MaxAttempts = 1
+
+	loggerM.Reset()
+	redis.Reset()
+
+	circuitName = uuid.NewString()
+	circuitBreaker = persistence.RedisCircuitBreaker(loggerM, redis, circuitName)
+}
+
+func circuitKey(name string) string {
+	return properties.Properties().Cache.KeyPrefix + "breaker." + circuitName + "." + name
+}
+
+func tripCircuit() {
+	for i := 0; i < properties.Properties().CircuitBreaker.FailureThreshold; i++ {
+		circuitBreaker.Failure(context.Background())
+	}
+}
+
+func TestCircuitBreakerClosedAllowSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	err := circuitBreaker.Allow(context.Background())
+
+	assert.Nil(t, err, "Should be nil")
+	assert.Equal(t, 1, redis.OpenCounter)
+	assert.Equal(t, 1, redis.CloseCounter)
+}
+
+func TestCircuitBreakerFailuresBelowThresholdSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	circuitBreaker.Failure(context.Background())
+	circuitBreaker.Failure(context.Background())
+
+	err := circuitBreaker.Allow(context.Background())
+
+	failures, _ := redis.Get(circuitKey("failures"))
+
+	assert.Nil(t, err, "Should be nil")
+	assert.Equal(t, "2", failures)
+	assert.Equal(t, 10*time.Second, redis.TTL(circuitKey("failures")))
+}
+
+func TestCircuitBreakerOpensOnThresholdFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	tripCircuit()
+
+	err := circuitBreaker.Allow(context.Background())
+
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Equal(t, "Circuit "+circuitName+" is open", err.InternalError())
+	assert.Equal(t, "Circuit breaker is open", err.Description())
+	assert.Equal(t, true, err.LockedClientId())
+	assert.Equal(t, true, err.LockedClient())
+	assert.Equal(t, 5*time.Second, redis.TTL(circuitKey("open")))
+	assert.Equal(t, 1, loggerM.WarningCallCounter)
+}
+
+func TestCircuitBreakerFailureWindowExpiredSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	circuitBreaker.Failure(context.Background())
+	circuitBreaker.Failure(context.Background())
+	redis.FastForward(10 * time.Second)
+	circuitBreaker.Failure(context.Background())
+
+	err := circuitBreaker.Allow(context.Background())
+
+	assert.Nil(t, err, "Should be nil")
+}
+
+func TestCircuitBreakerHalfOpenSingleTrialSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	tripCircuit()
+	redis.FastForward(5 * time.Second)
+
+	trialErr := circuitBreaker.Allow(context.Background())
+	err := circuitBreaker.Allow(context.Background())
+
+	assert.Nil(t, trialErr, "Should be nil")
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+}
+
+func TestCircuitBreakerHalfOpenCheckKeepsTrialSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	tripCircuit()
+	redis.FastForward(5 * time.Second)
+
+	checkErr := circuitBreaker.Check(context.Background())
+	trialErr := circuitBreaker.Allow(context.Background())
+	err := circuitBreaker.Check(context.Background())
+
+	assert.Nil(t, checkErr, "Should be nil")
+	assert.Nil(t, trialErr, "Should be nil")
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+}
+
+func TestCircuitBreakerOpenCheckFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	tripCircuit()
+
+	err := circuitBreaker.Check(context.Background())
+
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Equal(t, "Circuit "+circuitName+" is open", err.InternalError())
+}
+
+func TestCircuitBreakerHalfOpenExpiresSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	tripCircuit()
+
+	assert.Equal(t, 10*time.Second, redis.TTL(circuitKey("half_open")))
+
+	redis.FastForward(5 * time.Second)
+	_ = circuitBreaker.Allow(context.Background())
+
+	assert.Equal(t, 2*time.Second, redis.TTL(circuitKey("probe")))
+
+	redis.FastForward(5 * time.Second)
+
+	assert.Nil(t, circuitBreaker.Allow(context.Background()), "Should be nil")
+	assert.Nil(t, circuitBreaker.Allow(context.Background()), "Should be nil")
+}
+
+func TestCircuitBreakerHalfOpenProbeExpiredNewTrialSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	properties.Properties().CircuitBreaker.ProbeTimeout = 1 * time.Second
+	circuitBreaker = persistence.RedisCircuitBreaker(loggerM, redis, circuitName)
+
+	tripCircuit()
+	redis.FastForward(5 * time.Second)
+
+	_ = circuitBreaker.Allow(context.Background())
+	runningErr := circuitBreaker.Allow(context.Background())
+
+	redis.FastForward(1 * time.Second)
+
+	trialErr := circuitBreaker.Allow(context.Background())
+	err := circuitBreaker.Allow(context.Background())
+
+	assert.NotNil(t, runningErr, "Should not be nil")
+	assert.Nil(t, trialErr, "Should be nil")
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Equal(t, 1*time.Second, redis.TTL(circuitKey("probe")))
+}
+
+func TestCircuitBreakerProbeTimeoutBelowHTTPTimeoutSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	properties.Properties().CircuitBreaker.ProbeTimeout = 500 * time.Millisecond
+	circuitBreaker = persistence.RedisCircuitBreaker(loggerM, redis, circuitName)
+
+	tripCircuit()
+	redis.FastForward(5 * time.Second)
+
+	_ = circuitBreaker.Allow(context.Background())
+
+	assert.Equal(t, properties.Properties().HTTPClientTimeout, redis.TTL(circuitKey("probe")))
+}
+
+func TestCircuitBreakerProbeTimeoutBelowRetryBudgetSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	properties.Properties().ExchangeRetry.MaxAttempts = 3
+	properties.Properties().ExchangeRetry.MaxBackoff = 1 * time.Second
+	properties.Properties().ExchangeRetry.Jitter = 0.5
+	circuitBreaker = persistence.RedisCircuitBreaker(loggerM, redis, circuitName)
+
+	tripCircuit()
+	redis.FastForward(5 * time.Second)
+
+	_ = circuitBreaker.Allow(context.Background())
+
+	assert.Equal(t, 6*time.Second, redis.TTL(circuitKey("probe")))
+}
+
+func TestCircuitBreakerHalfOpenTrialReleasedSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	tripCircuit()
+	redis.FastForward(5 * time.Second)
+
+	_ = circuitBreaker.Allow(context.Background())
+	circuitBreaker.Release(context.Background())
+
+	trialErr := circuitBreaker.Allow(context.Background())
+	err := circuitBreaker.Allow(context.Background())
+
+	assert.Nil(t, trialErr, "Should be nil")
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+}
+
+func TestCircuitBreakerIntermittentFailuresOpenFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	circuitBreaker.Failure(context.Background())
+	circuitBreaker.Success(context.Background())
+	circuitBreaker.Failure(context.Background())
+	circuitBreaker.Success(context.Background())
+	circuitBreaker.Failure(context.Background())
+
+	err := circuitBreaker.Allow(context.Background())
+
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+}
+
+func TestCircuitBreakerHalfOpenTrialSuccessClosesSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	tripCircuit()
+	redis.FastForward(5 * time.Second)
+
+	_ = circuitBreaker.Allow(context.Background())
+	circuitBreaker.Success(context.Background())
+
+	assert.Nil(t, circuitBreaker.Allow(context.Background()), "Should be nil")
+	assert.Nil(t, circuitBreaker.Allow(context.Background()), "Should be nil")
+}
+
+func TestCircuitBreakerHalfOpenTrialFailureReopensFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	tripCircuit()
+	redis.FastForward(5 * time.Second)
+
+	_ = circuitBreaker.Allow(context.Background())
+	circuitBreaker.Failure(context.Background())
+
+	err := circuitBreaker.Allow(context.Background())
+
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, 5*time.Second, redis.TTL(circuitKey("open")))
+	assert.Equal(t, 2, loggerM.WarningCallCounter)
+}
+
+func TestCircuitBreakerOpenConnectionFailureAllowSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	tripCircuit()
+	redis.OpenError = errors.New("open conn error")
+
+	err := circuitBreaker.Allow(context.Background())
+
+	assert.Nil(t, err, "Should be nil")
+	assert.Equal(t, 2, loggerM.WarningCallCounter)
+	assert.Equal(t, 0, loggerM.ErrorCallCounter)
+}
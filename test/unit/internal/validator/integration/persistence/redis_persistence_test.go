@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"context"
 	"errors"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
@@ -10,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 var (
@@ -41,12 +43,14 @@ func TestRedisLockSuccess(t *testing.T) {
 	redisPersistenceSetup()
 	defer teardown()
 
-	err := redisPersistence.Lock(key)
+	err := redisPersistence.Lock(context.Background(), key)
 
 	keyLocked, _ := redis.Get(properties.Properties().Cache.KeyPrefix + key)
 
 	assert.Nil(t, err, "Should be nil")
-	assert.Equal(t, keyLocked, key)
+	assert.NotEmpty(t, keyLocked)
+	assert.NotEqual(t, key, keyLocked)
+	assert.Equal(t, properties.Properties().Cache.KeyTTL, redis.TTL(properties.Properties().Cache.KeyPrefix+key))
 	assert.Equal(t, 1, redis.OpenCounter)
 	assert.Equal(t, 1, redis.CloseCounter)
 	assert.Equal(t, 2, loggerM.InfoCallCounter)
@@ -60,12 +64,13 @@ func TestRedisLockKeyAlreadyLockedFailure(t *testing.T) {
 
 	_ = redis.Set(properties.Properties().Cache.KeyPrefix+key, key)
 
-	err := redisPersistence.Lock(key)
+	err := redisPersistence.Lock(context.Background(), key)
 
 	assert.NotNil(t, err, "Should not be nil")
 	assert.Equal(t, "Key is already locked", err.Error())
 	assert.Equal(t, "Key is already locked", err.InternalError())
 	assert.Equal(t, "Error while using Redis cache", err.Description())
+	assert.Equal(t, "Lock failed: Key is already locked", loggerM.LastErrorMessage)
 	assert.Equal(t, 1, redis.OpenCounter)
 	assert.Equal(t, 1, redis.CloseCounter)
 	assert.Equal(t, 1, loggerM.InfoCallCounter)
@@ -77,11 +82,11 @@ func TestRedisLockKeyAlreadyLocked2Failure(t *testing.T) {
 	redisPersistenceSetup()
 	defer teardown()
 
-	err := redisPersistence.Lock(key)
+	err := redisPersistence.Lock(context.Background(), key)
 
 	assert.Nil(t, err, "Should be nil")
 
-	err = redisPersistence.Lock(key)
+	err = redisPersistence.Lock(context.Background(), key)
 
 	assert.NotNil(t, err, "Should not be nil")
 	assert.Equal(t, "Key is already locked", err.Error())
@@ -100,7 +105,7 @@ func TestRedisLockOpenConnectionFailure(t *testing.T) {
 
 	redis.OpenError = errors.New("open conn error")
 
-	err := redisPersistence.Lock(key)
+	err := redisPersistence.Lock(context.Background(), key)
 
 	assert.NotNil(t, err, "Should not be nil")
 	assert.Equal(t, "open conn error", err.Error())
@@ -113,32 +118,13 @@ func TestRedisLockOpenConnectionFailure(t *testing.T) {
 	assert.Equal(t, 0, loggerM.WarningCallCounter)
 }
 
-func TestRedisLockGetFailure(t *testing.T) {
-	redisPersistenceSetup()
-	defer teardown()
-
-	redis.GetError = errors.New("get error")
-
-	err := redisPersistence.Lock("error")
-
-	assert.NotNil(t, err, "Should not be nil")
-	assert.Equal(t, "get error", err.Error())
-	assert.Equal(t, "Error while trying to get redis key", err.InternalError())
-	assert.Equal(t, "Error while using Redis cache", err.Description())
-	assert.Equal(t, 1, redis.OpenCounter)
-	assert.Equal(t, 1, redis.CloseCounter)
-	assert.Equal(t, 1, loggerM.InfoCallCounter)
-	assert.Equal(t, 1, loggerM.ErrorCallCounter)
-	assert.Equal(t, 0, loggerM.WarningCallCounter)
-}
-
 func TestRedisLockSetFailure(t *testing.T) {
 	redisPersistenceSetup()
 	defer teardown()
 
 	redis.SetError = errors.New("set error")
 
-	err := redisPersistence.Lock("error")
+	err := redisPersistence.Lock(context.Background(), "error")
 
 	assert.NotNil(t, err, "Should not be nil")
 	assert.Equal(t, "set error", err.Error())
@@ -157,7 +143,7 @@ func TestRedisLockCloseSuccess(t *testing.T) {
 
 	redis.CloseError = errors.New("close error")
 
-	err := redisPersistence.Lock("error")
+	err := redisPersistence.Lock(context.Background(), "error")
 
 	assert.Nil(t, err, "Should be nil")
 	assert.Equal(t, 1, redis.OpenCounter)
@@ -171,17 +157,17 @@ func TestRedisUnlockSuccess(t *testing.T) {
 	redisPersistenceSetup()
 	defer teardown()
 
-	_ = redis.Set(properties.Properties().Cache.KeyPrefix+key, key)
+	_ = redisPersistence.Lock(context.Background(), key)
 
-	err := redisPersistence.Unlock(key)
+	err := redisPersistence.Unlock(context.Background(), key)
 
 	keyLocked, _ := redis.Get(properties.Properties().Cache.KeyPrefix + key)
 
 	assert.Nil(t, err, "Should be nil")
 	assert.Equal(t, keyLocked, "")
-	assert.Equal(t, 1, redis.OpenCounter)
-	assert.Equal(t, 1, redis.CloseCounter)
-	assert.Equal(t, 2, loggerM.InfoCallCounter)
+	assert.Equal(t, 2, redis.OpenCounter)
+	assert.Equal(t, 2, redis.CloseCounter)
+	assert.Equal(t, 4, loggerM.InfoCallCounter)
 	assert.Equal(t, 0, loggerM.ErrorCallCounter)
 	assert.Equal(t, 0, loggerM.WarningCallCounter)
 }
@@ -190,14 +176,14 @@ func TestRedisUnlockKeyNotLockedSuccess(t *testing.T) {
 	redisPersistenceSetup()
 	defer teardown()
 
-	err := redisPersistence.Unlock(key)
+	err := redisPersistence.Unlock(context.Background(), key)
 
 	keyLocked, _ := redis.Get(properties.Properties().Cache.KeyPrefix + key)
 
 	assert.Nil(t, err, "Should be nil")
 	assert.Equal(t, keyLocked, "")
-	assert.Equal(t, 1, redis.OpenCounter)
-	assert.Equal(t, 1, redis.CloseCounter)
+	assert.Equal(t, 0, redis.OpenCounter)
+	assert.Equal(t, 0, redis.CloseCounter)
 	assert.Equal(t, 2, loggerM.InfoCallCounter)
 	assert.Equal(t, 0, loggerM.ErrorCallCounter)
 	assert.Equal(t, 0, loggerM.WarningCallCounter)
@@ -207,76 +193,269 @@ func TestRedisUnlockOpenFailure(t *testing.T) {
 	redisPersistenceSetup()
 	defer teardown()
 
+	_ = redisPersistence.Lock(context.Background(), key)
 	redis.OpenError = errors.New("open conn error")
 
-	_ = redis.Set(properties.Properties().Cache.KeyPrefix+key, key)
-	err := redisPersistence.Unlock(key)
+	err := redisPersistence.Unlock(context.Background(), key)
 
 	assert.NotNil(t, err, "Should not be nil")
 	assert.Equal(t, "open conn error", err.Error())
 	assert.Equal(t, "Error while trying to open redis connection", err.InternalError())
 	assert.Equal(t, "Error while using Redis cache", err.Description())
-	assert.Equal(t, 1, redis.OpenCounter)
-	assert.Equal(t, 0, redis.CloseCounter)
-	assert.Equal(t, 1, loggerM.InfoCallCounter)
+	assert.Equal(t, 2, redis.OpenCounter)
+	assert.Equal(t, 1, redis.CloseCounter)
+	assert.Equal(t, 3, loggerM.InfoCallCounter)
 	assert.Equal(t, 1, loggerM.ErrorCallCounter)
 	assert.Equal(t, 0, loggerM.WarningCallCounter)
 }
 
-func TestRedisUnlockDelFailure(t *testing.T) {
+func TestRedisUnlockEvalFailure(t *testing.T) {
 	redisPersistenceSetup()
 	defer teardown()
 
-	redis.DelError = errors.New("del error")
+	_ = redisPersistence.Lock(context.Background(), "error")
+	redis.EvalError = errors.New("eval error")
 
-	_ = redis.Set(properties.Properties().Cache.KeyPrefix+key, key)
-	err := redisPersistence.Unlock("error")
+	err := redisPersistence.Unlock(context.Background(), "error")
 
 	assert.NotNil(t, err, "Should not be nil")
-	assert.Equal(t, "del error", err.Error())
+	assert.Equal(t, "eval error", err.Error())
 	assert.Equal(t, "Error while trying to delete redis key", err.InternalError())
 	assert.Equal(t, "Error while using Redis cache", err.Description())
-	assert.Equal(t, 1, redis.OpenCounter)
-	assert.Equal(t, 1, redis.CloseCounter)
-	assert.Equal(t, 1, loggerM.InfoCallCounter)
+	assert.Equal(t, 2, redis.OpenCounter)
+	assert.Equal(t, 2, redis.CloseCounter)
+	assert.Equal(t, 3, loggerM.InfoCallCounter)
 	assert.Equal(t, 1, loggerM.ErrorCallCounter)
 	assert.Equal(t, 0, loggerM.WarningCallCounter)
 }
 
+func TestRedisUnlockRetryAfterEvalFailureSuccess(t *testing.T) {
+	redisPersistenceSetup()
+	defer teardown()
+
+	_ = redisPersistence.Lock(context.Background(), "error")
+	redis.EvalError = errors.New("eval error")
+
+	firstErr := redisPersistence.Unlock(context.Background(), "error")
+	retryErr := redisPersistence.Unlock(context.Background(), "error")
+	redis.EvalError = nil
+	err := redisPersistence.Unlock(context.Background(), "error")
+
+	keyLocked, _ := redis.Get(properties.Properties().Cache.KeyPrefix + "error")
+
+	assert.NotNil(t, firstErr, "Should not be nil")
+	assert.NotNil(t, retryErr, "Should not be nil")
+	assert.Equal(t, "Error while trying to delete redis key", retryErr.InternalError())
+	assert.Nil(t, err, "Should be nil")
+	assert.Equal(t, "", keyLocked)
+}
+
 func TestRedisUnlockCloseSuccess(t *testing.T) {
 	redisPersistenceSetup()
 	defer teardown()
 
+	_ = redisPersistence.Lock(context.Background(), key)
 	redis.CloseError = errors.New("close error")
 
-	_ = redis.Set(properties.Properties().Cache.KeyPrefix+key, key)
-	err := redisPersistence.Unlock(key)
+	err := redisPersistence.Unlock(context.Background(), key)
 
 	assert.Nil(t, err, "Should be nil")
-	assert.Equal(t, 1, redis.OpenCounter)
-	assert.Equal(t, 1, redis.CloseCounter)
-	assert.Equal(t, 2, loggerM.InfoCallCounter)
+	assert.Equal(t, 2, redis.OpenCounter)
+	assert.Equal(t, 2, redis.CloseCounter)
+	assert.Equal(t, 4, loggerM.InfoCallCounter)
 	assert.Equal(t, 0, loggerM.ErrorCallCounter)
 	assert.Equal(t, 1, loggerM.WarningCallCounter)
 }
 
-func TestRedisUnlockDelAndCloseErrorFailure(t *testing.T) {
+func TestRedisUnlockEvalAndCloseErrorFailure(t *testing.T) {
 	redisPersistenceSetup()
 	defer teardown()
 
 	redis.CloseError = errors.New("close error")
-	redis.DelError = errors.New("del error")
+	_ = redisPersistence.Lock(context.Background(), "error")
+	redis.EvalError = errors.New("eval error")
 
-	_ = redis.Set(properties.Properties().Cache.KeyPrefix+key, key)
-	err := redisPersistence.Unlock("error")
+	err := redisPersistence.Unlock(context.Background(), "error")
 
 	assert.NotNil(t, err, "Should not be nil")
-	assert.Equal(t, "del error", err.Error())
+	assert.Equal(t, "eval error", err.Error())
 	assert.Equal(t, "Error while trying to delete redis key", err.InternalError())
 	assert.Equal(t, "Error while using Redis cache", err.Description())
+	assert.Equal(t, 2, redis.OpenCounter)
+	assert.Equal(t, 2, redis.CloseCounter)
+	assert.Equal(t, 3, loggerM.InfoCallCounter)
+	assert.Equal(t, 1, loggerM.ErrorCallCounter)
+	assert.Equal(t, 2, loggerM.WarningCallCounter)
+}
+
+func TestRedisUnlockKeyOwnedByOtherProcessSuccess(t *testing.T) {
+	redisPersistenceSetup()
+	defer teardown()
+
+	_ = redisPersistence.Lock(context.Background(), key)
+	redis.FastForward(properties.Properties().Cache.KeyTTL)
+	_ = redis.Set(properties.Properties().Cache.KeyPrefix+key, "other-owner")
+
+	err := redisPersistence.Unlock(context.Background(), key)
+
+	keyLocked, _ := redis.Get(properties.Properties().Cache.KeyPrefix + key)
+
+	assert.Nil(t, err, "Should be nil")
+	assert.Equal(t, "other-owner", keyLocked)
+	assert.Equal(t, 0, loggerM.ErrorCallCounter)
+	assert.Equal(t, 1, loggerM.WarningCallCounter)
+}
+
+func TestRedisExtendSuccess(t *testing.T) {
+	redisPersistenceSetup()
+	defer teardown()
+
+	_ = redisPersistence.Lock(context.Background(), key)
+	redis.FastForward(properties.Properties().Cache.KeyTTL / 2)
+
+	err := redisPersistence.Extend(context.Background(), key)
+
+	assert.Nil(t, err, "Should be nil")
+	assert.Equal(t, properties.Properties().Cache.KeyTTL, redis.TTL(properties.Properties().Cache.KeyPrefix+key))
+	assert.Equal(t, 2, redis.OpenCounter)
+	assert.Equal(t, 2, redis.CloseCounter)
+	assert.Equal(t, 4, loggerM.InfoCallCounter)
+	assert.Equal(t, 0, loggerM.ErrorCallCounter)
+}
+
+func TestRedisExtendKeyNotLockedFailure(t *testing.T) {
+	redisPersistenceSetup()
+	defer teardown()
+
+	err := redisPersistence.Extend(context.Background(), key)
+
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, "Key is not locked by this process", err.InternalError())
+	assert.Equal(t, 0, redis.OpenCounter)
+	assert.Equal(t, 1, loggerM.ErrorCallCounter)
+}
+
+func TestRedisExtendKeyExpiredFailure(t *testing.T) {
+	redisPersistenceSetup()
+	defer teardown()
+
+	_ = redisPersistence.Lock(context.Background(), key)
+	redis.FastForward(properties.Properties().Cache.KeyTTL)
+
+	err := redisPersistence.Extend(context.Background(), key)
+
+	keyLocked, _ := redis.Get(properties.Properties().Cache.KeyPrefix + key)
+
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, "Key lock expired before extension", err.InternalError())
+	assert.Equal(t, "Extend failed: Key lock expired before extension", loggerM.LastErrorMessage)
+	assert.Equal(t, "", keyLocked)
+	assert.Equal(t, 2, redis.CloseCounter)
+	assert.Equal(t, 1, loggerM.ErrorCallCounter)
+}
+
+func TestRedisExistsKeyLockedByOtherProcessSuccess(t *testing.T) {
+	redisPersistenceSetup()
+	defer teardown()
+
+	_ = redis.Set(properties.Properties().Cache.KeyPrefix+key, "other-owner")
+
+	exists, err := redisPersistence.Exists(context.Background(), key)
+
+	assert.Nil(t, err, "Should be nil")
+	assert.True(t, exists)
 	assert.Equal(t, 1, redis.OpenCounter)
 	assert.Equal(t, 1, redis.CloseCounter)
-	assert.Equal(t, 1, loggerM.InfoCallCounter)
+	assert.Equal(t, 2, loggerM.InfoCallCounter)
+	assert.Equal(t, 0, loggerM.ErrorCallCounter)
+}
+
+func TestRedisExistsKeyNotLockedSuccess(t *testing.T) {
+	redisPersistenceSetup()
+	defer teardown()
+
+	exists, err := redisPersistence.Exists(context.Background(), key)
+
+	assert.Nil(t, err, "Should be nil")
+	assert.False(t, exists)
+}
+
+func TestRedisExistsKeyExpiredSuccess(t *testing.T) {
+	redisPersistenceSetup()
+	defer teardown()
+
+	_ = redisPersistence.Lock(context.Background(), key)
+	redis.FastForward(properties.Properties().Cache.KeyTTL)
+
+	exists, err := redisPersistence.Exists(context.Background(), key)
+
+	assert.Nil(t, err, "Should be nil")
+	assert.False(t, exists)
+}
+
+func TestRedisExistsOpenFailure(t *testing.T) {
+	redisPersistenceSetup()
+	defer teardown()
+
+	redis.OpenError = errors.New("open conn error")
+
+	exists, err := redisPersistence.Exists(context.Background(), key)
+
+	assert.False(t, exists)
+	assert.NotNil(t, err, "Should not be nil")
+	assert.Equal(t, "open conn error", err.Error())
+	assert.Equal(t, "Error while trying to open redis connection", err.InternalError())
+	assert.Equal(t, "Error while using Redis cache", err.Description())
+	assert.Equal(t, 0, redis.CloseCounter)
 	assert.Equal(t, 1, loggerM.ErrorCallCounter)
-	assert.Equal(t, 1, loggerM.WarningCallCounter)
+}
+
+func TestRedisWatchdogExtendsKeyUntilUnlockSuccess(t *testing.T) {
+	keyTTL := properties.Properties().Cache.KeyTTL
+	properties.Properties().Cache.KeyTTL = 300 * time.Millisecond
+	defer func() { properties.Properties().Cache.KeyTTL = keyTTL }()
+
+	redisPersistenceSetup()
+	defer teardown()
+
+	_ = redisPersistence.Lock(context.Background(), key)
+	redisPersistence.Watchdog(context.Background(), key)
+	redis.FastForward(200 * time.Millisecond)
+
+	time.Sleep(250 * time.Millisecond)
+
+	assert.Equal(t, 300*time.Millisecond, redis.TTL(properties.Properties().Cache.KeyPrefix+key))
+
+	err := redisPersistence.Unlock(context.Background(), key)
+
+	keyLocked, _ := redis.Get(properties.Properties().Cache.KeyPrefix + key)
+
+	assert.Nil(t, err, "Should be nil")
+	assert.Equal(t, "", keyLocked)
+	assert.Equal(t, 0, loggerM.ErrorCallCounter)
+}
+
+func TestRedisWatchdogStopsWhenContextDoneSuccess(t *testing.T) {
+	keyTTL := properties.Properties().Cache.KeyTTL
+	properties.Properties().Cache.KeyTTL = 300 * time.Millisecond
+	defer func() { properties.Properties().Cache.KeyTTL = keyTTL }()
+
+	redisPersistenceSetup()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_ = redisPersistence.Lock(context.Background(), key)
+	redisPersistence.Watchdog(ctx, key)
+	cancel()
+	redis.FastForward(200 * time.Millisecond)
+
+	time.Sleep(250 * time.Millisecond)
+
+	assert.Equal(t, 100*time.Millisecond, redis.TTL(properties.Properties().Cache.KeyPrefix+key))
+
+	err := redisPersistence.Unlock(context.Background(), key)
+
+	assert.Nil(t, err, "Should be nil")
+	assert.Equal(t, 0, loggerM.ErrorCallCounter)
 }
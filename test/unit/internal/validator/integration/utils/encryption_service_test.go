@@ -108,3 +108,15 @@ func TestSHA384EncryptSuccess(t *testing.T) {
 	assert.NotNil(t, newEncryptedString)
 	assert.Equal(t, sha384EncryptedString, newEncryptedString)
 }
+
+func TestSHA256EncryptSuccess(t *testing.T) {
+	setupEncryptionService()
+
+	// example from Binance API signed endpoints documentation
+	query := "symbol=LTCBTC&side=BUY&type=LIMIT&timeInForce=GTC&quantity=1&price=0.1&recvWindow=5000&timestamp=1499827319559"
+	secret := "NhqPtmdSJYdKjVHjA7PZj4Mge3R5YNiP1e3UZjInClVN65XAbvqqM6A7H5fATj0j"
+
+	signature := encryptionService.SHA256Encrypt(query, secret)
+
+	assert.Equal(t, "c8db56825ae71d6d79447849e617115f4a920fa2acdcab2b053c4b2838bd6b71", signature)
+}
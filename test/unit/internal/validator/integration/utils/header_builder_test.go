@@ -1,17 +1,19 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/utils"
-	"github.com/brienze1/crypto-robot-validator/pkg/time_utils"
 	"github.com/brienze1/crypto-robot-validator/test/mocks"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"strconv"
 	"testing"
+	"time"
 )
 
 var (
@@ -27,7 +29,6 @@ var (
 	credentialsPersisted *dto.Credentials
 	encryptionSecrets    *dto.EncryptionSecrets
 	expectedTokenHB      = uuid.NewString()
-	nonceHB              = time_utils.Epoch()
 	clientIdHB           = uuid.NewString()
 	endpointHB           = "v1/balance"
 	payloadHB            = `{}`
@@ -64,12 +65,15 @@ func setupHB() {
 func TestBuildBiscointHeaderSuccess(t *testing.T) {
 	setupHB()
 
-	header, err := headerBuilder.BiscointHeader(clientIdHB, endpointHB, payloadHB)
+	start := time.Now().UnixMilli()
+	header, err := headerBuilder.BiscointHeader(context.Background(), clientIdHB, endpointHB, payloadHB)
+	nonce, _ := strconv.ParseInt(header.Get("BSCNT-NONCE"), 10, 64)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, header)
 	assert.Equal(t, "application/json", header.Get("Content-Type"))
-	assert.Equal(t, nonceHB, header.Get("BSCNT-NONCE"))
+	assert.GreaterOrEqual(t, nonce, start)
+	assert.LessOrEqual(t, nonce, time.Now().UnixMilli())
 	assert.Equal(t, credentialsPersisted.ApiKey, header.Get("BSCNT-APIKEY"))
 	assert.Equal(t, expectedTokenHB, header.Get("BSCNT-SIGN"))
 	assert.Equal(t, 2, loggerHB.InfoCallCounter)
@@ -80,12 +84,27 @@ func TestBuildBiscointHeaderSuccess(t *testing.T) {
 	assert.Equal(t, 1, tokenBuilderHB.BuildCounter)
 }
 
+func TestBuildBiscointHeaderNonceIncreasesSuccess(t *testing.T) {
+	setupHB()
+
+	first, err := headerBuilder.BiscointHeader(context.Background(), clientIdHB, endpointHB, payloadHB)
+	assert.Nil(t, err)
+	second, err := headerBuilder.BiscointHeader(context.Background(), clientIdHB, endpointHB, payloadHB)
+	assert.Nil(t, err)
+
+	firstNonce, _ := strconv.ParseInt(first.Get("BSCNT-NONCE"), 10, 64)
+	secondNonce, _ := strconv.ParseInt(second.Get("BSCNT-NONCE"), 10, 64)
+
+	assert.Greater(t, secondNonce, firstNonce)
+	assert.Equal(t, 2, tokenBuilderHB.BuildCounter)
+}
+
 func TestBuildBiscointHeaderCredentialsFailure(t *testing.T) {
 	setupHB()
 
 	credentialsPersistenceHB.GetCredentialsError = errors.New("get credentials error")
 
-	header, err := headerBuilder.BiscointHeader(clientIdHB, endpointHB, payloadHB)
+	header, err := headerBuilder.BiscointHeader(context.Background(), clientIdHB, endpointHB, payloadHB)
 
 	assert.NotNil(t, err)
 	assert.Nil(t, header)
@@ -105,7 +124,7 @@ func TestBuildBiscointHeaderGetSecretFailure(t *testing.T) {
 
 	secretsManagerServiceHB.GetSecretError = errors.New("get secret error")
 
-	header, err := headerBuilder.BiscointHeader(clientIdHB, endpointHB, payloadHB)
+	header, err := headerBuilder.BiscointHeader(context.Background(), clientIdHB, endpointHB, payloadHB)
 
 	assert.NotNil(t, err)
 	assert.Nil(t, header)
@@ -125,7 +144,7 @@ func TestBuildBiscointHeaderAESDecryptFailure(t *testing.T) {
 
 	encryptionServiceHB.AESDecryptError = errors.New("AES decrypt error")
 
-	header, err := headerBuilder.BiscointHeader(clientIdHB, endpointHB, payloadHB)
+	header, err := headerBuilder.BiscointHeader(context.Background(), clientIdHB, endpointHB, payloadHB)
 
 	assert.NotNil(t, err)
 	assert.Nil(t, header)
@@ -145,7 +164,7 @@ func TestBuildBiscointHeaderTokenBuildFailure(t *testing.T) {
 
 	tokenBuilderHB.BuildError = errors.New("token build error")
 
-	header, err := headerBuilder.BiscointHeader(clientIdHB, endpointHB, payloadHB)
+	header, err := headerBuilder.BiscointHeader(context.Background(), clientIdHB, endpointHB, payloadHB)
 
 	assert.NotNil(t, err)
 	assert.Nil(t, header)
@@ -159,3 +178,53 @@ func TestBuildBiscointHeaderTokenBuildFailure(t *testing.T) {
 	assert.Equal(t, 1, encryptionServiceHB.AESDecryptCounter)
 	assert.Equal(t, 1, tokenBuilderHB.BuildCounter)
 }
+
+func TestBuildBinanceHeaderSuccess(t *testing.T) {
+	setupHB()
+
+	query := "recvWindow=5000&timestamp=1499827319559"
+
+	header, signedQuery, err := headerBuilder.BinanceHeader(context.Background(), clientIdHB, query)
+
+	assert.Nil(t, err)
+	assert.Equal(t, credentialsPersisted.ApiKey, header.Get("X-MBX-APIKEY"))
+	assert.Equal(t, query+"&signature=5cd2653da4476e25bd8a3e1e68b1487622d666474978f4ea7ad17af12ac5479c", signedQuery)
+	assert.Equal(t, 2, loggerHB.InfoCallCounter)
+	assert.Equal(t, 0, loggerHB.ErrorCallCounter)
+	assert.Equal(t, 1, credentialsPersistenceHB.GetCredentialsCounter)
+	assert.Equal(t, 1, secretsManagerServiceHB.GetSecretCounter)
+	assert.Equal(t, 1, encryptionServiceHB.AESDecryptCounter)
+	assert.Equal(t, 1, encryptionServiceHB.SHA256EncryptCounter)
+	assert.Equal(t, 0, tokenBuilderHB.BuildCounter)
+}
+
+func TestBuildBinanceHeaderCredentialsFailure(t *testing.T) {
+	setupHB()
+
+	credentialsPersistenceHB.GetCredentialsError = errors.New("get credentials error")
+
+	header, signedQuery, err := headerBuilder.BinanceHeader(context.Background(), clientIdHB, "timestamp=1499827319559")
+
+	assert.NotNil(t, err)
+	assert.Nil(t, header)
+	assert.Equal(t, "", signedQuery)
+	assert.Equal(t, "get credentials error", err.Error())
+	assert.Equal(t, "Error while using DynamoDB Credentials table", err.Description())
+	assert.Equal(t, 1, loggerHB.ErrorCallCounter)
+	assert.Equal(t, 0, encryptionServiceHB.SHA256EncryptCounter)
+}
+
+func TestBuildBinanceHeaderAESDecryptFailure(t *testing.T) {
+	setupHB()
+
+	encryptionServiceHB.AESDecryptError = errors.New("AES decrypt error")
+
+	header, signedQuery, err := headerBuilder.BinanceHeader(context.Background(), clientIdHB, "timestamp=1499827319559")
+
+	assert.NotNil(t, err)
+	assert.Nil(t, header)
+	assert.Equal(t, "", signedQuery)
+	assert.Equal(t, "Error while performing encryption", err.Description())
+	assert.Equal(t, 1, encryptionServiceHB.AESDecryptCounter)
+	assert.Equal(t, 0, encryptionServiceHB.SHA256EncryptCounter)
+}
@@ -0,0 +1,274 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/utils"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var (
+	retryClient  adapters.RetryClientAdapter
+	loggerRC     = mocks.Logger()
+	httpClientRC = mocks.HttpClient()
+)
+
+var (
+	signCounterRC int
+)
+
+func setupRC() {
+	config.LoadTestEnv()
+	properties.Properties().Reload()
+
+	loggerRC.Reset()
+	httpClientRC.Reset()
+	httpClientRC.SetupServer()
+	signCounterRC = 0
+
+	retryClient = utils.RetryClient(loggerRC, httpClientRC)
+}
+
+func teardownRC() {
+	httpClientRC.Close()
+}
+
+func requestRC(ctx context.Context) *http.Request {
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, httpClientRC.GetUrl()+"/"+properties.Properties().BiscointGetCryptoPath, nil)
+	return request
+}
+
+func signRC(request *http.Request) error {
+	signCounterRC++
+	request.Header = http.Header{}
+	request.Header.Set("BSCNT-NONCE", strconv.Itoa(signCounterRC))
+	return nil
+}
+
+func TestRetryClientSuccess(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	response, err := retryClient.Do(requestRC(context.Background()), signRC)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 1, httpClientRC.DoCounter)
+	assert.Equal(t, 1, signCounterRC)
+	assert.Equal(t, 0, loggerRC.WarningCallCounter)
+}
+
+func TestRetryClientRetryableStatusSuccess(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	httpClientRC.StatusCodes = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+
+	response, err := retryClient.Do(requestRC(context.Background()), signRC)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 3, httpClientRC.DoCounter)
+	assert.Equal(t, 3, signCounterRC)
+	assert.Equal(t, "1", httpClientRC.RequestHeaders[0].Get("BSCNT-NONCE"))
+	assert.Equal(t, "2", httpClientRC.RequestHeaders[1].Get("BSCNT-NONCE"))
+	assert.Equal(t, "3", httpClientRC.RequestHeaders[2].Get("BSCNT-NONCE"))
+	assert.Equal(t, 2, loggerRC.WarningCallCounter)
+}
+
+func TestRetryClientWithoutSignSuccess(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	httpClientRC.StatusCodes = []int{http.StatusBadGateway}
+
+	response, err := retryClient.Do(requestRC(context.Background()), nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 2, httpClientRC.DoCounter)
+	assert.Equal(t, 1, loggerRC.WarningCallCounter)
+}
+
+func TestRetryClientAttemptsExhaustedFailure(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	httpClientRC.StatusCode = http.StatusServiceUnavailable
+
+	response, err := retryClient.Do(requestRC(context.Background()), signRC)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Equal(t, properties.Properties().ExchangeRetry.MaxAttempts, httpClientRC.DoCounter)
+	assert.Equal(t, properties.Properties().ExchangeRetry.MaxAttempts, signCounterRC)
+	assert.Equal(t, properties.Properties().ExchangeRetry.MaxAttempts-1, loggerRC.WarningCallCounter)
+}
+
+func TestRetryClientStatusNotRetryableFailure(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	httpClientRC.StatusCode = http.StatusBadRequest
+
+	response, err := retryClient.Do(requestRC(context.Background()), signRC)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, 1, httpClientRC.DoCounter)
+	assert.Equal(t, 0, loggerRC.WarningCallCounter)
+}
+
+func TestRetryClientTransportErrorFailure(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	httpClientRC.DoError = errors.New("do error")
+
+	response, err := retryClient.Do(requestRC(context.Background()), signRC)
+
+	assert.Nil(t, response)
+	assert.Equal(t, "do error", err.Error())
+	assert.Equal(t, properties.Properties().ExchangeRetry.MaxAttempts, httpClientRC.DoCounter)
+	assert.Equal(t, properties.Properties().ExchangeRetry.MaxAttempts-1, loggerRC.WarningCallCounter)
+}
+
+func TestRetryClientSignFailure(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	response, err := retryClient.Do(requestRC(context.Background()), func(*http.Request) error {
+		return errors.New("sign error")
+	})
+
+	assert.Nil(t, response)
+	assert.Equal(t, "sign error", err.Error())
+	assert.Equal(t, 0, httpClientRC.DoCounter)
+}
+
+func TestRetryClientExponentialBackoffSuccess(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	properties.Properties().ExchangeRetry.BaseBackoff = 20 * time.Millisecond
+	properties.Properties().ExchangeRetry.MaxBackoff = 30 * time.Millisecond
+	properties.Properties().ExchangeRetry.Jitter = 0
+	retryClient = utils.RetryClient(loggerRC, httpClientRC)
+	httpClientRC.StatusCodes = []int{http.StatusInternalServerError, http.StatusInternalServerError}
+
+	start := time.Now()
+	response, err := retryClient.Do(requestRC(context.Background()), signRC)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 3, httpClientRC.DoCounter)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestRetryClientRetryAfterSuccess(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	properties.Properties().ExchangeRetry.MaxBackoff = 2 * time.Second
+	retryClient = utils.RetryClient(loggerRC, httpClientRC)
+	httpClientRC.StatusCodes = []int{http.StatusTooManyRequests}
+	httpClientRC.RetryAfter = "1"
+
+	start := time.Now()
+	response, err := retryClient.Do(requestRC(context.Background()), signRC)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 2, httpClientRC.DoCounter)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestRetryClientZeroRetryAfterNewNonceSuccess(t *testing.T) {
+	setupRC()
+	setupHB()
+	defer teardownRC()
+
+	httpClientRC.StatusCodes = []int{http.StatusTooManyRequests, http.StatusTooManyRequests}
+	httpClientRC.RetryAfter = "0"
+
+	sign := func(request *http.Request) error {
+		header, err := headerBuilder.BiscointHeader(request.Context(), clientIdHB, endpointHB, payloadHB)
+		if err != nil {
+			return err
+		}
+		request.Header = header
+		return nil
+	}
+
+	response, err := retryClient.Do(requestRC(context.Background()), sign)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 3, httpClientRC.DoCounter)
+	first, _ := strconv.ParseInt(httpClientRC.RequestHeaders[0].Get("BSCNT-NONCE"), 10, 64)
+	second, _ := strconv.ParseInt(httpClientRC.RequestHeaders[1].Get("BSCNT-NONCE"), 10, 64)
+	third, _ := strconv.ParseInt(httpClientRC.RequestHeaders[2].Get("BSCNT-NONCE"), 10, 64)
+	assert.Greater(t, second, first)
+	assert.Greater(t, third, second)
+}
+
+func TestRetryClientRetryAfterExceedsDeadlineFailure(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	properties.Properties().ExchangeRetry.MaxBackoff = 20 * time.Second
+	retryClient = utils.RetryClient(loggerRC, httpClientRC)
+	httpClientRC.StatusCode = http.StatusTooManyRequests
+	httpClientRC.RetryAfter = "10"
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	response, err := retryClient.Do(requestRC(ctx), signRC)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, 1, httpClientRC.DoCounter)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryClientRetryAfterExceedsMaxBackoffFailure(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	httpClientRC.StatusCode = http.StatusTooManyRequests
+	httpClientRC.RetryAfter = "1"
+
+	start := time.Now()
+	response, err := retryClient.Do(requestRC(context.Background()), signRC)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, 1, httpClientRC.DoCounter)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 0, loggerRC.WarningCallCounter)
+}
+
+func TestRetryClientContextCancelledFailure(t *testing.T) {
+	setupRC()
+	defer teardownRC()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	response, err := retryClient.Do(requestRC(ctx), signRC)
+
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, httpClientRC.DoCounter)
+	assert.Equal(t, 0, loggerRC.WarningCallCounter)
+}
@@ -0,0 +1,306 @@
+package webservice
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/utils"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/webservice"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	binanceWebService        adapters.CryptoServiceAdapter
+	loggerBN                 = mocks.Logger()
+	clientBN                 = mocks.HttpClient()
+	credentialsPersistenceBN = mocks.DynamoDBCredentialPersistence()
+	secretsManagerServiceBN  = mocks.SecretsManagerService()
+	encryptionServiceBN      = mocks.EncryptionService()
+	tokenBuilderBN           = mocks.TokenBuilder()
+	binance                  *binanceStandIn
+)
+
+var (
+	clientIdBN  = uuid.NewString()
+	apiKeyBN    = uuid.NewString()
+	apiSecretBN = "String to be encrypted"
+)
+
+// binanceStandIn serves the Binance book ticker and account endpoints. Signed requests are checked like Binance does:
+// api key header, HMAC-SHA256 signature of the query string (sent as the last parameter) and recvWindow.
+// statusCodes are answered first, statusCode after that.
+type binanceStandIn struct {
+	server          *httptest.Server
+	statusCode      int
+	statusCodes     []int
+	tickerResponse  string
+	accountResponse string
+	symbols         []string
+	queries         []string
+}
+
+func (b *binanceStandIn) handle(w http.ResponseWriter, r *http.Request) {
+	b.queries = append(b.queries, r.URL.RawQuery)
+
+	statusCode := b.statusCode
+	if len(b.statusCodes) > 0 {
+		statusCode, b.statusCodes = b.statusCodes[0], b.statusCodes[1:]
+	}
+	if statusCode != http.StatusOK {
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(`{"code":-1003,"msg":"Too many requests."}`))
+		return
+	}
+
+	switch r.URL.Path {
+	case "/" + properties.Properties().BinanceGetCryptoPath:
+		b.symbols = append(b.symbols, r.URL.Query().Get("symbol"))
+		_, _ = w.Write([]byte(b.tickerResponse))
+	case "/" + properties.Properties().BinanceGetBalancePath:
+		if err := b.verify(r); err != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(err))
+			return
+		}
+		_, _ = w.Write([]byte(b.accountResponse))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (b *binanceStandIn) verify(r *http.Request) string {
+	if r.Header.Get("X-MBX-APIKEY") != apiKeyBN {
+		return `{"code":-2014,"msg":"API-key format invalid."}`
+	}
+
+	payload, signature, found := strings.Cut(r.URL.RawQuery, "&signature=")
+	digester := hmac.New(sha256.New, []byte(apiSecretBN))
+	digester.Write([]byte(payload))
+	if !found || signature != hex.EncodeToString(digester.Sum(nil)) {
+		return `{"code":-1022,"msg":"Signature for this request is not valid."}`
+	}
+
+	timestamp, _ := strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+	recvWindow, _ := strconv.ParseInt(r.URL.Query().Get("recvWindow"), 10, 64)
+	if time.Now().UnixMilli()-timestamp > recvWindow {
+		return `{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`
+	}
+
+	return ""
+}
+
+func setupBN() {
+	config.LoadTestEnv()
+	properties.Properties().Reload()
+
+	loggerBN.Reset()
+	clientBN.Reset()
+	credentialsPersistenceBN.Reset()
+	secretsManagerServiceBN.Reset()
+	encryptionServiceBN.Reset()
+	tokenBuilderBN.Reset()
+
+	binance = &binanceStandIn{
+		statusCode:      http.StatusOK,
+		tickerResponse:  `{"symbol":"BTCBRL","bidPrice":"97878.96000000","bidQty":"0.01000000","askPrice":"98790.02000000","askQty":"0.02000000"}`,
+		accountResponse: `{"makerCommission":10,"balances":[{"asset":"BRL","free":"9949.75000000","locked":"50.00000000"},{"asset":"BTC","free":"0.00138164","locked":"0.00000000"},{"asset":"BNB","free":"1.00000000","locked":"0.00000000"}]}`,
+	}
+	binance.server = httptest.NewServer(http.HandlerFunc(binance.handle))
+	properties.Properties().BinanceUrl = binance.server.URL + "/"
+
+	credentialsPersistenceBN.AddCredential(&dto.Credentials{
+		ClientId:  clientIdBN,
+		ApiKey:    apiKeyBN,
+		ApiSecret: "7571a562734fe71e042c9839ea0beb80f033779aa7379b5b93f0fcc10b0a74db99712329ba92",
+	})
+	secretsManagerServiceBN.SetSecret(properties.Properties().Aws.SecretsManager.EncryptionSecretName, &dto.EncryptionSecrets{
+		EncryptionKey: "9y$B?E(H+MbQeThWmZq4t7w!z%C*F)J@",
+	})
+
+	headerBuilderBN := utils.HeaderBuilder(loggerBN, credentialsPersistenceBN, secretsManagerServiceBN, encryptionServiceBN, tokenBuilderBN)
+	binanceWebService = webservice.BinanceWebService(loggerBN, utils.RetryClient(loggerBN, clientBN), headerBuilderBN)
+}
+
+func teardownBN() {
+	binance.server.Close()
+}
+
+func TestBinanceGetCryptoSuccess(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	coin, err := binanceWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, err)
+	assert.Equal(t, symbol.Bitcoin, coin.Symbol)
+	assert.Equal(t, symbol.Brl, coin.Quote)
+	assert.Equal(t, 98790.02, coin.BuyValue)
+	assert.Equal(t, 97878.96, coin.SellValue)
+	assert.WithinDuration(t, time.Now(), coin.Timestamp, 2*time.Second)
+	assert.Equal(t, []string{"BTCBRL"}, binance.symbols)
+	assert.Equal(t, "application/json", clientBN.RequestHeaders[0].Get("Accept"))
+	assert.Equal(t, "", clientBN.RequestHeaders[0].Get("X-MBX-APIKEY"))
+	assert.Equal(t, 1, clientBN.DoCounter)
+	assert.Equal(t, 0, loggerBN.ErrorCallCounter)
+}
+
+func TestBinanceGetCryptoStatusNotOKFailure(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	binance.statusCode = http.StatusTeapot
+
+	coin, err := binanceWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, `418 I'm a teapot: {"code":-1003,"msg":"Too many requests."}`, err.Error())
+	assert.Equal(t, "Binance API status code not Ok: 418 I'm a teapot", err.InternalError())
+	assert.Equal(t, "Error while performing Binance API request", err.Description())
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Equal(t, true, err.LockedClientId())
+	assert.Equal(t, true, err.LockedClient())
+	assert.Equal(t, 1, loggerBN.ErrorCallCounter)
+}
+
+func TestBinanceGetCryptoDoRequestFailure(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	clientBN.DoError = errors.New("do error")
+
+	coin, err := binanceWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, "do error", err.Error())
+	assert.Equal(t, "Error while trying to get crypto value from Binance", err.InternalError())
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Equal(t, properties.Properties().ExchangeRetry.MaxAttempts, clientBN.DoCounter)
+}
+
+func TestBinanceGetCryptoDecodeFailure(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	binance.tickerResponse = `{"symbol":"BTCBRL","bidPrice":97878.96,"askPrice":98790.02}`
+
+	coin, err := binanceWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, "Error while trying to decode Binance book ticker API response", err.InternalError())
+	assert.Equal(t, "", err.Code())
+}
+
+func TestBinanceGetCryptoInvalidPriceFailure(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	binance.tickerResponse = `{"symbol":"BTCBRL","bidPrice":"97878.96","askPrice":"invalid"}`
+
+	coin, err := binanceWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, "Could not convert Binance book ticker response to model", err.InternalError())
+}
+
+func TestBinanceGetBalanceSuccess(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	balance, err := binanceWebService.GetBalance(context.Background(), clientIdBN)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 9949.75, balance.Amount(symbol.Brl))
+	assert.Equal(t, 0.00138164, balance.Amount(symbol.Bitcoin))
+	assert.Equal(t, 2, len(balance.Assets))
+	assert.Equal(t, apiKeyBN, clientBN.RequestHeaders[0].Get("X-MBX-APIKEY"))
+	assert.True(t, strings.HasPrefix(binance.queries[0], "recvWindow=5000&timestamp="))
+	assert.Equal(t, 1, clientBN.DoCounter)
+	assert.Equal(t, 1, encryptionServiceBN.SHA256EncryptCounter)
+	assert.Equal(t, 0, loggerBN.ErrorCallCounter)
+}
+
+func TestBinanceGetBalanceRetrySignsAgainSuccess(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	binance.statusCodes = []int{http.StatusServiceUnavailable}
+
+	balance, err := binanceWebService.GetBalance(context.Background(), clientIdBN)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 9949.75, balance.Amount(symbol.Brl))
+	assert.Equal(t, 2, clientBN.DoCounter)
+	assert.Equal(t, 2, encryptionServiceBN.SHA256EncryptCounter)
+}
+
+func TestBinanceGetBalanceInvalidSignatureFailure(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	apiSecret := apiSecretBN
+	apiSecretBN = "another secret"
+	defer func() { apiSecretBN = apiSecret }()
+
+	balance, err := binanceWebService.GetBalance(context.Background(), clientIdBN)
+
+	assert.Nil(t, balance)
+	assert.Equal(t, `401 Unauthorized: {"code":-1022,"msg":"Signature for this request is not valid."}`, err.Error())
+	assert.Equal(t, "Binance API status code not Ok: 401 Unauthorized", err.InternalError())
+	assert.Equal(t, "", err.Code())
+	assert.Equal(t, 1, clientBN.DoCounter)
+}
+
+func TestBinanceGetBalanceCredentialsFailure(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	credentialsPersistenceBN.GetCredentialsError = errors.New("get credentials error")
+
+	balance, err := binanceWebService.GetBalance(context.Background(), clientIdBN)
+
+	assert.Nil(t, balance)
+	assert.Equal(t, "get credentials error", err.Error())
+	assert.Equal(t, "GetCredentials error", err.InternalError())
+	assert.Equal(t, 0, clientBN.DoCounter)
+}
+
+func TestBinanceGetBalanceStatusNotOKFailure(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	binance.statusCode = http.StatusTooManyRequests
+
+	balance, err := binanceWebService.GetBalance(context.Background(), clientIdBN)
+
+	assert.Nil(t, balance)
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Equal(t, properties.Properties().ExchangeRetry.MaxAttempts, clientBN.DoCounter)
+}
+
+func TestBinanceGetBalanceToModelFailure(t *testing.T) {
+	setupBN()
+	defer teardownBN()
+
+	binance.accountResponse = `{"balances":[{"asset":"BRL","free":"invalid","locked":"0.00000000"}]}`
+
+	balance, err := binanceWebService.GetBalance(context.Background(), clientIdBN)
+
+	assert.Nil(t, balance)
+	assert.Equal(t, "Could not convert Binance account response to model", err.InternalError())
+}
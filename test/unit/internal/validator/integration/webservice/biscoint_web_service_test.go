@@ -1,16 +1,22 @@
 package webservice
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/utils"
 	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/webservice"
 	"github.com/brienze1/crypto-robot-validator/test/mocks"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"testing"
+	"time"
 )
 
 var (
@@ -28,10 +34,9 @@ func setup() {
 	client.Reset()
 	client.SetupServer()
 	properties.Properties().BiscointUrl = client.GetUrl() + "/"
-	properties.Properties().SimulationUrl = client.GetUrl() + "/"
 	headerBuilder.Reset()
 
-	biscointWebService = webservice.BiscointWebService(logger, client, headerBuilder)
+	biscointWebService = webservice.BiscointWebService(logger, utils.RetryClient(logger, client), headerBuilder)
 }
 
 func teardown() {
@@ -58,11 +63,11 @@ func TestGetCryptoSuccess(t *testing.T) {
 				"bid": 97878.96,
 				"bidQuoteAmountRef": 1000,
 				"bidBaseAmountRef": 0.0102167,
-				"timestamp": "2022-09-22T18:07:27.415Z"
+				"timestamp": "` + time.Now().UTC().Format(time.RFC3339Nano) + `"
 			}
 		}`
 
-	coin, err := biscointWebService.GetCrypto(symbol.Bitcoin, symbol.Brl)
+	coin, err := biscointWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, coin)
@@ -71,6 +76,7 @@ func TestGetCryptoSuccess(t *testing.T) {
 	assert.Equal(t, 98790.02, coin.BuyValue)
 	assert.Equal(t, 97878.96, coin.SellValue)
 	assert.Equal(t, 1, client.DoCounter)
+	assert.Equal(t, "application/json", client.RequestHeaders[0].Get("Accept"))
 	assert.Equal(t, 2, logger.InfoCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
 }
@@ -81,9 +87,9 @@ func TestGetCryptoNewRequestFailure(t *testing.T) {
 
 	properties.Properties().BiscointGetCryptoPath = ""
 	properties.Properties().BiscointUrl = string([]byte{0x7f})
-	biscointWebService = webservice.BiscointWebService(logger, client, headerBuilder)
+	biscointWebService = webservice.BiscointWebService(logger, utils.RetryClient(logger, client), headerBuilder)
 
-	coin, err := biscointWebService.GetCrypto(symbol.Bitcoin, symbol.Brl)
+	coin, err := biscointWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
 
 	assert.Equal(t, "parse \"\\x7f\": net/url: invalid control character in URL", err.Error())
 	assert.Equal(t, "Error while trying to generate Biscoint get request", err.InternalError())
@@ -100,15 +106,15 @@ func TestGetCryptoDoRequestFailure(t *testing.T) {
 
 	properties.Properties().BiscointUrl = ""
 	properties.Properties().BiscointGetCryptoPath = ""
-	biscointWebService = webservice.BiscointWebService(logger, client, headerBuilder)
+	biscointWebService = webservice.BiscointWebService(logger, utils.RetryClient(logger, client), headerBuilder)
 
-	coin, err := biscointWebService.GetCrypto(symbol.Bitcoin, symbol.Brl)
+	coin, err := biscointWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
 
 	assert.Equal(t, "Get \"?quote=BRL&symbol=BTC\": unsupported protocol scheme \"\"", err.Error())
 	assert.Equal(t, "Error while trying to get crypto value from Biscoint", err.InternalError())
 	assert.Equal(t, "Error while performing Biscoint API request", err.Description())
 	assert.Nil(t, coin)
-	assert.Equal(t, 1, client.DoCounter)
+	assert.Equal(t, properties.Properties().ExchangeRetry.MaxAttempts, client.DoCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
@@ -119,9 +125,9 @@ func TestGetCryptoStatusNotOKFailure(t *testing.T) {
 
 	client.StatusCode = 400
 
-	coin, err := biscointWebService.GetCrypto(symbol.Bitcoin, symbol.Brl)
+	coin, err := biscointWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
 
-	assert.Equal(t, "Biscoint API status code not Ok: 400 Bad Request", err.Error())
+	assert.Equal(t, "400 Bad Request", err.Error())
 	assert.Equal(t, "Biscoint API status code not Ok: 400 Bad Request", err.InternalError())
 	assert.Equal(t, "Error while performing Biscoint API request", err.Description())
 	assert.Nil(t, coin)
@@ -130,6 +136,21 @@ func TestGetCryptoStatusNotOKFailure(t *testing.T) {
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
 
+func TestGetCryptoStatusNotOKBodyFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.StatusCode = 503
+	client.GetCryptoResponse = `{"message": "service unavailable"}`
+
+	coin, err := biscointWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Equal(t, `503 Service Unavailable: {"message": "service unavailable"}`, err.Error())
+	assert.Equal(t, "Biscoint API status code not Ok: 503 Service Unavailable", err.InternalError())
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Nil(t, coin)
+}
+
 func TestGetCryptoDecodeFailure(t *testing.T) {
 	setup()
 	defer teardown()
@@ -154,9 +175,9 @@ func TestGetCryptoDecodeFailure(t *testing.T) {
 			}
 		}`
 
-	coin, err := biscointWebService.GetCrypto(symbol.Bitcoin, symbol.Brl)
+	coin, err := biscointWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
 
-	assert.Equal(t, "json: cannot unmarshal string into Go struct field Coin.data.ask of type float64", err.Error())
+	assert.Equal(t, "json: cannot unmarshal string into Go struct field CoinResponse.data.ask of type float64", err.Error())
 	assert.Equal(t, "Error while trying to decode Biscoint coinResponse API response", err.InternalError())
 	assert.Equal(t, "Error while performing Biscoint API request", err.Description())
 	assert.Nil(t, coin)
@@ -178,22 +199,23 @@ func TestGetBalanceSuccess(t *testing.T) {
 			}
 		}`
 
-	balance, err := biscointWebService.GetBalance(uuid.NewString(), false)
+	balance, err := biscointWebService.GetBalance(context.Background(), uuid.NewString())
 
 	assert.Nil(t, err)
 	assert.NotNil(t, balance)
-	assert.Equal(t, 9949.75, balance.BrlBalance)
-	assert.Equal(t, 0.00138164, balance.CryptoBalance)
+	assert.Equal(t, 9949.75, balance.Amount(symbol.Brl))
+	assert.Equal(t, 0.00138164, balance.Amount(symbol.Bitcoin))
 	assert.Equal(t, 1, client.DoCounter)
 	assert.Equal(t, 1, headerBuilder.BiscointHeaderCounter)
 	assert.Equal(t, 2, logger.InfoCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
 }
 
-func TestGetBalanceSimulationSuccess(t *testing.T) {
+func TestGetBalanceRetrySuccess(t *testing.T) {
 	setup()
 	defer teardown()
 
+	client.StatusCodes = []int{http.StatusServiceUnavailable}
 	client.GetBalanceResponse = `
 		{
 			"message": "",
@@ -203,15 +225,14 @@ func TestGetBalanceSimulationSuccess(t *testing.T) {
 			}
 		}`
 
-	balance, err := biscointWebService.GetBalance(uuid.NewString(), true)
+	balance, err := biscointWebService.GetBalance(context.Background(), uuid.NewString())
 
 	assert.Nil(t, err)
 	assert.NotNil(t, balance)
-	assert.Equal(t, 9949.75, balance.BrlBalance)
-	assert.Equal(t, 0.00138164, balance.CryptoBalance)
-	assert.Equal(t, 1, client.DoCounter)
-	assert.Equal(t, 1, headerBuilder.BiscointHeaderCounter)
-	assert.Equal(t, 2, logger.InfoCallCounter)
+	assert.Equal(t, 9949.75, balance.Amount(symbol.Brl))
+	assert.Equal(t, 2, client.DoCounter)
+	assert.Equal(t, 2, headerBuilder.BiscointHeaderCounter)
+	assert.Equal(t, 1, logger.WarningCallCounter)
 	assert.Equal(t, 0, logger.ErrorCallCounter)
 }
 
@@ -221,9 +242,9 @@ func TestGetBalanceCreateRequestFailed(t *testing.T) {
 
 	properties.Properties().BiscointGetBalancePath = ""
 	properties.Properties().BiscointUrl = string([]byte{0x7f})
-	biscointWebService = webservice.BiscointWebService(logger, client, headerBuilder)
+	biscointWebService = webservice.BiscointWebService(logger, utils.RetryClient(logger, client), headerBuilder)
 
-	balance, err := biscointWebService.GetBalance(uuid.NewString(), false)
+	balance, err := biscointWebService.GetBalance(context.Background(), uuid.NewString())
 
 	assert.Equal(t, "parse \"\\x7f\": net/url: invalid control character in URL", err.Error())
 	assert.Equal(t, "Error while trying to generate Biscoint get request", err.InternalError())
@@ -241,7 +262,7 @@ func TestGetBalanceBuildHeaderFailed(t *testing.T) {
 
 	headerBuilder.BiscointHeaderError = errors.New("error building header")
 
-	balance, err := biscointWebService.GetBalance(uuid.NewString(), false)
+	balance, err := biscointWebService.GetBalance(context.Background(), uuid.NewString())
 
 	assert.Equal(t, "error building header", err.Error())
 	assert.Equal(t, "header builder error", err.InternalError())
@@ -259,14 +280,14 @@ func TestGetBalanceDoRequestFailed(t *testing.T) {
 
 	client.DoError = errors.New("do error")
 
-	balance, err := biscointWebService.GetBalance(uuid.NewString(), false)
+	balance, err := biscointWebService.GetBalance(context.Background(), uuid.NewString())
 
 	assert.Equal(t, "do error", err.Error())
 	assert.Equal(t, "Error while trying to get balance from Biscoint", err.InternalError())
 	assert.Equal(t, "Error while performing Biscoint API request", err.Description())
 	assert.Nil(t, balance)
-	assert.Equal(t, 1, client.DoCounter)
-	assert.Equal(t, 1, headerBuilder.BiscointHeaderCounter)
+	assert.Equal(t, properties.Properties().ExchangeRetry.MaxAttempts, client.DoCounter)
+	assert.Equal(t, properties.Properties().ExchangeRetry.MaxAttempts, headerBuilder.BiscointHeaderCounter)
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
@@ -277,9 +298,9 @@ func TestGetBalanceStatusFailed(t *testing.T) {
 
 	client.StatusCode = 400
 
-	balance, err := biscointWebService.GetBalance(uuid.NewString(), false)
+	balance, err := biscointWebService.GetBalance(context.Background(), uuid.NewString())
 
-	assert.Equal(t, "Biscoint API status code not Ok: 400 Bad Request", err.Error())
+	assert.Equal(t, "400 Bad Request", err.Error())
 	assert.Equal(t, "Biscoint API status code not Ok: 400 Bad Request", err.InternalError())
 	assert.Equal(t, "Error while performing Biscoint API request", err.Description())
 	assert.Nil(t, balance)
@@ -302,7 +323,7 @@ func TestGetBalanceBRLDecodeFailed(t *testing.T) {
 			}
 		}`
 
-	balance, err := biscointWebService.GetBalance(uuid.NewString(), false)
+	balance, err := biscointWebService.GetBalance(context.Background(), uuid.NewString())
 
 	assert.Equal(t, "invalid character 'e' looking for beginning of value", err.Error())
 	assert.Equal(t, "Error while trying to decode Biscoint balanceResponse API response", err.InternalError())
@@ -327,7 +348,7 @@ func TestGetBalanceToModelBRLFailed(t *testing.T) {
 			}
 		}`
 
-	balance, err := biscointWebService.GetBalance(uuid.NewString(), false)
+	balance, err := biscointWebService.GetBalance(context.Background(), uuid.NewString())
 
 	assert.Equal(t, "strconv.ParseFloat: parsing \"error\": invalid syntax", err.Error())
 	assert.Equal(t, "Could not convert Biscoint Get Balance response to model", err.InternalError())
@@ -352,7 +373,7 @@ func TestGetBalanceToModelBTCFailed(t *testing.T) {
 			}
 		}`
 
-	balance, err := biscointWebService.GetBalance(uuid.NewString(), false)
+	balance, err := biscointWebService.GetBalance(context.Background(), uuid.NewString())
 
 	assert.Equal(t, "strconv.ParseFloat: parsing \"error\": invalid syntax", err.Error())
 	assert.Equal(t, "Could not convert Biscoint Get Balance response to model", err.InternalError())
@@ -363,3 +384,10 @@ func TestGetBalanceToModelBTCFailed(t *testing.T) {
 	assert.Equal(t, 1, logger.InfoCallCounter)
 	assert.Equal(t, 1, logger.ErrorCallCounter)
 }
+
+func coinResponse(ask, bid float64, timestamp time.Time) string {
+	return fmt.Sprintf(
+		`{"message": "", "data": {"base": "BTC", "quote": "BRL", "ask": %v, "bid": %v, "timestamp": "%s"}}`,
+		ask, bid, timestamp.UTC().Format(time.RFC3339Nano),
+	)
+}
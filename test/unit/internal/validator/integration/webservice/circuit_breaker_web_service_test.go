@@ -0,0 +1,156 @@
+package webservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/webservice"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var (
+	circuitBreakerWebService adapters.CryptoServiceAdapter
+	circuitBreaker           = mocks.CircuitBreaker()
+)
+
+func circuitBreakerSetup() {
+	setup()
+	circuitBreaker.Reset()
+
+	circuitBreakerWebService = webservice.CircuitBreakerWebService(circuitBreaker, biscointWebService)
+
+	client.GetCryptoResponse = coinResponse(98790.02, 97878.96, time.Now())
+	client.GetBalanceResponse = `{"message": "", "data": {"BRL": "9949.75", "BTC": "0.00138164"}}`
+}
+
+func TestCircuitBreakerGetCryptoSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	coin, err := circuitBreakerWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 98790.02, coin.BuyValue)
+	assert.Equal(t, 1, circuitBreaker.AllowCounter)
+	assert.Equal(t, 1, circuitBreaker.SuccessCounter)
+	assert.Equal(t, 0, circuitBreaker.FailureCounter)
+}
+
+func TestCircuitBreakerGetBalanceSuccess(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	balance, err := circuitBreakerWebService.GetBalance(context.Background(), uuid.NewString())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 9949.75, balance.Amount(symbol.Brl))
+	assert.Equal(t, 1, circuitBreaker.AllowCounter)
+	assert.Equal(t, 1, circuitBreaker.SuccessCounter)
+	assert.Equal(t, 0, circuitBreaker.FailureCounter)
+}
+
+func TestCircuitBreakerOpenFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	circuitBreaker.Open = true
+
+	coin, err := circuitBreakerWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Equal(t, 0, client.DoCounter)
+	assert.Equal(t, 0, circuitBreaker.SuccessCounter)
+	assert.Equal(t, 0, circuitBreaker.FailureCounter)
+}
+
+func TestCircuitBreakerServiceUnavailableFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	client.StatusCode = http.StatusServiceUnavailable
+
+	balance, err := circuitBreakerWebService.GetBalance(context.Background(), uuid.NewString())
+
+	assert.Nil(t, balance)
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Equal(t, 0, circuitBreaker.SuccessCounter)
+	assert.Equal(t, 1, circuitBreaker.FailureCounter)
+}
+
+func TestCircuitBreakerTransportErrorFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	client.DoError = errors.New("do error")
+
+	coin, err := circuitBreakerWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Equal(t, 1, circuitBreaker.FailureCounter)
+}
+
+func TestCircuitBreakerStatusNotRecordedFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	client.StatusCode = http.StatusBadRequest
+
+	coin, err := circuitBreakerWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, "", err.Code())
+	assert.Equal(t, 0, circuitBreaker.SuccessCounter)
+	assert.Equal(t, 0, circuitBreaker.FailureCounter)
+	assert.Equal(t, 1, circuitBreaker.ReleaseCounter)
+}
+
+func TestCircuitBreakerContextCancelledNotRecordedFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	coin, err := circuitBreakerWebService.GetCrypto(ctx, symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, "", err.Code())
+	assert.Equal(t, 0, circuitBreaker.FailureCounter)
+}
+
+func TestCircuitBreakerContextDeadlineNotRecordedFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	coin, err := circuitBreakerWebService.GetCrypto(ctx, symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, "", err.Code())
+	assert.Equal(t, 0, circuitBreaker.FailureCounter)
+}
+
+func TestCircuitBreakerClientTimeoutRecordedFailure(t *testing.T) {
+	circuitBreakerSetup()
+	defer teardown()
+
+	client.DoError = fmt.Errorf("client timeout: %w", context.DeadlineExceeded)
+
+	coin, err := circuitBreakerWebService.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, error_code.ServiceUnavailable.Name(), err.Code())
+	assert.Equal(t, 1, circuitBreaker.FailureCounter)
+}
@@ -0,0 +1,62 @@
+package webservice
+
+import (
+	"context"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/exchange"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/webservice"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	biscointServiceER = mocks.BiscointWebService()
+	biscointBreakerER = mocks.CircuitBreaker()
+	binanceServiceER  = mocks.BiscointWebService()
+	binanceBreakerER  = mocks.CircuitBreaker()
+)
+
+func setupER() {
+	biscointServiceER.Reset()
+	biscointBreakerER.Reset()
+	binanceServiceER.Reset()
+	binanceBreakerER.Reset()
+}
+
+func TestExchangeRegistryGetSuccess(t *testing.T) {
+	setupER()
+
+	registry := webservice.ExchangeRegistry().
+		Register(exchange.Biscoint, biscointServiceER, biscointBreakerER).
+		Register(exchange.Binance, binanceServiceER, binanceBreakerER)
+
+	service, breaker, err := registry.Get(exchange.Binance)
+	_, _ = service.GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, err)
+	assert.Equal(t, binanceBreakerER, breaker)
+	assert.Equal(t, 1, binanceServiceER.GetCryptoCounter)
+	assert.Equal(t, 1, binanceBreakerER.AllowCounter)
+	assert.Equal(t, 1, binanceBreakerER.SuccessCounter)
+	assert.Equal(t, 0, biscointServiceER.GetCryptoCounter)
+	assert.Equal(t, 0, biscointBreakerER.AllowCounter)
+}
+
+func TestExchangeRegistryGetNotRegisteredFailure(t *testing.T) {
+	setupER()
+
+	registry := webservice.ExchangeRegistry().
+		Register(exchange.Biscoint, biscointServiceER, biscointBreakerER)
+
+	service, breaker, err := registry.Get(exchange.Binance)
+
+	assert.Nil(t, service)
+	assert.Nil(t, breaker)
+	assert.Equal(t, error_code.ExchangeNotSupported.Name(), err.Code())
+	assert.Equal(t, "Exchange BINANCE is not registered", err.InternalError())
+	assert.Equal(t, "Exchange is not supported", err.Description())
+	assert.Equal(t, true, err.LockedClientId())
+	assert.Equal(t, true, err.LockedClient())
+}
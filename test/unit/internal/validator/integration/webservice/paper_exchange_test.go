@@ -0,0 +1,262 @@
+package webservice
+
+import (
+	"context"
+	"errors"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/config"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/application/properties"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/adapters"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/error_code"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/operation_type"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/enum/symbol"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/domain/model"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/dto"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/persistence"
+	"github.com/brienze1/crypto-robot-validator/internal/validator/integration/webservice"
+	"github.com/brienze1/crypto-robot-validator/test/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var (
+	loggerPE         = mocks.Logger()
+	dynamoDBClientPE = mocks.DynamoDBClient()
+	tickerPE         = mocks.BiscointWebService()
+)
+
+var (
+	paperExchange adapters.PaperExchangeAdapter
+	clientIdPE    string
+	operationPE   *model.Operation
+)
+
+func setupPE() {
+	config.LoadTestEnv()
+
+	loggerPE.Reset()
+	dynamoDBClientPE.Reset()
+	tickerPE.Reset()
+
+	properties.Properties().PaperExchange.StartingBalances = map[string]float64{"BRL": 1000, "BTC": 0.5}
+	paperExchange = webservice.PaperExchange(loggerPE, persistence.DynamoDBLedgerPersistence(loggerPE, dynamoDBClientPE), nil)
+
+	clientIdPE = uuid.NewString()
+	operationPE = model.NewOperation(uuid.NewString(), 50)
+	operationPE.Type = operation_type.Buy
+	operationPE.Base = symbol.Brl
+	operationPE.Quote = symbol.Bitcoin
+	operationPE.Amount = 100
+}
+
+func ledgerPE() *dto.Ledger {
+	ledger, _ := persistence.DynamoDBLedgerPersistence(loggerPE, dynamoDBClientPE).Get(context.Background(), clientIdPE)
+	return ledger
+}
+
+func TestPaperExchangeGetBalanceStartingBalancesSuccess(t *testing.T) {
+	setupPE()
+
+	balance, err := paperExchange.Exchange(tickerPE).GetBalance(context.Background(), clientIdPE)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1000.0, balance.Amount(symbol.Brl))
+	assert.Equal(t, 0.5, balance.Amount(symbol.Bitcoin))
+	assert.Equal(t, 1, ledgerPE().Version)
+	assert.Equal(t, 0, tickerPE.GetBalanceCounter)
+}
+
+func TestPaperExchangeGetBalanceKeepsReservedSuccess(t *testing.T) {
+	setupPE()
+
+	_ = paperExchange.Reserve(context.Background(), clientIdPE, operationPE)
+
+	balance, err := paperExchange.Exchange(tickerPE).GetBalance(context.Background(), clientIdPE)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1000.0, balance.Amount(symbol.Brl))
+	assert.Equal(t, 900.0, ledgerPE().Balances["BRL"])
+}
+
+func TestPaperExchangeGetBalanceLedgerFailure(t *testing.T) {
+	setupPE()
+
+	dynamoDBClientPE.GetItemError = errors.New("get item error")
+
+	balance, err := paperExchange.Exchange(tickerPE).GetBalance(context.Background(), clientIdPE)
+
+	assert.Nil(t, balance)
+	assert.Equal(t, "get item error", err.Error())
+	assert.Equal(t, "Error while trying to get ledger.", err.InternalError())
+	assert.Equal(t, true, err.LockedClient())
+}
+
+func TestPaperExchangeGetCryptoTickerSuccess(t *testing.T) {
+	setupPE()
+
+	coin, err := paperExchange.Exchange(tickerPE).GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, coin)
+	assert.Equal(t, 1, tickerPE.GetCryptoCounter)
+}
+
+func TestPaperExchangeGetCryptoRecordedFeedSuccess(t *testing.T) {
+	setupPE()
+
+	timestamp := time.Now().UTC().Truncate(time.Second)
+	dynamoDBClientPE.AddItem("BTCBRL", &dto.Price{
+		Pair:      "BTCBRL",
+		Symbol:    symbol.Bitcoin,
+		Quote:     symbol.Brl,
+		BuyValue:  100500,
+		SellValue: 100000,
+		Timestamp: timestamp,
+	}, properties.Properties().Aws.DynamoDB.PriceTableName)
+	paperExchange = webservice.PaperExchange(loggerPE, persistence.DynamoDBLedgerPersistence(loggerPE, dynamoDBClientPE), persistence.DynamoDBPricePersistence(loggerPE, dynamoDBClientPE))
+
+	coin, err := paperExchange.Exchange(tickerPE).GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 100500.0, coin.BuyValue)
+	assert.Equal(t, 100000.0, coin.SellValue)
+	assert.Equal(t, true, timestamp.Equal(coin.Timestamp))
+	assert.Equal(t, 0, tickerPE.GetCryptoCounter)
+}
+
+func TestPaperExchangeGetCryptoNotRecordedFailure(t *testing.T) {
+	setupPE()
+
+	paperExchange = webservice.PaperExchange(loggerPE, persistence.DynamoDBLedgerPersistence(loggerPE, dynamoDBClientPE), persistence.DynamoDBPricePersistence(loggerPE, dynamoDBClientPE))
+
+	coin, err := paperExchange.Exchange(tickerPE).GetCrypto(context.Background(), symbol.Bitcoin, symbol.Brl)
+
+	assert.Nil(t, coin)
+	assert.Equal(t, "Price not recorded for BTCBRL.", err.InternalError())
+	assert.Equal(t, true, err.LockedClient())
+}
+
+func TestPaperExchangeRecordedPriceFeedSuccess(t *testing.T) {
+	setupPE()
+
+	recorded := webservice.PaperExchange(loggerPE, persistence.DynamoDBLedgerPersistence(loggerPE, dynamoDBClientPE), persistence.DynamoDBPricePersistence(loggerPE, dynamoDBClientPE))
+
+	assert.Equal(t, false, paperExchange.RecordedPriceFeed())
+	assert.Equal(t, true, recorded.RecordedPriceFeed())
+}
+
+func TestPaperExchangeReserveBuySuccess(t *testing.T) {
+	setupPE()
+
+	err := paperExchange.Reserve(context.Background(), clientIdPE, operationPE)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 900.0, ledgerPE().Balances["BRL"])
+	assert.Equal(t, 0.5, ledgerPE().Balances["BTC"])
+	assert.Equal(t, &dto.LedgerReservation{Asset: "BRL", Amount: 100}, ledgerPE().Reservations[operationPE.Id])
+	assert.Equal(t, 2, ledgerPE().Version)
+}
+
+func TestPaperExchangeReserveSellSuccess(t *testing.T) {
+	setupPE()
+
+	operationPE.Type = operation_type.Sell
+	operationPE.Base = symbol.Bitcoin
+	operationPE.Quote = symbol.Brl
+	operationPE.Amount = 0.2
+
+	err := paperExchange.Reserve(context.Background(), clientIdPE, operationPE)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1000.0, ledgerPE().Balances["BRL"])
+	assert.Equal(t, 0.3, ledgerPE().Balances["BTC"])
+	assert.Equal(t, &dto.LedgerReservation{Asset: "BTC", Amount: 0.2}, ledgerPE().Reservations[operationPE.Id])
+}
+
+func TestPaperExchangeReserveFeeSuccess(t *testing.T) {
+	setupPE()
+
+	operationPE.Fee = 0.5
+
+	err := paperExchange.Reserve(context.Background(), clientIdPE, operationPE)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 899.5, ledgerPE().Balances["BRL"])
+	assert.Equal(t, &dto.LedgerReservation{Asset: "BRL", Amount: 100.5}, ledgerPE().Reservations[operationPE.Id])
+}
+
+func TestPaperExchangeReserveFeeBalanceFailure(t *testing.T) {
+	setupPE()
+
+	operationPE.Amount = 1000
+	operationPE.Fee = 5
+
+	err := paperExchange.Reserve(context.Background(), clientIdPE, operationPE)
+
+	assert.Equal(t, error_code.LedgerBalance.Name(), err.Code())
+	assert.Equal(t, 1000.0, ledgerPE().Balances["BRL"])
+	assert.Equal(t, 0, len(ledgerPE().Reservations))
+}
+
+func TestPaperExchangeReserveAgainSuccess(t *testing.T) {
+	setupPE()
+
+	_ = paperExchange.Reserve(context.Background(), clientIdPE, operationPE)
+	err := paperExchange.Reserve(context.Background(), clientIdPE, operationPE)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 900.0, ledgerPE().Balances["BRL"])
+	assert.Equal(t, 2, ledgerPE().Version)
+}
+
+func TestPaperExchangeReserveBalanceFailure(t *testing.T) {
+	setupPE()
+
+	operationPE.Amount = 1000.01
+
+	err := paperExchange.Reserve(context.Background(), clientIdPE, operationPE)
+
+	assert.Equal(t, error_code.LedgerBalance.Name(), err.Code())
+	assert.Equal(t, "Client ledger BRL balance is lower than the operation amount", err.InternalError())
+	assert.Equal(t, "Error while using the paper exchange", err.Description())
+	assert.Equal(t, true, err.LockedClientId())
+	assert.Equal(t, true, err.LockedClient())
+	assert.Equal(t, 1000.0, ledgerPE().Balances["BRL"])
+	assert.Equal(t, 0, len(ledgerPE().Reservations))
+}
+
+func TestPaperExchangeReleaseSuccess(t *testing.T) {
+	setupPE()
+
+	_ = paperExchange.Reserve(context.Background(), clientIdPE, operationPE)
+
+	err := paperExchange.Release(context.Background(), clientIdPE, operationPE)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1000.0, ledgerPE().Balances["BRL"])
+	assert.Equal(t, 0, len(ledgerPE().Reservations))
+}
+
+func TestPaperExchangeReleaseNotReservedSuccess(t *testing.T) {
+	setupPE()
+
+	err := paperExchange.Release(context.Background(), clientIdPE, operationPE)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1000.0, ledgerPE().Balances["BRL"])
+	assert.Equal(t, 1, ledgerPE().Version)
+}
+
+func TestPaperExchangeReleaseSaveFailure(t *testing.T) {
+	setupPE()
+
+	_ = paperExchange.Reserve(context.Background(), clientIdPE, operationPE)
+	dynamoDBClientPE.PutItemError = errors.New("put item error")
+
+	err := paperExchange.Release(context.Background(), clientIdPE, operationPE)
+
+	assert.Equal(t, "put item error", err.Error())
+	assert.Equal(t, "PutItem error", err.InternalError())
+	assert.Equal(t, 900.0, ledgerPE().Balances["BRL"])
+}
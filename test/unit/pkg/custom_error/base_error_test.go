@@ -51,3 +51,14 @@ func TestBaseExceptionFromNilErrorSuccess(t *testing.T) {
 	assert.Equal(t, baseErrorTest.Message, baseError.InternalMessage)
 	assert.Equal(t, baseErrorTest.DescriptionMessage, baseError.DescriptionMessage)
 }
+
+func TestBaseExceptionFromBaseErrorWithCodeSuccess(t *testing.T) {
+	setup()
+
+	baseErrorTest.SetCode("error Code")
+
+	baseError := custom_error.NewBaseError(&baseErrorTest)
+
+	assert.Equal(t, baseErrorTest.Message, baseError.Message)
+	assert.Equal(t, "error Code", baseError.Code())
+}
@@ -68,6 +68,27 @@ func TestLoggerSetCorrelationIdErrorSuccess(t *testing.T) {
 	assert.Contains(t, buf.String(), testMessage)
 }
 
+func TestLoggerWithCorrelationIdSuccess(t *testing.T) {
+	setup()
+	defer func() {
+		teardown()
+	}()
+
+	logger.SetCorrelationID(testCorrelationId)
+	recordCorrelationId := uuid.NewString()
+	log2.Logger().WithCorrelationID(recordCorrelationId).Info(testMessage)
+
+	assert.Contains(t, buf.String(), recordCorrelationId)
+	assert.NotContains(t, buf.String(), testCorrelationId)
+	assert.Contains(t, buf.String(), testMessage)
+
+	buf.Reset()
+	logger.Info(testMessage)
+
+	assert.Contains(t, buf.String(), testCorrelationId)
+	assert.NotContains(t, buf.String(), recordCorrelationId)
+}
+
 func TestLoggerInfoMessageOnlySuccess(t *testing.T) {
 	setup()
 	defer func() {
@@ -105,3 +105,47 @@ func TestIsThisMonthDifferentMonthFalse(t *testing.T) {
 
 	assert.Equal(t, false, isThisMonth)
 }
+
+func TestFromRFC3339Success(t *testing.T) {
+	date := "2022-09-17T12:05:07.45066-03:00"
+	expected, _ := time.Parse(time.RFC3339Nano, date)
+
+	value := time_utils.From(date).Value()
+
+	assert.Equal(t, true, expected.Equal(value))
+}
+
+func TestFromTimeStringSuccess(t *testing.T) {
+	expected := time.Date(2022, 9, 17, 12, 5, 7, 0, time.UTC)
+
+	value := time_utils.From(expected.String()).Value()
+
+	assert.Equal(t, true, expected.Equal(value))
+}
+
+func TestFromInvalidDateFallbackToNow(t *testing.T) {
+	value := time_utils.From("invalid date").Value()
+
+	assert.Equal(t, time.Now().Year(), value.Year())
+	assert.Equal(t, time.Now().Month(), value.Month())
+	assert.Equal(t, time.Now().Day(), value.Day())
+}
+
+func TestTimeInLocation(t *testing.T) {
+	location := time_utils.Location("Pacific/Kiritimati")
+	timeNow := time.Now().In(location)
+
+	timeSource := time_utils.TimeIn(location)
+
+	assert.Equal(t, timeNow.Year(), timeSource.Year())
+	assert.Equal(t, int(timeNow.Month()), timeSource.Month())
+	assert.Equal(t, timeNow.Day(), timeSource.Day())
+	assert.Equal(t, true, timeSource.IsToday(timeNow.Year(), int(timeNow.Month()), timeNow.Day()))
+	assert.Equal(t, time.Date(timeNow.Year(), timeNow.Month(), timeNow.Day()+1, 0, 0, 0, 0, location), timeSource.Tomorrow())
+}
+
+func TestLocationInvalidFallbackToUTC(t *testing.T) {
+	location := time_utils.Location("Invalid/Location")
+
+	assert.Equal(t, time.UTC, location)
+}